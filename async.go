@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+// analyzeDifference calls analyze with a sampled difference d,
+// which detects options for, enriches, and reports the difference.
+// For [Codec.AsyncReportQueueSize], only the values of d are copied
+// (since the caller may mutate them once the call returns),
+// while analyze is called later by the background goroutine.
+// Otherwise, analyze is called synchronously.
+func (c *Codec) analyzeDifference(d Difference, analyze func(Difference)) {
+	if c.AsyncReportQueueSize <= 0 {
+		analyze(d)
+		return
+	}
+	d = d.clone()
+	c.enqueueReport(func() { analyze(d) })
+}
+
+// enqueueReport queues the report function to be called
+// by the background goroutine for [Codec.AsyncReportQueueSize],
+// dropping it if the queue is full.
+func (c *Codec) enqueueReport(report func()) {
+	q := c.lockReportQueue()
+	defer c.reportQueueMu.RUnlock()
+	select {
+	case q <- report:
+	default:
+		c.add(&c.NumDifferencesDropped, 1)
+	}
+}
+
+// lockReportQueue read-locks the report queue and returns it,
+// starting the background goroutine that calls the functions
+// in the queue if not already running.
+// The caller must call c.reportQueueMu.RUnlock once done sending.
+func (c *Codec) lockReportQueue() chan func() {
+	c.reportQueueMu.RLock()
+	for c.reportQueue == nil {
+		c.reportQueueMu.RUnlock()
+		c.reportQueueMu.Lock()
+		if c.reportQueue == nil {
+			q, done := make(chan func(), max(c.AsyncReportQueueSize, 1)), make(chan struct{})
+			c.reportQueue, c.reportQueueDone = q, done
+			go func() {
+				defer close(done)
+				for report := range q {
+					report()
+				}
+			}()
+		}
+		c.reportQueueMu.Unlock()
+		c.reportQueueMu.RLock()
+	}
+	return c.reportQueue
+}
+
+// stopReportQueue reports all queued differences and
+// stops the background goroutine if running.
+// The queue is restarted upon the next queued report.
+func (c *Codec) stopReportQueue() {
+	c.reportQueueMu.Lock()
+	q, done := c.reportQueue, c.reportQueueDone
+	c.reportQueue, c.reportQueueDone = nil, nil
+	c.reportQueueMu.Unlock()
+	if q != nil {
+		close(q)
+		<-done
+	}
+}
+
+// FlushReports waits until all differences queued
+// before the call (see [Codec.AsyncReportQueueSize]) have been reported.
+// It returns immediately if reporting is synchronous.
+func (c *Codec) FlushReports() {
+	if c.AsyncReportQueueSize <= 0 {
+		return
+	}
+	q := c.lockReportQueue()
+	defer c.reportQueueMu.RUnlock()
+	done := make(chan struct{})
+	q <- func() { close(done) }
+	<-done
+}
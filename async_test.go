@@ -0,0 +1,95 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAsyncReportQueueSize(t *testing.T) {
+	release := make(chan struct{})
+	var got []Difference
+	codec := &Codec{
+		AsyncReportQueueSize: 2,
+		ReportDifference: func(d Difference) {
+			<-release
+			got = append(got, d)
+		},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	// The first difference blocks the background goroutine,
+	// the next two fill the queue, and the last is dropped.
+	in := []int(nil)
+	codec.Marshal(in)
+	for codec.NumDifferencesDropped.Value() == 0 {
+		codec.Marshal(in)
+	}
+	close(release)
+	codec.FlushReports()
+	if n := codec.NumMarshalDiffs.Value(); int64(len(got))+codec.NumDifferencesDropped.Value() != n {
+		t.Errorf("got %d reports and %d dropped, want %d total", len(got), codec.NumDifferencesDropped.Value(), n)
+	}
+	if len(got) < 2 || len(got) > 3 {
+		t.Errorf("got %d reports, want 2 or 3", len(got))
+	}
+
+	// Flushing without any queued differences returns immediately.
+	codec.FlushReports()
+	(&Codec{}).FlushReports()
+}
+
+func TestAsyncReportAnalysis(t *testing.T) {
+	release := make(chan struct{})
+	var got []Difference
+	codec := &Codec{
+		AutoDetectOptions:    true,
+		AsyncReportQueueSize: 4,
+		ReportDifference:     func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// Block the background goroutine such that options are not yet detected
+	// when the call returns and the caller mutates its values.
+	codec.enqueueReport(func() { <-release })
+	in := struct{ S []int }{}
+	codec.Marshal(&in)
+	in.S = []int{}
+	b := []byte(`{"s":[1]}`)
+	out := new(struct{ S []int })
+	codec.Unmarshal(b, out)
+	copy(b, `{"S":[2]}`)
+	out.S[0] = 3
+	if n := codec.NumAutoDetectResolved.Value(); n != 0 || len(got) != 0 {
+		t.Fatalf("detected %d options and reported %d differences synchronously, want none", n, len(got))
+	}
+
+	close(release)
+	if err := codec.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d reports after Close, want 2", len(got))
+	}
+	if names := slices.Collect(got[0].OptionNames()); !slices.Equal(names, []string{"jsonv2.FormatNilSliceAsNull"}) {
+		t.Errorf("Marshal OptionNames = %v, want [jsonv2.FormatNilSliceAsNull]", names)
+	}
+	if names := slices.Collect(got[1].OptionNames()); !slices.Equal(names, []string{"jsonv2.MatchCaseInsensitiveNames"}) {
+		t.Errorf("Unmarshal OptionNames = %v, want [jsonv2.MatchCaseInsensitiveNames]", names)
+	}
+	if string(got[1].JSONValue) != `{"s":[1]}` {
+		t.Errorf("Unmarshal JSONValue = %s, want the input at the time of the call", got[1].JSONValue)
+	}
+
+	// The queue is restarted after Close.
+	codec.Marshal([]int(nil))
+	codec.FlushReports()
+	if len(got) != 3 {
+		t.Errorf("got %d reports after restarting, want 3", len(got))
+	}
+	codec.Close()
+}
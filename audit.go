@@ -0,0 +1,91 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// TypeMethods describes the JSON methods implemented by a Go type
+// (either on the value receiver or the pointer receiver).
+type TypeMethods struct {
+	// Type is the Go type (never a pointer to a type with the methods).
+	Type reflect.Type
+
+	// MarshalJSON reports whether the type implements [jsonv2.Marshaler].
+	MarshalJSON bool
+	// MarshalJSONTo reports whether the type implements [jsonv2.MarshalerTo].
+	MarshalJSONTo bool
+	// UnmarshalJSON reports whether the type implements [jsonv2.Unmarshaler].
+	UnmarshalJSON bool
+	// UnmarshalJSONFrom reports whether the type implements [jsonv2.UnmarshalerFrom].
+	UnmarshalJSONFrom bool
+}
+
+var (
+	marshalerType       = reflect.TypeFor[jsonv2.Marshaler]()
+	marshalerToType     = reflect.TypeFor[jsonv2.MarshalerTo]()
+	unmarshalerType     = reflect.TypeFor[jsonv2.Unmarshaler]()
+	unmarshalerFromType = reflect.TypeFor[jsonv2.UnmarshalerFrom]()
+)
+
+// AuditTypeMethods returns every type reachable from t that implements
+// [jsonv2.MarshalerTo] or [jsonv2.UnmarshalerFrom], sorted by type name.
+//
+// Those methods are ignored by v1, but take precedence over
+// [jsonv2.Marshaler] and [jsonv2.Unmarshaler] in v2.
+// Thus, such types represent behavior that will change
+// as soon as calls are switched from v1 to v2.
+//
+// Types are reachable through pointers, slices, arrays, maps,
+// and exported or embedded struct fields.
+// Types behind interfaces cannot be statically reached.
+func AuditTypeMethods(t reflect.Type) []TypeMethods {
+	var out []TypeMethods
+	seen := make(map[reflect.Type]bool)
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+
+		if t.Kind() != reflect.Pointer {
+			tm := TypeMethods{Type: t}
+			for _, t := range []reflect.Type{t, reflect.PointerTo(t)} {
+				tm.MarshalJSON = tm.MarshalJSON || t.Implements(marshalerType)
+				tm.MarshalJSONTo = tm.MarshalJSONTo || t.Implements(marshalerToType)
+				tm.UnmarshalJSON = tm.UnmarshalJSON || t.Implements(unmarshalerType)
+				tm.UnmarshalJSONFrom = tm.UnmarshalJSONFrom || t.Implements(unmarshalerFromType)
+			}
+			if tm.MarshalJSONTo || tm.UnmarshalJSONFrom {
+				out = append(out, tm)
+			}
+		}
+
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case reflect.Struct:
+			for i := range t.NumField() {
+				if f := t.Field(i); f.IsExported() || f.Anonymous {
+					walk(f.Type)
+				}
+			}
+		}
+	}
+	walk(t)
+	slices.SortFunc(out, func(x, y TypeMethods) int {
+		return cmp.Compare(typeString(x.Type), typeString(y.Type))
+	})
+	return out
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+
+	jsontext "github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+type auditBoth struct{}
+
+func (auditBoth) MarshalJSON() ([]byte, error)                 { return []byte("null"), nil }
+func (auditBoth) MarshalJSONTo(*jsontext.Encoder) error        { return nil }
+func (*auditBoth) UnmarshalJSONFrom(*jsontext.Decoder) error   { return nil }
+func (*auditV1Only) UnmarshalJSON([]byte) error                { return nil }
+func (auditV2Only) MarshalJSONTo(*jsontext.Encoder) error      { return nil }
+func (*auditV2Only) UnmarshalJSONFrom(*jsontext.Decoder) error { return nil }
+
+type (
+	auditV1Only struct{}
+	auditV2Only struct{}
+	auditRoot   struct {
+		Both    *auditBoth
+		V1      []auditV1Only
+		V2      map[string][2]auditV2Only
+		private auditV2Only
+		Any     any
+	}
+)
+
+func TestAuditTypeMethods(t *testing.T) {
+	got := AuditTypeMethods(reflect.TypeFor[*auditRoot]())
+	want := []TypeMethods{{
+		Type:              reflect.TypeFor[auditBoth](),
+		MarshalJSON:       true,
+		MarshalJSONTo:     true,
+		UnmarshalJSONFrom: true,
+	}, {
+		Type:              reflect.TypeFor[auditV2Only](),
+		MarshalJSONTo:     true,
+		UnmarshalJSONFrom: true,
+	}}
+	if d := cmp.Diff(got, want, cmp.Comparer(func(x, y reflect.Type) bool { return x == y })); d != "" {
+		t.Errorf("AuditTypeMethods mismatch (-got +want):\n%s", d)
+	}
+}
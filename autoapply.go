@@ -0,0 +1,59 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"slices"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// opTypeKey identifies the "Marshal" or "Unmarshal" operation
+// (optionally suffixed by the check, e.g., "Marshal/StandardV1")
+// on a Go type, such as for the options applied to its v2 calls.
+type opTypeKey struct {
+	op string
+	t  reflect.Type
+}
+
+// storeDetectedOptions records options that resolved a difference
+// for the "Marshal" or "Unmarshal" operation on a value of type t,
+// joining them with any previously recorded options for t.
+// It does nothing unless [Codec.AutoApplyDetectedOptions] is enabled.
+func (c *Codec) storeDetectedOptions(op string, t reflect.Type, opts jsonv2.Options) {
+	if !c.AutoApplyDetectedOptions || t == nil || opts == nil {
+		return
+	}
+	k := opTypeKey{op, t}
+	for {
+		prev, ok := c.appliedOptions.Load(k)
+		if !ok {
+			if _, loaded := c.appliedOptions.LoadOrStore(k, opts); !loaded {
+				return
+			}
+			continue
+		}
+		next := jsonv2.JoinOptions(prev.(jsonv2.Options), opts)
+		if c.appliedOptions.CompareAndSwap(k, prev, next) {
+			return
+		}
+	}
+}
+
+// withDetectedOptions returns the options o for v2 calls of the
+// "Marshal" or "Unmarshal" operation on a value of type t,
+// with any options recorded by [Codec.storeDetectedOptions] appended.
+func (c *Codec) withDetectedOptions(op string, t reflect.Type, o []jsonv2.Options) []jsonv2.Options {
+	if !c.AutoApplyDetectedOptions || t == nil {
+		return o
+	}
+	opts, ok := c.appliedOptions.Load(opTypeKey{op, t})
+	if !ok {
+		return o
+	}
+	c.add(&c.NumAutoApplied, 1)
+	return append(slices.Clip(o), opts.(jsonv2.Options))
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestAutoApplyDetectedOptions(t *testing.T) {
+	var got [][]string
+	codec := &Codec{
+		AutoDetectOptions:        true,
+		AutoApplyDetectedOptions: true,
+		ReportDifference: func(d Difference) {
+			got = append(got, slices.Collect(d.OptionNames()))
+		},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV2)
+	codec.SetUnmarshalCallMode(CallBothButReturnV2)
+
+	// The first call for each type reports a difference and returns the
+	// v2 result, but subsequent calls converge on the v1 behavior.
+	for i := range 3 {
+		b, err := codec.Marshal([]int(nil))
+		if i > 0 && (err != nil || string(b) != "null") {
+			t.Errorf("Marshal = (%s, %v), want (null, nil)", b, err)
+		}
+	}
+	for i := range 3 {
+		b, err := codec.Marshal(map[string]int(nil))
+		if i > 0 && (err != nil || string(b) != "null") {
+			t.Errorf("Marshal = (%s, %v), want (null, nil)", b, err)
+		}
+	}
+	for i := range 3 {
+		var v struct{ Name string }
+		if err := codec.Unmarshal([]byte(`{"name":"x"}`), &v); i > 0 && (err != nil || v.Name != "x") {
+			t.Errorf("Unmarshal = (%+v, %v), want ({Name:x}, nil)", v, err)
+		}
+	}
+
+	want := [][]string{
+		{"jsonv2.FormatNilSliceAsNull"},
+		{"jsonv2.FormatNilMapAsNull"},
+		{"jsonv2.MatchCaseInsensitiveNames"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d differences, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("difference %d: options = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if n := codec.NumAutoApplied.Value(); n != 6 {
+		t.Errorf("NumAutoApplied = %d, want 6", n)
+	}
+
+	// Options are not applied to other types or without the mode.
+	if b, _ := codec.Marshal([]string(nil)); string(b) != "[]" {
+		t.Errorf("Marshal([]string(nil)) = %s, want []", b)
+	}
+	codec.AutoApplyDetectedOptions = false
+	if b, _ := codec.Marshal([]int(nil)); string(b) != "[]" {
+		t.Errorf("Marshal([]int(nil)) = %s, want []", b)
+	}
+
+	// Applied options are reported as in effect for v2.
+	codec.AutoApplyDetectedOptions = true
+	type T struct {
+		S []int
+		H string
+	}
+	codec.Marshal(T{})
+	var gotV2 jsonv2.Options
+	codec.ReportDifference = func(d Difference) { gotV2 = d.OptionsV2 }
+	codec.Marshal(T{H: "<>"})
+	if v, ok := jsonv2.GetOption(gotV2, jsonv2.FormatNilSliceAsNull); !v || !ok {
+		t.Errorf("OptionsV2 does not include the applied jsonv2.FormatNilSliceAsNull option")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// detectionBudget tracks the number of auto-detections within the
+// current one minute window for [Codec.MaxDetectionsPerMinute]
+// and [Codec.MaxDetectionsPerType].
+type detectionBudget struct {
+	mu      sync.Mutex
+	now     func() time.Time // for testing; if nil, uses time.Now
+	window  time.Time        // start of the current window
+	total   int
+	perType map[reflect.Type]int
+}
+
+// allowDetection reports whether auto-detection of options may run
+// for a difference in the Go type t according to the detection budget,
+// and if so, consumes one detection from the budget.
+// Otherwise, it is counted in [CodecMetrics.NumAutoDetectBudgetExceeded].
+func (c *Codec) allowDetection(t reflect.Type) bool {
+	if c.MaxDetectionsPerMinute <= 0 && c.MaxDetectionsPerType <= 0 {
+		return true
+	}
+	if !c.detectionBudget.consume(c.MaxDetectionsPerMinute, c.MaxDetectionsPerType, t) {
+		c.add(&c.NumAutoDetectBudgetExceeded, 1)
+		return false
+	}
+	return true
+}
+
+// consume consumes one detection for the Go type t from the budget
+// if neither the total nor the per-type limit (if positive) is reached.
+func (b *detectionBudget) consume(maxTotal, maxPerType int, t reflect.Type) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.now != nil {
+		now = b.now()
+	}
+	if now.Sub(b.window) >= time.Minute {
+		b.window, b.total, b.perType = now, 0, nil
+	}
+	if (maxTotal > 0 && b.total >= maxTotal) || (maxPerType > 0 && b.perType[t] >= maxPerType) {
+		return false
+	}
+	if b.perType == nil {
+		b.perType = make(map[reflect.Type]int)
+	}
+	b.total++
+	b.perType[t]++
+	return true
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectionBudget(t *testing.T) {
+	var got [][]string
+	codec := &Codec{
+		AutoDetectOptions:      true,
+		MaxDetectionsPerMinute: 3,
+		MaxDetectionsPerType:   2,
+		ReportDifference: func(d Difference) {
+			got = append(got, slices.Collect(d.OptionNames()))
+		},
+	}
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	codec.detectionBudget.now = func() time.Time { return now }
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	codec.Marshal([]int(nil))
+	codec.Marshal([]int(nil))             // exceeds the per-type budget
+	codec.Marshal(map[string]int(nil))    // within the total budget
+	codec.Marshal(map[string]string(nil)) // exceeds the total budget
+	now = now.Add(time.Minute)
+	codec.Marshal([]int(nil)) // new window
+
+	want := [][]string{
+		{"jsonv2.FormatNilSliceAsNull"},
+		{"jsonv2.FormatNilSliceAsNull"},
+		nil,
+		{"jsonv2.FormatNilMapAsNull"},
+		nil,
+		{"jsonv2.FormatNilSliceAsNull"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d differences, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("difference %d: options = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if n := codec.NumAutoDetectBudgetExceeded.Value(); n != 2 {
+		t.Errorf("NumAutoDetectBudgetExceeded = %d, want 2", n)
+	}
+}
+
+func TestDetectionBudgetCached(t *testing.T) {
+	var got [][]string
+	codec := &Codec{
+		AutoDetectOptions:    true,
+		MaxDetectionsPerType: 1,
+		ReportDifference: func(d Difference) {
+			got = append(got, slices.Collect(d.OptionNames()))
+		},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	// Options cached for the type do not consume the budget.
+	for range 3 {
+		MarshalFor(codec, []int(nil))
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d differences, want 3", len(got))
+	}
+	for i, names := range got {
+		if !slices.Equal(names, []string{"jsonv2.FormatNilSliceAsNull"}) {
+			t.Errorf("difference %d: options = %v, want [jsonv2.FormatNilSliceAsNull]", i, names)
+		}
+	}
+	if n := codec.NumAutoDetectBudgetExceeded.Value(); n != 0 {
+		t.Errorf("NumAutoDetectBudgetExceeded = %d, want 0", n)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OptionCategory classifies the effect of an option
+// reported in [Difference.Options] (see [Difference.OptionCategories]).
+type OptionCategory int
+
+const (
+	// OptionCategorySemantic is an option that affects the behavior
+	// of marshal or unmarshal (e.g., [jsonv2.FormatNilSliceAsNull]).
+	OptionCategorySemantic OptionCategory = iota
+	// OptionCategoryFormatting is an option that only affects
+	// how the JSON output is formatted, but not the JSON value it represents
+	// (e.g., [jsontext.EscapeForHTML] or [jsontext.Multiline]).
+	OptionCategoryFormatting
+
+	numOptionCategories = iota
+)
+
+var optionCategoryNames = [numOptionCategories]string{
+	OptionCategorySemantic:   "Semantic",
+	OptionCategoryFormatting: "Formatting",
+}
+
+// String returns the name of the category (e.g., "Formatting").
+func (c OptionCategory) String() string {
+	if 0 <= c && c < numOptionCategories {
+		return optionCategoryNames[c]
+	}
+	return fmt.Sprintf("OptionCategory(%d)", int(c))
+}
+
+// MarshalText returns the name of the category.
+func (c OptionCategory) MarshalText() ([]byte, error) {
+	if 0 <= c && c < numOptionCategories {
+		return []byte(optionCategoryNames[c]), nil
+	}
+	return nil, fmt.Errorf("invalid option category: %d", int(c))
+}
+
+// UnmarshalText parses the name of a category.
+func (c *OptionCategory) UnmarshalText(b []byte) error {
+	for i, name := range optionCategoryNames {
+		if string(b) == name {
+			*c = OptionCategory(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid option category: %q", b)
+}
+
+// formattingOptionNames are the names of options not in [AllOptions]
+// that only affect formatting.
+// Options with arguments are matched by the name before the parenthesis
+// (e.g., `jsontext.WithIndent("  ")`).
+var formattingOptionNames = map[string]bool{
+	"jsontext.SpaceAfterColon": true,
+	"jsontext.SpaceAfterComma": true,
+	"jsontext.Multiline":       true,
+	"jsontext.WithIndent":      true,
+}
+
+// optionCategory returns the category of the option with the given name
+// as reported by [Difference.OptionNames].
+func optionCategory(name string) OptionCategory {
+	name, _, _ = strings.Cut(name, "(")
+	if info, ok := LookupOption(name); ok {
+		return info.Category
+	}
+	if formattingOptionNames[name] {
+		return OptionCategoryFormatting
+	}
+	return OptionCategorySemantic
+}
+
+// optionCategories categorizes every option in [Difference.Options].
+// It returns nil if there are no options.
+func optionCategories(d Difference) map[string]OptionCategory {
+	var m map[string]OptionCategory
+	for name := range d.OptionNames() {
+		if m == nil {
+			m = make(map[string]OptionCategory)
+		}
+		m[name] = optionCategory(name)
+	}
+	return m
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"maps"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestOptionCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		want OptionCategory
+	}{
+		{"jsontext.EscapeForHTML", OptionCategoryFormatting},
+		{"jsontext.EscapeForJS", OptionCategoryFormatting},
+		{"jsontext.PreserveRawStrings", OptionCategoryFormatting},
+		{`jsontext.WithIndent("  ")`, OptionCategoryFormatting},
+		{"jsontext.AllowDuplicateNames", OptionCategorySemantic},
+		{"jsonv2.FormatNilSliceAsNull", OptionCategorySemantic},
+	}
+	for _, tt := range tests {
+		if got := optionCategory(tt.name); got != tt.want {
+			t.Errorf("optionCategory(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for c := range OptionCategory(numOptionCategories) {
+		b, err := jsonv2.Marshal(c)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var got OptionCategory
+		if err := jsonv2.Unmarshal(b, &got); err != nil || got != c {
+			t.Errorf("Unmarshal(%s) = (%v, %v), want %v", b, got, err, c)
+		}
+	}
+
+	var got map[string]OptionCategory
+	codec := &Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { got = d.OptionCategories }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(struct {
+		S []int
+		H string
+	}{H: "<>"})
+	want := map[string]OptionCategory{
+		"jsontext.EscapeForHTML":      OptionCategoryFormatting,
+		"jsonv2.FormatNilSliceAsNull": OptionCategorySemantic,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("OptionCategories = %v, want %v", got, want)
+	}
+}
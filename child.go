@@ -0,0 +1,90 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"reflect"
+	"runtime"
+	"weak"
+)
+
+// NewChild returns a new codec that inherits the configuration of c
+// so that individual settings can be overridden (e.g., per team)
+// without duplicating the entire configuration.
+//
+// The child starts with a copy of every exported field of c
+// (e.g., [Codec.ReportDifference] and the equality functions)
+// and the functions marked by [Codec.Helper].
+// Subsequent changes to those fields of c are not reflected in the child.
+//
+// The ratios set by [Codec.SetMarshalCallRatio],
+// [Codec.SetUnmarshalCallRatio], and [Codec.SetGoValueCaptureRatio]
+// are not copied, but resolved through c at the time of every call
+// until they are set on the child. Thus, switching c back to [OnlyCallV1]
+// (e.g., as a kill switch) also switches every child that did not override it.
+// Accordingly, changes to the configuration of c are also reported
+// to the functions registered on the child by [Codec.WatchConfig].
+// The [CodecMetrics] of the child start at zero and
+// [Codec.MetricsBackend] is not inherited,
+// but metrics may be rolled up to c with [Codec.RollUpMetrics].
+//
+// The [GlobalCodec] may be used as the parent with GlobalCodec.NewChild().
+func (c *Codec) NewChild() *Codec {
+	child := &Codec{parent: c}
+	src := reflect.ValueOf(c).Elem()
+	dst := reflect.ValueOf(child).Elem()
+	for i := range src.NumField() {
+		switch sf := src.Type().Field(i); {
+		case !sf.IsExported() || sf.Anonymous:
+			continue // only configuration fields
+		case sf.Name == "MetricsBackend" || sf.Name == "RollUpMetrics":
+			continue // metrics are specific to each codec
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+	c.helperCallers.Range(func(k, v any) bool {
+		child.helperCallers.Store(k, v)
+		return true
+	})
+	c.helperEntries.Range(func(k, v any) bool {
+		child.helperEntries.Store(k, v)
+		return true
+	})
+	wp := weak.Make(child)
+	c.children.Store(wp, struct{}{})
+	runtime.AddCleanup(child, func(wp weak.Pointer[Codec]) { c.children.Delete(wp) }, wp)
+	return child
+}
+
+// Parent returns the codec that c was created from by [Codec.NewChild],
+// or nil if c was not created by [Codec.NewChild].
+func (c *Codec) Parent() *Codec {
+	return c.parent
+}
+
+// parentMetric returns the metric in the parent of c
+// that corresponds to the metric v in c.
+// It returns nil if metrics are not rolled up to a parent
+// or if the parent has no corresponding metric.
+func (c *Codec) parentMetric(v expvar.Var) (*Codec, expvar.Var) {
+	if c.parent == nil || !c.RollUpMetrics {
+		return nil, nil
+	}
+	if pv, ok := c.parentMetrics.Load(v); ok {
+		return c.parent, pv.(expvar.Var)
+	}
+	name, ok := c.lookupMetricName(v)
+	if !ok {
+		return nil, nil
+	}
+	for name2, pv := range c.parent.All() {
+		if name2 == name {
+			c.parentMetrics.Store(v, pv)
+			return c.parent, pv
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"testing"
+)
+
+func TestNewChild(t *testing.T) {
+	var got []string
+	b := &testBackend{counters: make(map[string]int64), histograms: make(map[string][]int64)}
+	parent := &Codec{
+		AutoDetectOptions:    true,
+		TrackCallerDiffRates: true,
+		ReportDifference:     func(d Difference) { got = append(got, d.Func) },
+		MetricsBackend:       b,
+	}
+	parent.SetMarshalCallRatio(OnlyCallV1, CallBothButReturnV1, 1)
+	parent.SetUnmarshalCallMode(CallBothButReturnV2)
+
+	child := parent.NewChild()
+	if child.Parent() != parent {
+		t.Errorf("Parent = %p, want %p", child.Parent(), parent)
+	}
+	if !child.AutoDetectOptions || child.MetricsBackend != nil {
+		t.Errorf("AutoDetectOptions, MetricsBackend = %v, %v, want true, nil", child.AutoDetectOptions, child.MetricsBackend)
+	}
+	if mode1, mode2, ratio := child.MarshalCallRatio(); mode1 != OnlyCallV1 || mode2 != CallBothButReturnV1 || ratio != 1 {
+		t.Errorf("MarshalCallRatio = (%v, %v, %v), want (OnlyCallV1, CallBothButReturnV1, 1)", mode1, mode2, ratio)
+	}
+
+	// Overrides in the child do not affect the parent.
+	child.SetUnmarshalCallMode(CallBothButReturnV1)
+	if mode, _, _ := parent.UnmarshalCallRatio(); mode != CallBothButReturnV2 {
+		t.Errorf("parent UnmarshalCallRatio mode = %v, want CallBothButReturnV2", mode)
+	}
+
+	// Unset ratios are resolved through the parent at the time of the call.
+	parent.SetUnmarshalCallMode(OnlyCallV1)
+	parent.SetGoValueCaptureRatio(0.5)
+	if mode, _, _ := child.UnmarshalCallRatio(); mode != CallBothButReturnV1 {
+		t.Errorf("child UnmarshalCallRatio mode = %v, want CallBothButReturnV1", mode)
+	}
+	if ratio := child.GoValueCaptureRatio(); ratio != 0.5 {
+		t.Errorf("child GoValueCaptureRatio = %v, want 0.5", ratio)
+	}
+	parent.SetMarshalCallMode(OnlyCallV1)
+	if mode, _, _ := child.MarshalCallRatio(); mode != OnlyCallV1 {
+		t.Errorf("child MarshalCallRatio mode = %v, want OnlyCallV1", mode)
+	}
+	if child.Marshal([]int(nil)); child.NumMarshalCallBoth.Value() != 0 {
+		t.Errorf("child NumMarshalCallBoth = %d, want 0", child.NumMarshalCallBoth.Value())
+	}
+	parent.SetMarshalCallRatio(OnlyCallV1, CallBothButReturnV1, 1)
+	parent.SetGoValueCaptureRatio(1)
+
+	// Unknown metrics are not rolled up.
+	child.RollUpMetrics = true
+	if p, pv := child.parentMetric(new(expvar.Int)); p != nil || pv != nil {
+		t.Errorf("parentMetric(unknown) = (%p, %v), want (nil, nil)", p, pv)
+	}
+	child.RollUpMetrics = false
+
+	// Differences are reported with the inherited function,
+	// but metrics are only rolled up if requested.
+	child.Marshal([]int(nil))
+	if len(got) != 1 || child.NumMarshalDiffs.Value() != 1 || parent.NumMarshalDiffs.Value() != 0 {
+		t.Errorf("got %d differences with %d diffs in child and %d in parent, want 1, 1, 0",
+			len(got), child.NumMarshalDiffs.Value(), parent.NumMarshalDiffs.Value())
+	}
+	child.RollUpMetrics = true
+	child.Marshal([]int(nil))
+	child.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	if got := parent.NumMarshalDiffs.Value(); got != 1 {
+		t.Errorf("parent NumMarshalDiffs = %d, want 1", got)
+	}
+	if got := parent.NumUnmarshalDiffs.Value(); got != 1 {
+		t.Errorf("parent NumUnmarshalDiffs = %d, want 1", got)
+	}
+	if got := len(b.histograms["marshal_size_histogram"]); got != 1 {
+		t.Errorf("parent backend marshal_size_histogram has %d observations, want 1", got)
+	}
+	if got := len(parent.UnmarshalCallerDiffRates.m); got != 1 {
+		t.Errorf("parent UnmarshalCallerDiffRates has %d callers, want 1", got)
+	}
+	if got := b.counters["num_marshal_diffs"]; got != 1 {
+		t.Errorf("parent backend num_marshal_diffs = %d, want 1", got)
+	}
+}
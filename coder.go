@@ -0,0 +1,361 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	jsonv1std "encoding/json"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"         // TODO: Use "encoding/json"
+)
+
+// Encoder is a drop-in replacement for [jsonv1.Encoder] that writes
+// each value using [Codec.Marshal], such that code using an encoder
+// may call v1, v2, or both according to [Codec.SetMarshalCallRatio].
+type Encoder struct {
+	codec *Codec
+	w     io.Writer
+	opts  []jsonv2.Options
+	err   error
+
+	// prefix and indent are the indentation unsupported by v2
+	// applied with [jsonv1std.Indent] after marshaling (see [Encoder.SetIndent]).
+	prefix, indent string
+}
+
+// NewEncoder returns a new encoder that writes to w using the [GlobalCodec].
+func NewEncoder(w io.Writer) *Encoder {
+	return GlobalCodec.NewEncoder(w)
+}
+
+// NewEncoder returns a new encoder that writes to w using c.
+func (c *Codec) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{codec: c, w: w}
+}
+
+// Encode writes the JSON encoding of v to the stream,
+// followed by a newline character. See [jsonv1.Encoder.Encode].
+func (e *Encoder) Encode(v any) error {
+	if e.err != nil {
+		return e.err
+	}
+	b, err := e.codec.Marshal(v, e.opts...)
+	if err != nil {
+		return err
+	}
+	if e.prefix != "" || e.indent != "" {
+		var buf bytes.Buffer
+		if err := jsonv1std.Indent(&buf, b, e.prefix, e.indent); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	}
+	if _, err := e.w.Write(append(b, '\n')); err != nil {
+		e.err = err
+		return err
+	}
+	return nil
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value
+// with the provided prefix and indent. See [jsonv1.Encoder.SetIndent].
+//
+// Since v2 only supports a prefix and indent composed of spaces and tabs,
+// values are marshaled compactly for any other characters
+// and then indented with [jsonv1std.Indent] (as with [Codec.MarshalIndent]).
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix, e.indent = "", ""
+	if prefix == "" && indent == "" {
+		e.opts = append(e.opts, jsontext.Multiline(false))
+		return
+	}
+	if strings.Trim(prefix, " \t") != "" || strings.Trim(indent, " \t") != "" {
+		e.prefix, e.indent = prefix, indent
+		e.opts = append(e.opts, jsontext.Multiline(false))
+		return
+	}
+	e.opts = append(e.opts, jsontext.WithIndentPrefix(prefix), jsontext.WithIndent(indent))
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters
+// should be escaped inside JSON quoted strings.
+// See [jsonv1.Encoder.SetEscapeHTML].
+//
+// Unless explicitly set, v1 escapes HTML characters while v2 does not.
+// Once set, the setting applies to both v1 and v2.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.opts = append(e.opts, jsontext.EscapeForHTML(on))
+}
+
+// Decoder is a drop-in replacement for [jsonv1.Decoder] that reads
+// each value using [Codec.Unmarshal], such that code using a decoder
+// may call v1, v2, or both according to [Codec.SetUnmarshalCallRatio].
+//
+// The boundaries of each JSON value in the stream are determined
+// in the same way as v1 (see [Decoder.Token] for how they are compared).
+type Decoder struct {
+	codec *Codec
+	dec   *jsontext.Decoder
+	opts  []jsonv2.Options
+	err   error
+
+	useNumber bool
+
+	// shadow is a [jsonv1std.Decoder] reading the same input stream
+	// to compare against. It is nil if the streams are not compared.
+	shadow *jsonv1std.Decoder
+	split  *splitReader
+}
+
+// NewDecoder returns a new decoder that reads from r using the [GlobalCodec].
+func NewDecoder(r io.Reader) *Decoder {
+	return GlobalCodec.NewDecoder(r)
+}
+
+// NewDecoder returns a new decoder that reads from r using c.
+// The decoder introduces its own buffering and may
+// read data from r beyond the JSON values requested.
+//
+// If the mode selected according to [Codec.SetUnmarshalCallRatio]
+// calls both v1 and v2, then the token stream of the decoder is compared
+// against [jsonv1std.Decoder] (see [Decoder.Token]).
+func (c *Codec) NewDecoder(r io.Reader) *Decoder {
+	// Hide bytes.Buffer from jsontext (see jsonv1.NewDecoder).
+	if _, ok := r.(*bytes.Buffer); ok {
+		r = struct{ io.Reader }{r}
+	}
+	d := &Decoder{codec: c}
+	switch c.unmarshalRatio().loadRandomMode() {
+	case CallBothButReturnV1, CallBothButReturnV2, CallBothV1StdAndV1Emulated:
+		d.split = &splitReader{r: r}
+		d.shadow = jsonv1std.NewDecoder(splitSide{d.split, 1})
+		r = splitSide{d.split, 0}
+	}
+	d.dec = jsontext.NewDecoder(r, jsonv1.DefaultOptionsV1())
+	return d
+}
+
+// DisallowUnknownFields causes the decoder to return an error when
+// the destination is a struct and the input contains unknown members.
+// See [jsonv1.Decoder.DisallowUnknownFields].
+func (d *Decoder) DisallowUnknownFields() {
+	d.opts = append(d.opts, jsonv2.RejectUnknownMembers(true))
+}
+
+// UseNumber causes the decoder to unmarshal a number into
+// an empty interface value as a [jsonv1.Number] instead of as a float64.
+// See [jsonv1.Decoder.UseNumber].
+func (d *Decoder) UseNumber() {
+	if !d.useNumber {
+		d.useNumber = true
+		d.opts = append(d.opts, useNumber())
+		if d.shadow != nil {
+			d.shadow.UseNumber()
+		}
+	}
+}
+
+// useNumber is the option used by [Decoder.UseNumber].
+// It is specified identically to both v1 and v2.
+var useNumber = sync.OnceValue(func() jsonv2.Options {
+	return jsonv2.WithUnmarshalers(jsonv2.UnmarshalFromFunc(func(dec *jsontext.Decoder, v *any) error {
+		if dec.PeekKind() != '0' || *v != nil {
+			return jsonv2.SkipFunc
+		}
+		val, err := dec.ReadValue()
+		if err != nil {
+			return err
+		}
+		*v = jsonv1.Number(val)
+		return nil
+	}))
+})
+
+// usesNumber reports whether opts unmarshals a JSON number into
+// an empty interface value as a [jsonv1.Number] (see [Decoder.UseNumber]).
+func usesNumber(opts jsonv2.Options) bool {
+	var v any
+	err := jsonv2.Unmarshal([]byte("0"), &v, opts)
+	_, ok := v.(jsonv1.Number)
+	return err == nil && ok
+}
+
+// Decode reads the next JSON value from its input and
+// stores it in the value pointed to by v. See [jsonv1.Decoder.Decode].
+func (d *Decoder) Decode(v any) error {
+	if d.err != nil {
+		return d.err
+	}
+	offset := d.dec.InputOffset()
+	b, err := d.dec.ReadValue()
+	if d.shadow != nil {
+		var b1 jsonv1std.RawMessage
+		err1 := d.shadow.Decode(&b1)
+		if (err1 == nil) != (err == nil) {
+			d.reportTokenDiff(offset, b1, jsontext.Value(bytes.Clone(b)), err1, err)
+		}
+		if err1 != nil || err != nil {
+			d.stopShadow()
+		}
+	}
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.ErrUnexpectedEOF // same as v1
+		}
+		d.err = err
+		return err
+	}
+	return d.codec.Unmarshal(b, v, d.opts...)
+}
+
+// More reports whether there is another element in the
+// current array or object being parsed. See [jsonv1.Decoder.More].
+func (d *Decoder) More() bool {
+	k := d.dec.PeekKind()
+	return k > 0 && k != ']' && k != '}'
+}
+
+// Buffered returns a reader of the data remaining in the decoder's buffer.
+// The reader is valid until the next call to [Decoder.Decode].
+func (d *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(d.dec.UnreadBuffer())
+}
+
+// Token returns the next JSON token in the input stream.
+// At the end of the input stream, Token returns nil, [io.EOF].
+// See [jsonv1.Decoder.Token].
+//
+// If the token stream is compared (see [Codec.NewDecoder]),
+// then each token is also read with [jsonv1std.Decoder.Token]
+// and the first token where they diverge is reported as a [Difference]
+// with a Check of "Token", after which the streams are no longer compared.
+// Regardless of comparison, the returned token is always
+// produced by [jsontext.Decoder].
+func (d *Decoder) Token() (jsonv1.Token, error) {
+	offset := d.dec.InputOffset()
+	tok, err := d.readToken()
+	if d.shadow != nil {
+		tok1, err1 := d.shadow.Token()
+		switch t := tok1.(type) {
+		case jsonv1std.Delim:
+			tok1 = jsonv1.Delim(t)
+		case jsonv1std.Number:
+			tok1 = jsonv1.Number(t)
+		}
+		if (err1 == nil) != (err == nil) || (err == nil && tok1 != tok) {
+			d.reportTokenDiff(offset, tok1, tok, err1, err)
+			d.stopShadow()
+		} else if err != nil {
+			d.stopShadow()
+		}
+	}
+	return tok, err
+}
+
+// readToken reads the next token in the same way as [jsonv1.Decoder.Token].
+func (d *Decoder) readToken() (jsonv1.Token, error) {
+	tok, err := d.dec.ReadToken()
+	if err != nil {
+		// Report errors for truncated input in the same way as v1.
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			if len(bytes.Trim(d.dec.UnreadBuffer(), " \r\n\t,:")) == 0 {
+				return nil, io.EOF
+			}
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch k := tok.Kind(); k {
+	case 'n':
+		return nil, nil
+	case 'f':
+		return false, nil
+	case 't':
+		return true, nil
+	case '"':
+		return tok.String(), nil
+	case '0':
+		if d.useNumber {
+			return jsonv1.Number(tok.String()), nil
+		}
+		return tok.Float(), nil
+	default:
+		return jsonv1.Delim(k), nil
+	}
+}
+
+// reportTokenDiff reports a divergence between the token streams.
+func (d *Decoder) reportTokenDiff(offset int64, tok1, tok2 any, err1, err2 error) {
+	c := d.codec
+	c.add(&c.NumUnmarshalTokenDiffs, 1)
+	diff := Difference{
+		Caller:        c.caller(),
+		Func:          "Unmarshal",
+		Check:         "Token",
+		GoValueV1:     tok1,
+		GoValueV2:     tok2,
+		InputOffset:   offset,
+		ErrorV1:       err1,
+		ErrorV2:       err2,
+		CallerOptions: callerOptions(d.opts),
+	}
+	c.classifyDifference(&diff)
+	if c.reportsDifferences() && c.sampleReport() {
+		c.reportDifference(diff)
+	}
+}
+
+// stopShadow stops comparing the token streams.
+func (d *Decoder) stopShadow() {
+	d.shadow = nil
+	d.split.stop()
+}
+
+// splitReader splits a reader such that two readers (see [splitSide])
+// each observe the entire stream, where data read by one side
+// is buffered until it is read by the other side.
+type splitReader struct {
+	r       io.Reader
+	err     error
+	bufs    [2][]byte // unread data for each side
+	stopped bool      // whether the second side is no longer read
+}
+
+// stop stops buffering data for the second side.
+func (s *splitReader) stop() {
+	s.stopped = true
+	s.bufs[1] = nil
+}
+
+// splitSide is one side of a [splitReader].
+type splitSide struct {
+	s *splitReader
+	i int
+}
+
+func (r splitSide) Read(b []byte) (int, error) {
+	s := r.s
+	if buf := s.bufs[r.i]; len(buf) > 0 {
+		n := copy(b, buf)
+		s.bufs[r.i] = buf[n:]
+		return n, nil
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	n, err := s.r.Read(b)
+	if other := 1 - r.i; other == 0 || !s.stopped {
+		s.bufs[other] = append(s.bufs[other], b[:n]...)
+	}
+	s.err = err
+	return n, err
+}
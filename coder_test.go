@@ -0,0 +1,202 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncoder(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf)
+	wantCaller := callerPlus(codec.caller(), 1)
+	if err := enc.Encode("<html>"); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(map[string]string{"k": "<html>"}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if want := "\"\\u003chtml\\u003e\"\n{\n\t\"k\": \"<html>\"\n}\n"; buf.String() != want {
+		t.Errorf("Encode output = %q, want %q", buf.String(), want)
+	}
+	if len(got) != 1 || got[0].Caller != wantCaller {
+		t.Errorf("got differences %v, want one difference from %s", got, wantCaller)
+	}
+}
+
+func TestEncoderUnsupportedIndent(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV2)
+
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf)
+	enc.SetIndent(">", "--")
+	if err := enc.Encode(map[string][]int{"k": {1}}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	enc.SetIndent("", " ")
+	if err := enc.Encode([]int{1}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if want := "{\n>--\"k\": [\n>----1\n>--]\n>}\n[\n 1\n]\n"; buf.String() != want {
+		t.Errorf("Encode output = %q, want %q", buf.String(), want)
+	}
+	if len(got) > 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	dec := codec.NewDecoder(strings.NewReader(`{"NAME":"x"} {"Name":"y"} [`))
+	var users []reverifyUser
+	wantCaller := callerPlus(codec.caller(), 3)
+	for dec.More() {
+		var u reverifyUser
+		if err := dec.Decode(&u); err != nil {
+			if err != io.ErrUnexpectedEOF {
+				t.Errorf("Decode error = %v, want %v", err, io.ErrUnexpectedEOF)
+			}
+			break
+		}
+		users = append(users, u)
+	}
+	if len(users) != 2 || users[0].Name != "x" || users[1].Name != "y" {
+		t.Errorf("Decode = %v, want [{x} {y}]", users)
+	}
+	if len(got) != 1 || got[0].Caller != wantCaller {
+		t.Errorf("got differences %v, want one difference from %s", got, wantCaller)
+	}
+
+	dec = codec.NewDecoder(strings.NewReader(`{"Name":"x","Extra":1} rest`))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(new(reverifyUser)); err == nil {
+		t.Errorf("Decode error = nil, want unknown member error")
+	}
+	if b, _ := io.ReadAll(dec.Buffered()); string(b) != " rest" {
+		t.Errorf("Buffered = %q, want %q", b, " rest")
+	}
+}
+
+func TestDecoderKnobs(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		AutoDetectOptions: true,
+		ReportDifference:  func(d Difference) { got = append(got, d) },
+	}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// The input only differs between v1 and v2 because unknown members
+	// are rejected, where "NAME" is unknown to v2 since it is case-sensitive.
+	dec := codec.NewDecoder(strings.NewReader(`{"NAME":null,"Aliases":[1.50]} [1.50, 2] `))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	var u struct {
+		Name    string
+		Aliases []any
+	}
+	if err := dec.Decode(&u); err != nil {
+		t.Errorf("Decode error: %v", err)
+	}
+	if diff := cmp.Diff(u.Aliases, []any{jsonv1.Number("1.50")}); diff != "" {
+		t.Errorf("Decode mismatch (-got +want):\n%s", diff)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	if diff := cmp.Diff(slices.Collect(got[0].CallerOptionNames()), []string{"jsonv2.RejectUnknownMembers", "jsonv1.Decoder.UseNumber"}); diff != "" {
+		t.Errorf("CallerOptionNames mismatch (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(slices.Collect(optionNamesOf(got[0].CausingOptions, true)), []string{"jsonv2.RejectUnknownMembers"}); diff != "" {
+		t.Errorf("CausingOptions mismatch (-got +want):\n%s", diff)
+	}
+
+	// Numbers are tokenized in the same way as v1.
+	var toks []jsonv1.Token
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		toks = append(toks, tok)
+	}
+	if diff := cmp.Diff(toks, []jsonv1.Token{jsonv1.Delim('['), jsonv1.Number("1.50"), jsonv1.Number("2"), jsonv1.Delim(']')}); diff != "" {
+		t.Errorf("Token mismatch (-got +want):\n%s", diff)
+	}
+	if len(got) != 1 {
+		t.Errorf("got differences %v, want no token differences", got[1:])
+	}
+}
+
+func TestDecoderToken(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// Mixing tokens and values does not diverge.
+	dec := codec.NewDecoder(strings.NewReader(`[{"Name":"x"}, 1, "a"] `))
+	var toks []jsonv1.Token
+	for {
+		if tok, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("Token error = %v, want %v", err, io.EOF)
+			}
+			break
+		} else {
+			toks = append(toks, tok)
+		}
+		if len(toks) == 1 {
+			var u reverifyUser
+			if err := dec.Decode(&u); err != nil || u.Name != "x" {
+				t.Errorf("Decode = (%v, %v), want ({x}, nil)", u, err)
+			}
+		}
+	}
+	if want := []jsonv1.Token{jsonv1.Delim('['), 1.0, "a", jsonv1.Delim(']')}; !reflect.DeepEqual(toks, want) {
+		t.Errorf("Token = %v, want %v", toks, want)
+	}
+	if len(got) > 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+
+	// Numbers that overflow a float64 are an error in v1.
+	dec = codec.NewDecoder(strings.NewReader(`[1, "a", 1e1000, true]`))
+	wantCaller := callerPlus(codec.caller(), 2)
+	for range 4 {
+		dec.Token()
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	if d := got[0]; d.Caller != wantCaller || d.Check != "Token" || d.InputOffset != 7 ||
+		d.ErrorV1 == nil || d.ErrorV2 != nil || d.GoValueV2 != math.MaxFloat64 {
+		t.Errorf("got difference %v, want a token difference from %s at offset 7", d, wantCaller)
+	}
+	if n := codec.NumUnmarshalTokenDiffs.Value(); n != 1 {
+		t.Errorf("NumUnmarshalTokenDiffs = %d, want 1", n)
+	}
+	if tok, err := dec.Token(); tok != true || err != nil {
+		t.Errorf("Token = (%v, %v), want (true, nil)", tok, err)
+	}
+}
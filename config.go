@@ -0,0 +1,245 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"weak"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// ConfigSnapshot is a point-in-time copy of the effective configuration
+// of a [Codec]. See [Codec.ConfigJSON] and [Codec.WatchConfig].
+type ConfigSnapshot struct {
+	// MarshalCallRatio is the ratio set by [Codec.SetMarshalCallRatio].
+	MarshalCallRatio CallRatioConfig
+	// UnmarshalCallRatio is the ratio set by [Codec.SetUnmarshalCallRatio].
+	UnmarshalCallRatio CallRatioConfig
+	// ForcedCallMode is the call mode forced by [ForceModeEnv], if any.
+	ForcedCallMode string `json:",omitzero"`
+	// GoValueCaptureRatio is the ratio set by [Codec.SetGoValueCaptureRatio].
+	GoValueCaptureRatio float64
+	// DefaultOptions are the names of options in [Codec.DefaultOptions].
+	DefaultOptions []string `json:",omitzero"`
+	// V1Implementation is the name of [Codec.V1Implementation]
+	// if it is not [AutoSelectV1].
+	V1Implementation string `json:",omitzero"`
+	// TypeOptions are the names of the options registered by
+	// [RegisterTypeOptions] keyed by the name of the Go type.
+	TypeOptions map[string][]string `json:",omitzero"`
+
+	AutoDetectOptions bool
+	// AutoDetectParallelism is the effective parallelism
+	// after resolving negative values of [Codec.AutoDetectParallelism].
+	AutoDetectParallelism    int
+	MaxDetectionsPerMinute   int  `json:",omitzero"`
+	MaxDetectionsPerType     int  `json:",omitzero"`
+	AutoApplyDetectedOptions bool `json:",omitzero"`
+	// OptionProbes are the names of options registered by [RegisterOptionProbe].
+	OptionProbes []string `json:",omitzero"`
+
+	CopyDifferenceValues bool
+	DiffGoValues         bool
+	// KnownDifferences is the number of fingerprints in
+	// [Codec.KnownDifferences], or nil if it is not set.
+	KnownDifferences        *int    `json:",omitzero"`
+	MaxReportsPerDifference int     `json:",omitzero"`
+	ReportSampleRate        float64 `json:",omitzero"`
+	AsyncReportQueueSize    int     `json:",omitzero"`
+	// ReportSeverities are the names of [Codec.ReportSeverities].
+	ReportSeverities []string `json:",omitzero"`
+
+	VerifyCrossDecode bool
+	VerifyRoundTrip   bool
+	VerifyInterop     bool
+	VerifyStandardV1  bool
+	TranslateV2Errors bool
+
+	// Transformers are the names of the Go types in [Codec.Transformers].
+	Transformers            []string `json:",omitzero"`
+	StreamMarshalComparison bool
+	HashGoValuesAboveSize   int
+	IgnoreGoValuesOnErrors  bool
+	CallerSkipPrefixes      []string `json:",omitzero"`
+	TrackCallerDiffRates    bool     `json:",omitzero"`
+
+	// Hooks are the names of all function fields in [Codec] that are set.
+	Hooks []string `json:",omitzero"`
+	// DifferenceHandlers is the number of handlers
+	// added by [Codec.AddDifferenceHandler].
+	DifferenceHandlers int `json:",omitzero"`
+
+	// MetricsBackend is the name of the Go type of [Codec.MetricsBackend].
+	MetricsBackend string `json:",omitzero"`
+	RollUpMetrics  bool   `json:",omitzero"`
+	// Parent is the configuration of the codec that c was created from
+	// by [Codec.NewChild], if any, through which unset ratios are resolved.
+	Parent *ConfigSnapshot `json:",omitzero"`
+}
+
+// CallRatioConfig is the call modes and ratio for marshal or unmarshal
+// (see [Codec.SetMarshalCallRatio] and [Codec.SetUnmarshalCallRatio]).
+type CallRatioConfig struct {
+	Mode1, Mode2 string
+	Ratio        float64
+}
+
+// Config returns a snapshot of the effective configuration of c.
+func (c *Codec) Config() ConfigSnapshot {
+	cfg := ConfigSnapshot{
+		GoValueCaptureRatio:      c.GoValueCaptureRatio(),
+		AutoDetectOptions:        c.AutoDetectOptions,
+		AutoDetectParallelism:    c.autoDetectParallelism(),
+		MaxDetectionsPerMinute:   c.MaxDetectionsPerMinute,
+		MaxDetectionsPerType:     c.MaxDetectionsPerType,
+		AutoApplyDetectedOptions: c.AutoApplyDetectedOptions,
+		CopyDifferenceValues:     c.CopyDifferenceValues,
+		MaxReportsPerDifference:  c.MaxReportsPerDifference,
+		ReportSampleRate:         c.ReportSampleRate,
+		AsyncReportQueueSize:     c.AsyncReportQueueSize,
+		DiffGoValues:             c.DiffGoValues,
+		VerifyCrossDecode:        c.VerifyCrossDecode,
+		VerifyRoundTrip:          c.VerifyRoundTrip,
+		VerifyInterop:            c.VerifyInterop,
+		VerifyStandardV1:         c.VerifyStandardV1,
+		TranslateV2Errors:        c.TranslateV2Errors,
+		StreamMarshalComparison:  c.StreamMarshalComparison,
+		HashGoValuesAboveSize:    c.HashGoValuesAboveSize,
+		IgnoreGoValuesOnErrors:   c.IgnoreGoValuesOnErrors,
+		CallerSkipPrefixes:       c.CallerSkipPrefixes,
+		TrackCallerDiffRates:     c.TrackCallerDiffRates,
+		RollUpMetrics:            c.RollUpMetrics,
+	}
+	mode1, mode2, ratio := c.MarshalCallRatio()
+	cfg.MarshalCallRatio = CallRatioConfig{mode1.String(), mode2.String(), ratio}
+	mode1, mode2, ratio = c.UnmarshalCallRatio()
+	cfg.UnmarshalCallRatio = CallRatioConfig{mode1.String(), mode2.String(), ratio}
+	cfg.DefaultOptions = slices.Collect(optionNamesOf(c.DefaultOptions, true))
+	cfg.DifferenceHandlers = len(c.differenceHandlers())
+	if c.V1Implementation != AutoSelectV1 {
+		cfg.V1Implementation = c.V1Implementation.String()
+	}
+	if mode, ok := forcedCallMode(); ok {
+		cfg.ForcedCallMode = mode.String()
+	}
+	for _, p := range optionProbes()[len(builtinOptions):] {
+		cfg.OptionProbes = append(cfg.OptionProbes, p.name)
+	}
+	for _, s := range c.ReportSeverities {
+		cfg.ReportSeverities = append(cfg.ReportSeverities, s.String())
+	}
+	c.registeredOptions.Range(func(t, opts any) bool {
+		if cfg.TypeOptions == nil {
+			cfg.TypeOptions = make(map[string][]string)
+		}
+		cfg.TypeOptions[typeString(t.(reflect.Type))] = slices.Collect(optionNamesOf(opts.(jsonv2.Options), true))
+		return true
+	})
+	if c.MetricsBackend != nil {
+		cfg.MetricsBackend = typeString(reflect.TypeOf(c.MetricsBackend))
+	}
+	if c.parent != nil {
+		p := c.parent.Config()
+		cfg.Parent = &p
+	}
+	if c.KnownDifferences != nil {
+		n := c.KnownDifferences.Len()
+		cfg.KnownDifferences = &n
+	}
+	for _, t := range c.Transformers {
+		cfg.Transformers = append(cfg.Transformers, typeString(t.typ))
+	}
+	for _, hook := range []struct {
+		name string
+		set  bool
+	}{
+		{"ReportDifference", c.ReportDifference != nil},
+		{"ReportEmulationRegression", c.ReportEmulationRegression != nil},
+		{"EqualJSONValues", c.EqualJSONValues != nil},
+		{"EqualGoValues", c.EqualGoValues != nil},
+		{"EqualErrors", c.EqualErrors != nil},
+		{"ShouldCompareMarshal", c.ShouldCompareMarshal != nil},
+		{"ShouldCompareUnmarshal", c.ShouldCompareUnmarshal != nil},
+		{"CompareSizeProbability", c.CompareSizeProbability != nil},
+		{"CloneGoValue", c.CloneGoValue != nil},
+		{"ReportSummary", c.ReportSummary != nil},
+		{"SaveState", c.SaveState != nil},
+	} {
+		if hook.set {
+			cfg.Hooks = append(cfg.Hooks, hook.name)
+		}
+	}
+	return cfg
+}
+
+// ConfigJSON returns the complete effective configuration of c as JSON
+// (e.g., the call modes and ratios, the enabled verifications and limits,
+// and the names of any custom functions that are set),
+// such that the configuration of a misbehaving instance can be captured.
+// Function-valued fields are only reported by name since they
+// have no JSON representation.
+// The format is intended for human consumption and may change.
+func (c *Codec) ConfigJSON() jsontext.Value {
+	b, _ := jsonv2.Marshal(c.Config())
+	return b
+}
+
+// configWatcher is a function registered by [Codec.WatchConfig].
+type configWatcher struct {
+	f func(ConfigSnapshot)
+}
+
+// WatchConfig registers f to be called with a snapshot of the configuration
+// whenever the configuration of c is changed through its methods
+// or functions (e.g., [Codec.SetMarshalCallRatio], [RegisterTypeOptions],
+// and [Codec.AddDifferenceHandler]), regardless of what initiated the change.
+// This includes changes to the configuration of any ancestor of c
+// (see [Codec.NewChild]), which are reflected in the snapshot of c
+// (e.g., in the call ratios that c inherits).
+// It enables audit logging and coordination with other subsystems.
+// The function is called synchronously by the goroutine making the change
+// and must not change the configuration itself.
+// Changes to exported fields are not observed since they
+// must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+//
+// It returns a function to stop watching.
+func (c *Codec) WatchConfig(f func(ConfigSnapshot)) (stop func()) {
+	w := &configWatcher{f}
+	c.configWatchersMu.Lock()
+	defer c.configWatchersMu.Unlock()
+	if c.configWatchers == nil {
+		c.configWatchers = make(map[*configWatcher]struct{})
+	}
+	c.configWatchers[w] = struct{}{}
+	return func() {
+		c.configWatchersMu.Lock()
+		defer c.configWatchersMu.Unlock()
+		delete(c.configWatchers, w)
+	}
+}
+
+// notifyConfig calls every function registered by [Codec.WatchConfig]
+// on c and on every descendant of c.
+func (c *Codec) notifyConfig() {
+	c.configWatchersMu.Lock()
+	watchers := slices.Collect(maps.Keys(c.configWatchers))
+	c.configWatchersMu.Unlock()
+	if len(watchers) > 0 {
+		cfg := c.Config()
+		for _, w := range watchers {
+			w.f(cfg)
+		}
+	}
+	c.children.Range(func(k, _ any) bool {
+		if child := k.(weak.Pointer[Codec]).Value(); child != nil {
+			child.notifyConfig()
+		}
+		return true
+	})
+}
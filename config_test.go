@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestConfigJSON(t *testing.T) {
+	var codec Codec
+	want := `{"MarshalCallRatio":{"Mode1":"OnlyCallV1","Mode2":"OnlyCallV1","Ratio":0},` +
+		`"UnmarshalCallRatio":{"Mode1":"OnlyCallV1","Mode2":"OnlyCallV1","Ratio":0},` +
+		`"GoValueCaptureRatio":1,"AutoDetectOptions":false,"AutoDetectParallelism":0,` +
+		`"CopyDifferenceValues":false,"DiffGoValues":false,"VerifyCrossDecode":false,"VerifyRoundTrip":false,"VerifyInterop":false,"VerifyStandardV1":false,"TranslateV2Errors":false,` +
+		`"StreamMarshalComparison":false,"HashGoValuesAboveSize":0,"IgnoreGoValuesOnErrors":false}`
+	if got := string(codec.ConfigJSON()); got != want {
+		t.Errorf("ConfigJSON:\n\tgot  %s\n\twant %s", got, want)
+	}
+
+	codec = Codec{
+		DefaultOptions:     jsonv2.MatchCaseInsensitiveNames(true),
+		V1Implementation:   AlwaysUseStdV1,
+		AutoDetectOptions:  true,
+		KnownDifferences:   new(DifferenceSet),
+		Transformers:       []Transformer{Transform(func(t time.Time) time.Time { return t })},
+		CallerSkipPrefixes: []string{"example.com/"},
+		ReportDifference:   func(Difference) {},
+		EqualGoValues:      func(any, any) bool { return true },
+	}
+	codec.SetMarshalCallRatio(OnlyCallV1, CallBothButReturnV1, 0.5)
+	codec.SetUnmarshalCallMode(OnlyCallV2)
+	want = `{"MarshalCallRatio":{"Mode1":"OnlyCallV1","Mode2":"CallBothButReturnV1","Ratio":0.5},` +
+		`"UnmarshalCallRatio":{"Mode1":"OnlyCallV2","Mode2":"OnlyCallV2","Ratio":1},` +
+		`"GoValueCaptureRatio":1,"DefaultOptions":["jsonv2.MatchCaseInsensitiveNames"],"V1Implementation":"AlwaysUseStdV1","AutoDetectOptions":true,"AutoDetectParallelism":0,` +
+		`"CopyDifferenceValues":false,"DiffGoValues":false,"KnownDifferences":0,"VerifyCrossDecode":false,"VerifyRoundTrip":false,"VerifyInterop":false,"VerifyStandardV1":false,"TranslateV2Errors":false,` +
+		`"Transformers":["time.Time"],"StreamMarshalComparison":false,"HashGoValuesAboveSize":0,"IgnoreGoValuesOnErrors":false,` +
+		`"CallerSkipPrefixes":["example.com/"],"Hooks":["ReportDifference","EqualGoValues"]}`
+	if got := string(codec.ConfigJSON()); got != want {
+		t.Errorf("ConfigJSON:\n\tgot  %s\n\twant %s", got, want)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	var codec Codec
+	var got []ConfigSnapshot
+	stop := codec.WatchConfig(func(cfg ConfigSnapshot) { got = append(got, cfg) })
+	codec.SetMarshalCallRatio(OnlyCallV1, CallBothButReturnV1, 0.25)
+	codec.SetUnmarshalCallMode(CallBothButReturnV2)
+	codec.SetGoValueCaptureRatio(0.5)
+	RegisterTypeOptions[reverifyUser](&codec, jsonv2.MatchCaseInsensitiveNames(true))
+	h := codec.AddDifferenceHandler(func(Difference) {})
+	codec.RemoveDifferenceHandler(h)
+	stop()
+	codec.SetMarshalCallMode(OnlyCallV2)
+
+	if len(got) != 6 {
+		t.Fatalf("got %d notifications, want 6", len(got))
+	}
+	if want := (CallRatioConfig{"OnlyCallV1", "CallBothButReturnV1", 0.25}); got[0].MarshalCallRatio != want {
+		t.Errorf("MarshalCallRatio = %v, want %v", got[0].MarshalCallRatio, want)
+	}
+	if want := (CallRatioConfig{"CallBothButReturnV2", "CallBothButReturnV2", 1}); got[1].UnmarshalCallRatio != want {
+		t.Errorf("UnmarshalCallRatio = %v, want %v", got[1].UnmarshalCallRatio, want)
+	}
+	if got[2].GoValueCaptureRatio != 0.5 {
+		t.Errorf("GoValueCaptureRatio = %v, want 0.5", got[2].GoValueCaptureRatio)
+	}
+	if len(got[3].TypeOptions) != 1 {
+		t.Errorf("TypeOptions = %v, want options for one type", got[3].TypeOptions)
+	}
+	if got[4].DifferenceHandlers != 1 || got[5].DifferenceHandlers != 0 {
+		t.Errorf("DifferenceHandlers = %d, %d, want 1, 0", got[4].DifferenceHandlers, got[5].DifferenceHandlers)
+	}
+}
+
+func TestWatchConfigChild(t *testing.T) {
+	parent := new(Codec)
+	child := parent.NewChild()
+	grandchild := child.NewChild()
+	var got []ConfigSnapshot
+	grandchild.WatchConfig(func(cfg ConfigSnapshot) { got = append(got, cfg) })
+
+	parent.SetMarshalCallMode(CallBothButReturnV1)
+	if len(got) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(got))
+	}
+	if want := (CallRatioConfig{"CallBothButReturnV1", "CallBothButReturnV1", 1}); got[0].MarshalCallRatio != want {
+		t.Errorf("MarshalCallRatio = %v, want %v", got[0].MarshalCallRatio, want)
+	}
+	child.SetUnmarshalCallMode(CallBothButReturnV2)
+	if len(got) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(got))
+	}
+	if want := (CallRatioConfig{"CallBothButReturnV2", "CallBothButReturnV2", 1}); got[1].UnmarshalCallRatio != want {
+		t.Errorf("UnmarshalCallRatio = %v, want %v", got[1].UnmarshalCallRatio, want)
+	}
+}
+
+func TestConfigInheritance(t *testing.T) {
+	parent := &Codec{MetricsBackend: &testBackend{}}
+	parent.SetMarshalCallMode(CallBothButReturnV1)
+	RegisterTypeOptions[reverifyUser](parent, jsonv2.MatchCaseInsensitiveNames(true))
+	child := parent.NewChild()
+	child.RollUpMetrics = true
+
+	got := child.Config()
+	if got.Parent == nil {
+		t.Fatalf("Parent = nil, want the configuration of the parent")
+	}
+	if want := "*" + typeString(reflect.TypeFor[testBackend]()); got.Parent.MetricsBackend != want {
+		t.Errorf("Parent.MetricsBackend = %q, want %q", got.Parent.MetricsBackend, want)
+	}
+	want := map[string][]string{typeString(reflect.TypeFor[reverifyUser]()): {"jsonv2.MatchCaseInsensitiveNames"}}
+	if !reflect.DeepEqual(got.Parent.TypeOptions, want) {
+		t.Errorf("Parent.TypeOptions = %v, want %v", got.Parent.TypeOptions, want)
+	}
+	if got.MetricsBackend != "" || !got.RollUpMetrics {
+		t.Errorf("MetricsBackend, RollUpMetrics = %q, %v, want %q, true", got.MetricsBackend, got.RollUpMetrics, "")
+	}
+	if want := (CallRatioConfig{"CallBothButReturnV1", "CallBothButReturnV1", 1}); got.MarshalCallRatio != want {
+		t.Errorf("MarshalCallRatio = %v, want %v", got.MarshalCallRatio, want)
+	}
+}
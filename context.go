@@ -0,0 +1,58 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+type codecContextKey struct{}
+
+// NewContext returns a copy of ctx that carries the codec c.
+func NewContext(ctx context.Context, c *Codec) context.Context {
+	return context.WithValue(ctx, codecContextKey{}, c)
+}
+
+// FromContext returns the codec carried by ctx.
+// If ctx does not carry a codec, it returns &[GlobalCodec].
+func FromContext(ctx context.Context) *Codec {
+	if c, ok := ctx.Value(codecContextKey{}).(*Codec); ok && c != nil {
+		return c
+	}
+	return &GlobalCodec
+}
+
+type callerLabelContextKey struct{}
+
+// WithCallerLabel returns a copy of ctx that carries a caller label,
+// which [Codec.MarshalContext] and [Codec.UnmarshalContext] use
+// instead of the source location of the caller to attribute differences
+// (see [Codec.WithCallerLabel]). It panics if the label is empty.
+func WithCallerLabel(ctx context.Context, label string) context.Context {
+	if label == "" {
+		panic("empty caller label")
+	}
+	return context.WithValue(ctx, callerLabelContextKey{}, label)
+}
+
+// callerLabel returns the caller label carried by ctx, if any.
+func callerLabel(ctx context.Context) string {
+	label, _ := ctx.Value(callerLabelContextKey{}).(string)
+	return label
+}
+
+// contextLabels returns the profiler labels carried by ctx, if any.
+func contextLabels(ctx context.Context) map[string]string {
+	var labels map[string]string
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
+		return true
+	})
+	return labels
+}
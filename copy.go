@@ -0,0 +1,119 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// clone returns a copy of d such that none of the JSON or Go values
+// alias memory that is still referenced by the marshal or unmarshal call.
+// See [Codec.CopyDifferenceValues].
+func (d Difference) clone() Difference {
+	d.JSONValue = bytes.Clone(d.JSONValue)
+	d.JSONValueV1 = bytes.Clone(d.JSONValueV1)
+	d.JSONValueV2 = bytes.Clone(d.JSONValueV2)
+	d.GoValue = deepCopy(d.GoValue)
+	d.GoValueV1 = deepCopy(d.GoValueV1)
+	d.GoValueV2 = deepCopy(d.GoValueV2)
+	d.FieldDifferences = slices.Clone(d.FieldDifferences)
+	d.TagSuggestions = slices.Clone(d.TagSuggestions)
+	d.OptionCategories = maps.Clone(d.OptionCategories)
+	d.OptionScopes = maps.Clone(d.OptionScopes)
+	d.OptionLocations = maps.Clone(d.OptionLocations)
+	return d
+}
+
+// deepCopy returns a deep copy of v.
+//
+// Unexported struct fields cannot be modified through reflection,
+// so they are shallow copied and may still alias the original value.
+// Channels, functions, and unsafe pointers are also shallow copied.
+func deepCopy(v any) any {
+	if v == nil {
+		return nil
+	}
+	c := copier{seen: make(map[reflect.Type]map[uintptr]reflect.Value)}
+	return c.copy(reflect.ValueOf(v)).Interface()
+}
+
+type copier struct {
+	// seen maps previously copied pointers to their copies,
+	// which preserves cycles and shared references in the copy.
+	seen map[reflect.Type]map[uintptr]reflect.Value
+
+	// transform optionally replaces a value instead of copying it
+	// (see [transformGoValue]).
+	transform func(reflect.Value) (reflect.Value, bool)
+}
+
+func (c *copier) copy(src reflect.Value) reflect.Value {
+	if c.transform != nil && src.IsValid() {
+		if dst, ok := c.transform(src); ok {
+			return dst
+		}
+	}
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return src
+		}
+		if dst, ok := c.seen[src.Type()][src.Pointer()]; ok {
+			return dst
+		}
+		dst := reflect.New(src.Type().Elem())
+		if c.seen[src.Type()] == nil {
+			c.seen[src.Type()] = make(map[uintptr]reflect.Value)
+		}
+		c.seen[src.Type()][src.Pointer()] = dst
+		dst.Elem().Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := range src.Len() {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		for iter := src.MapRange(); iter.Next(); {
+			dst.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+		for i := range src.Len() {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(src) // shallow copy all fields, including unexported ones
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(c.copy(src.Field(i)))
+			}
+		}
+		return dst
+	default:
+		return src
+	}
+}
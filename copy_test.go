@@ -0,0 +1,62 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	type node struct {
+		Name     string
+		Children []*node
+		Attrs    map[string]any
+		Parent   *node
+		Array    [2][]int
+	}
+	root := &node{Name: "root", Attrs: map[string]any{"k": []any{1.0}}}
+	child := &node{Name: "child", Parent: root, Array: [2][]int{{1}, {2}}}
+	root.Children = []*node{child, child}
+
+	got := deepCopy(root).(*node)
+	if !reflect.DeepEqual(got, root) {
+		t.Fatalf("deepCopy is not equal to the original")
+	}
+	if got == root || got.Children[0] == child || &got.Children[0] == &root.Children[0] {
+		t.Errorf("deepCopy aliases the original pointers or slices")
+	}
+	if got.Children[0] != got.Children[1] || got.Children[0].Parent != got {
+		t.Errorf("deepCopy did not preserve shared references and cycles")
+	}
+
+	// Mutating the original must not affect the copy.
+	root.Attrs["k"].([]any)[0] = 2.0
+	child.Array[0][0] = 5
+	if got.Attrs["k"].([]any)[0] != 1.0 || got.Children[0].Array[0][0] != 1 {
+		t.Errorf("deepCopy aliases the original maps or arrays")
+	}
+}
+
+func TestCopyDifferenceValues(t *testing.T) {
+	var got Difference
+	c := Codec{
+		ReportDifference:     func(d Difference) { got = d },
+		CopyDifferenceValues: true,
+	}
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+	in := []byte(`{"FIRSTNAME":"John"}`)
+	out := new(struct{ FirstName string })
+	c.Unmarshal(in, out)
+
+	in[2] = 'X'
+	out.FirstName = "Jane"
+	if got.JSONValue[2] != 'F' {
+		t.Errorf("Difference.JSONValue aliases the input buffer")
+	}
+	if v := got.GoValueV1.(*struct{ FirstName string }); v == out || v.FirstName != "John" {
+		t.Errorf("Difference.GoValueV1 aliases the output value")
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// corpusEntry is a difference recorded as JSON by [Difference.MarshalJSON].
+// Since that representation is not reversible,
+// Go types, errors, and options are only available by name.
+type corpusEntry struct {
+	ID            string            `json:",omitzero"`
+	Caller        string            `json:",omitzero"`
+	Labels        map[string]string `json:",omitzero"`
+	Func          string            `json:",omitzero"`
+	Severity      Severity          `json:",omitzero"`
+	Check         string            `json:",omitzero"`
+	GoType        string            `json:",omitzero"`
+	JSONValue     jsontext.Value    `json:",omitzero"`
+	JSONValueV1   jsontext.Value    `json:",omitzero"`
+	JSONValueV2   jsontext.Value    `json:",omitzero"`
+	GoValuePath   string            `json:",omitzero"`
+	GoValueDiff   string            `json:",omitzero"`
+	InputOffset   int64             `json:",omitzero"`
+	ErrorV1       string            `json:",omitzero"`
+	ErrorV2       string            `json:",omitzero"`
+	CallerOptions []string          `json:",omitzero"`
+	OptionsV1     []string          `json:",omitzero"`
+	OptionsV2     []string          `json:",omitzero"`
+	StandardV1    bool              `json:",omitzero"`
+	Options       []string          `json:",omitzero"`
+
+	OptionsIncomplete bool `json:",omitzero"`
+
+	OptionCategories map[string]OptionCategory `json:",omitzero"`
+	OptionScopes     map[string]OptionScope    `json:",omitzero"`
+	OptionLocations  map[string]string         `json:",omitzero"`
+
+	CausingOptions []string `json:",omitzero"`
+
+	DetectionFailed       bool   `json:",omitzero"`
+	DetectionFailedReason string `json:",omitzero"`
+
+	SuggestedCall string `json:",omitzero"`
+
+	TagSuggestions []TagSuggestion `json:",omitzero"`
+
+	FieldDifferences []FieldDifference `json:",omitzero"`
+
+	JSONValuePointer jsontext.Pointer `json:",omitzero"`
+	JSONValueOffset  int64            `json:",omitzero"`
+
+	// JSONValueBase64, JSONValueV1Base64, and JSONValueV2Base64 hold
+	// the exact payloads recorded by [Difference.MarshalJSONLossless].
+	JSONValueBase64   []byte `json:",omitzero"`
+	JSONValueV1Base64 []byte `json:",omitzero"`
+	JSONValueV2Base64 []byte `json:",omitzero"`
+}
+
+// corpusOptions are the options used to unmarshal a [corpusEntry],
+// which permit the invalid JSON that may be within recorded payloads.
+var corpusOptions = sync.OnceValue(func() jsonv2.Options {
+	return jsonv2.JoinOptions(jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+})
+
+// difference reconstructs the recorded difference,
+// where the Go type is resolved by name from typesByName.
+func (e corpusEntry) difference(typesByName map[string]reflect.Type) Difference {
+	d := Difference{
+		ID:            e.ID,
+		Caller:        e.Caller,
+		Labels:        e.Labels,
+		Func:          e.Func,
+		Severity:      e.Severity,
+		Check:         e.Check,
+		GoType:        typesByName[e.GoType],
+		JSONValue:     e.JSONValue,
+		JSONValueV1:   e.JSONValueV1,
+		JSONValueV2:   e.JSONValueV2,
+		GoValuePath:   e.GoValuePath,
+		GoValueDiff:   e.GoValueDiff,
+		InputOffset:   e.InputOffset,
+		CallerOptions: parseOptionNames(e.CallerOptions),
+		OptionsV1:     parseOptionNames(e.OptionsV1),
+		OptionsV2:     parseOptionNames(e.OptionsV2),
+		StandardV1:    e.StandardV1,
+		Options:       parseOptionNames(e.Options),
+
+		OptionsIncomplete: e.OptionsIncomplete,
+
+		OptionCategories: e.OptionCategories,
+		OptionScopes:     e.OptionScopes,
+		OptionLocations:  e.OptionLocations,
+
+		CausingOptions: parseOptionNames(e.CausingOptions),
+
+		DetectionFailed:       e.DetectionFailed,
+		DetectionFailedReason: e.DetectionFailedReason,
+
+		SuggestedCall: e.SuggestedCall,
+
+		TagSuggestions: e.TagSuggestions,
+
+		FieldDifferences: e.FieldDifferences,
+
+		JSONValuePointer: e.JSONValuePointer,
+		JSONValueOffset:  e.JSONValueOffset,
+	}
+	for _, p := range []struct {
+		val *jsontext.Value
+		b64 []byte
+	}{
+		{&d.JSONValue, e.JSONValueBase64},
+		{&d.JSONValueV1, e.JSONValueV1Base64},
+		{&d.JSONValueV2, e.JSONValueV2Base64},
+	} {
+		if p.b64 != nil {
+			*p.val = p.b64
+		}
+	}
+	if e.ErrorV1 != "" {
+		d.ErrorV1 = errors.New(e.ErrorV1)
+	}
+	if e.ErrorV2 != "" {
+		d.ErrorV2 = errors.New(e.ErrorV2)
+	}
+	return d
+}
+
+// readCorpus reads all differences within a corpus directory,
+// where each regular file (recursively) contains a sequence of differences
+// encoded as JSON by [Difference.MarshalJSON]
+// or [Difference.MarshalJSONLossless] (e.g., JSON Lines).
+// Entries are returned in lexical order of file name, and
+// then in the order they appear in each file.
+func readCorpus(dir string) ([]corpusEntry, error) {
+	var entries []corpusEntry
+	err := filepath.WalkDir(dir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil || !de.Type().IsRegular() {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		opts := corpusOptions()
+		dec := jsontext.NewDecoder(f, opts)
+		for {
+			val, err := dec.ReadValue()
+			if err == io.EOF {
+				return nil
+			}
+			var e corpusEntry
+			if err == nil {
+				err = jsonv2.Unmarshal(val, &e, opts)
+			}
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", path, dec.InputOffset(), err)
+			}
+			entries = append(entries, e)
+		}
+	})
+	return entries, err
+}
+
+// ReadCorpus reads all differences within a corpus directory,
+// where each file (recursively) contains a sequence of differences
+// encoded as JSON by [Difference.MarshalJSON]
+// or [Difference.MarshalJSONLossless] (e.g., JSON Lines).
+// Differences are returned in lexical order of file name, and
+// then in the order they appear in each file.
+//
+// Since the JSON representation is not reversible, the differences
+// are reconstructed on a best-effort basis:
+//   - [Difference.GoType] is only populated if the recorded type name
+//     matches one of the specified types or a pointer to one of them.
+//   - [Difference.ErrorV1] and [Difference.ErrorV2] only
+//     preserve the error message.
+//   - [Difference.GoValue], [Difference.GoValueV1], and [Difference.GoValueV2]
+//     are never populated since they are not recorded.
+func ReadCorpus(dir string, types ...reflect.Type) ([]Difference, error) {
+	entries, err := readCorpus(dir)
+	if err != nil {
+		return nil, err
+	}
+	typesByName := make(map[string]reflect.Type)
+	for _, t := range types {
+		typesByName[typeString(t)] = t
+		typesByName[typeString(reflect.PointerTo(t))] = reflect.PointerTo(t)
+	}
+	ds := make([]Difference, len(entries))
+	for i, e := range entries {
+		ds[i] = e.difference(typesByName)
+	}
+	return ds, nil
+}
+
+// ParseOptions is the inverse of [Difference.OptionNames] and
+// [Difference.CallerOptionNames], which reconstructs the options
+// from their names (e.g., "jsonv2.FormatNilSliceAsNull" or
+// "jsonv2.Deterministic(false)"), such that options detected in production
+// can be reapplied at call sites or specified in configuration files.
+// Names of options registered with [RegisterOptionProbe] are also supported.
+// It reports an error for any unknown name and returns nil if there are no names.
+func ParseOptions(names ...string) (jsonv2.Options, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	opts := make([]jsonv2.Options, 0, len(names))
+	for _, name := range names {
+		opt, ok := parseOptionName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown option name %q", name)
+		}
+		opts = append(opts, opt)
+	}
+	return jsonv2.JoinOptions(opts...), nil
+}
+
+// parseOptionNames is like [ParseOptions], but ignores unknown names.
+func parseOptionNames(names []string) jsonv2.Options {
+	if len(names) == 0 {
+		return nil
+	}
+	var opts []jsonv2.Options
+	for _, name := range names {
+		if opt, ok := parseOptionName(name); ok {
+			opts = append(opts, opt)
+		}
+	}
+	return jsonv2.JoinOptions(opts...)
+}
+
+// parseOptionName parses a single option name
+// as formatted by [optionNamesOf].
+func parseOptionName(name string) (jsonv2.Options, bool) {
+	name, disabled := strings.CutSuffix(name, "(false)")
+	if option, ok := lookupOption(name); ok {
+		return option(!disabled), true
+	}
+	switch name {
+	case "jsontext.Multiline":
+		return jsontext.Multiline(!disabled), true
+	case "jsontext.SpaceAfterColon":
+		return jsontext.SpaceAfterColon(!disabled), true
+	case "jsontext.SpaceAfterComma":
+		return jsontext.SpaceAfterComma(!disabled), true
+	case "jsonv2.RejectUnknownMembers":
+		return jsonv2.RejectUnknownMembers(!disabled), true
+	case "jsonv1.Decoder.UseNumber":
+		return useNumber(), !disabled
+	}
+	if arg, ok := strings.CutPrefix(name, "jsontext.WithIndent("); ok && !disabled {
+		if indent, err := strconv.Unquote(strings.TrimSuffix(arg, ")")); err == nil && strings.Trim(indent, " \t") == "" {
+			return jsontext.WithIndent(indent), true
+		}
+	}
+	return nil, false
+}
+
+// goTypeExpr converts a type name formatted by [typeString]
+// (e.g., "map[string]*example.com/pkg.User") into a Go type expression
+// (e.g., "map[string]*pkg.User"), recording any packages to import.
+// The package name is assumed to be the last element of the package path.
+func goTypeExpr(name string, imports map[string]bool) (string, bool) {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, ok := goTypeExpr(name[len("*"):], imports)
+		return "*" + elem, ok
+	case strings.HasPrefix(name, "[]"):
+		elem, ok := goTypeExpr(name[len("[]"):], imports)
+		return "[]" + elem, ok
+	case strings.HasPrefix(name, "["):
+		n, elem, ok := strings.Cut(name[len("["):], "]")
+		if _, err := strconv.ParseUint(n, 10, 0); err != nil || !ok {
+			return "", false
+		}
+		elem, ok = goTypeExpr(elem, imports)
+		return "[" + n + "]" + elem, ok
+	case strings.HasPrefix(name, "map["):
+		// Find the bracket that terminates the key type.
+		depth := 0
+		for i, r := range name[len("map"):] {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			if depth == 0 {
+				i += len("map")
+				key, ok1 := goTypeExpr(name[len("map["):i], imports)
+				val, ok2 := goTypeExpr(name[i+len("]"):], imports)
+				return "map[" + key + "]" + val, ok1 && ok2
+			}
+		}
+		return "", false
+	case name == "interface {}":
+		return "any", true
+	case strings.ContainsAny(name, "[]{}() "):
+		return "", false // generic, struct, function, or other unnamed types
+	}
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return name, true // predeclared type
+	}
+	pkgPath, typeName := name[:i], name[i+len("."):]
+	if pkgPath == "main" {
+		return "", false // cannot import the main package
+	}
+	imports[pkgPath] = true
+	return pkgPath[strings.LastIndexByte(pkgPath, '/')+1:] + "." + typeName, true
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+type genericType[T any] struct{ V T }
+
+func TestGoTypeExpr(t *testing.T) {
+	for _, tt := range []struct {
+		in          reflect.Type
+		want        string
+		wantImports []string
+		wantOk      bool
+	}{
+		{in: reflect.TypeFor[int](), want: "int", wantOk: true},
+		{in: reflect.TypeFor[any](), want: "any", wantOk: true},
+		{in: reflect.TypeFor[*CallMode](), want: "*jsonsplit.CallMode", wantImports: []string{"github.com/go-json-experiment/jsonsplit"}, wantOk: true},
+		{in: reflect.TypeFor[[]reflect.Value](), want: "[]reflect.Value", wantImports: []string{"reflect"}, wantOk: true},
+		{in: reflect.TypeFor[[4]byte](), want: "[4]uint8", wantOk: true},
+		{in: reflect.TypeFor[map[[2]int]map[string]*int](), want: "map[[2]int]map[string]*int", wantOk: true},
+		{in: reflect.TypeFor[struct{ A int }](), wantOk: false},
+		{in: reflect.TypeFor[*func()](), wantOk: false},
+		{in: reflect.TypeFor[genericType[int]](), wantOk: false},
+	} {
+		imports := make(map[string]bool)
+		got, ok := goTypeExpr(typeString(tt.in), imports)
+		if ok != tt.wantOk || (ok && got != tt.want) {
+			t.Errorf("goTypeExpr(%q) = (%q, %v), want (%q, %v)", typeString(tt.in), got, ok, tt.want, tt.wantOk)
+		}
+		if ok {
+			var gotImports []string
+			for p := range imports {
+				gotImports = append(gotImports, p)
+			}
+			if d := cmp.Diff(gotImports, tt.wantImports); d != "" {
+				t.Errorf("goTypeExpr(%q) imports mismatch (-got +want):\n%s", typeString(tt.in), d)
+			}
+		}
+	}
+}
+
+func TestReadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	var ds []Difference
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { ds = append(ds, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(struct{ Name string }))
+	if len(ds) != 2 {
+		t.Fatalf("got %d differences, want 2", len(ds))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.jsonl"), []byte(ds[0].String()+"\n"+ds[1].String()+"\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.json"), []byte(ds[0].String()), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readCorpus(dir)
+	if err != nil {
+		t.Fatalf("readCorpus error: %v", err)
+	}
+	marshal := corpusEntry{
+		ID:          ds[0].ID,
+		Caller:      ds[0].Caller,
+		OptionsV1:   slices.Collect(optionNamesOf(ds[0].OptionsV1, true)),
+		OptionsV2:   slices.Collect(optionNamesOf(ds[0].OptionsV2, true)),
+		Func:        "Marshal",
+		GoType:      "map[string][]int",
+		JSONValueV1: []byte(`{"k":null}`),
+		JSONValueV2: []byte(`{"k":[]}`),
+		Options:     []string{"jsonv2.FormatNilSliceAsNull"},
+
+		OptionCategories: map[string]OptionCategory{"jsonv2.FormatNilSliceAsNull": OptionCategorySemantic},
+		OptionScopes:     map[string]OptionScope{"jsonv2.FormatNilSliceAsNull": OptionScopeCall},
+		OptionLocations:  map[string]string{"jsonv2.FormatNilSliceAsNull": "/k"},
+
+		SuggestedCall: "jsonsplit.Marshal(v, jsonv2.FormatNilSliceAsNull(true))",
+
+		JSONValuePointer: "/k",
+		JSONValueOffset:  5,
+	}
+	unmarshal := corpusEntry{
+		ID:          ds[1].ID,
+		Caller:      ds[1].Caller,
+		OptionsV1:   slices.Collect(optionNamesOf(ds[1].OptionsV1, true)),
+		OptionsV2:   slices.Collect(optionNamesOf(ds[1].OptionsV2, true)),
+		Func:        "Unmarshal",
+		GoType:      "*struct { Name string }",
+		JSONValue:   []byte(`{"NAME":"x"}`),
+		GoValuePath: ".Name",
+		Options:     []string{"jsonv2.MatchCaseInsensitiveNames"},
+
+		OptionCategories: map[string]OptionCategory{"jsonv2.MatchCaseInsensitiveNames": OptionCategorySemantic},
+		OptionScopes:     map[string]OptionScope{"jsonv2.MatchCaseInsensitiveNames": OptionScopeTag},
+		OptionLocations:  map[string]string{"jsonv2.MatchCaseInsensitiveNames": ".Name"},
+
+		SuggestedCall:  "jsonsplit.Unmarshal(b, v, jsonv2.MatchCaseInsensitiveNames(true))",
+		TagSuggestions: []TagSuggestion{{Type: "struct { Name string }", Field: "Name", Tag: `json:",case:ignore"`}},
+
+		FieldDifferences: []FieldDifference{{Field: "Name", ValueV1: `"x"`, ValueV2: `""`}},
+	}
+	want := []corpusEntry{marshal, unmarshal, marshal}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("readCorpus mismatch (-got +want):\n%s", d)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "c.json"), []byte(`{"Func":`), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCorpus(dir); err == nil {
+		t.Errorf("readCorpus error = nil, want non-nil")
+	}
+}
+
+func TestDifferenceLossless(t *testing.T) {
+	want := Difference{
+		ID:          "01J2Z3Q4R5S6T7V8W9X0Y1Z2A3",
+		Caller:      "example.com/pkg.Function+12",
+		Func:        "Unmarshal",
+		JSONValue:   []byte("{ \"k\":\"\xff\", \"k\":1"),
+		JSONValueV1: []byte(`{"k":"\u0041"}`),
+		JSONValueV2: []byte(`{"k":"A"}`),
+		GoValuePath: "T.K",
+		ErrorV2:     errors.New("duplicate name"),
+		CallerOptions: jsonv2.JoinOptions(
+			jsonv2.RejectUnknownMembers(true),
+			jsonv2.MatchCaseInsensitiveNames(true),
+		),
+		Options: jsonv2.JoinOptions(jsontext.AllowDuplicateNames(true)),
+	}
+	opts := []cmp.Option{
+		cmp.Comparer(func(x, y error) bool { return fmt.Sprint(x) == fmt.Sprint(y) }),
+		cmp.Transformer("OptionNames", func(opts jsonv2.Options) []string {
+			return slices.Collect(optionNamesOf(opts, true))
+		}),
+	}
+
+	b, err := want.MarshalJSONLossless()
+	if err != nil {
+		t.Fatalf("MarshalJSONLossless error: %v", err)
+	}
+	var got Difference
+	if err := got.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON error: %v", err)
+	}
+	if d := cmp.Diff(got, want, opts...); d != "" {
+		t.Errorf("lossless round-trip mismatch (-got +want):\n%s", d)
+	}
+
+	// The regular format only preserves payloads that are valid and formatted.
+	if err := got.UnmarshalJSON([]byte(want.String())); err != nil {
+		t.Fatalf("UnmarshalJSON error: %v", err)
+	}
+	want.JSONValue = []byte(`"INVALID: { \"k\":\"` + "\ufffd" + `\", \"k\":1"`)
+	want.JSONValueV1 = []byte(`{"k":"A"}`)
+	if d := cmp.Diff(got, want, opts...); d != "" {
+		t.Errorf("lossy round-trip mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestParseOptionNames(t *testing.T) {
+	var want []string
+	for info := range AllOptions() {
+		want = append(want, info.Name)
+	}
+	want = append(want, `jsontext.WithIndent("  ")`, "jsontext.SpaceAfterColon", "jsontext.SpaceAfterComma")
+	got := slices.Collect(optionNames(parseOptionNames(append(want, "bogus.Option"))))
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("optionNames(parseOptionNames) mismatch (-got +want):\n%s", d)
+	}
+
+	want = []string{"jsonv1.StringifyWithLegacySemantics", "jsonv2.Deterministic(false)", "jsontext.SpaceAfterComma(false)"}
+	got = slices.Collect(optionNamesOf(parseOptionNames(want), true))
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("optionNamesOf(parseOptionNames) mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	want := []string{"jsonv1.FormatDurationAsNano", "jsonv2.FormatNilSliceAsNull", "jsonv2.RejectUnknownMembers", "jsonv1.Decoder.UseNumber", `jsontext.WithIndent("\t")`}
+	opts, err := ParseOptions(want...)
+	if err != nil {
+		t.Fatalf("ParseOptions error: %v", err)
+	}
+	if d := cmp.Diff(slices.Collect(optionNames(opts)), want); d != "" {
+		t.Errorf("optionNames(ParseOptions) mismatch (-got +want):\n%s", d)
+	}
+
+	if opts, err := ParseOptions(); opts != nil || err != nil {
+		t.Errorf("ParseOptions() = (%v, %v), want (nil, nil)", opts, err)
+	}
+	for _, name := range []string{"bogus.Option", `jsontext.WithIndent("x")`, "jsonv1.Decoder.UseNumber(false)"} {
+		if _, err := ParseOptions("jsonv2.FormatNilSliceAsNull", name); err == nil {
+			t.Errorf("ParseOptions(%q) error = nil, want non-nil", name)
+		}
+	}
+}
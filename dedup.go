@@ -0,0 +1,146 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Fingerprint returns a hash of the distinguishing properties of d,
+// namely the operation (and check), the Go type, the severity,
+// and where the values diverged (see [Difference.JSONValuePointer]
+// and [Difference.GoValuePath]) with any array indexes elided.
+// The detected options are not hashed since they depend on
+// whether detection ran (e.g., due to sampling or the detection budget).
+// The hash is stable across program restarts and processes,
+// so it may be used to group the many identical differences
+// that are typically reported for a single Go type
+// (e.g., in downstream log pipelines).
+// It is the same fingerprint used by [Codec.KnownDifferences].
+func (d Difference) Fingerprint() uint64 {
+	h := fnv.New64a()
+	io.WriteString(h, d.Func)
+	if d.Check != "" {
+		io.WriteString(h, "/"+d.Check)
+	}
+	h.Write([]byte{0})
+	if d.GoType != nil {
+		io.WriteString(h, typeString(d.GoType))
+	}
+	h.Write([]byte{0})
+	io.WriteString(h, d.Severity.String())
+	h.Write([]byte{0})
+	io.WriteString(h, canonicalDivergence(d))
+	return h.Sum64()
+}
+
+// canonicalDivergence returns where the values of d diverged
+// with array indexes elided (e.g., "/users/*/aliases" or "User.Aliases[*]"),
+// such that differences in different elements of the same array
+// are considered the same difference.
+func canonicalDivergence(d Difference) string {
+	if d.JSONValuePointer != "" {
+		toks := strings.Split(string(d.JSONValuePointer), "/")
+		for i, tok := range toks {
+			if tok != "" && strings.Trim(tok, "0123456789") == "" {
+				toks[i] = "*"
+			}
+		}
+		return strings.Join(toks, "/")
+	}
+	var b strings.Builder
+	for path := d.GoValuePath; path != ""; {
+		i := strings.IndexByte(path, '[')
+		j := strings.IndexByte(path[i+1:], ']')
+		if i < 0 || j < 0 {
+			b.WriteString(path)
+			break
+		}
+		b.WriteString(path[:i+1])
+		if index := path[i+1 : i+1+j]; index != "" && strings.Trim(index, "0123456789") == "" {
+			b.WriteString("*") // an array or slice index
+		} else {
+			b.WriteString(index) // e.g., a quoted map key
+		}
+		path = path[i+1+j:]
+	}
+	return b.String()
+}
+
+// DifferenceSet is a set of difference fingerprints.
+// It is used by [Codec.KnownDifferences] to avoid repeatedly reporting
+// the same difference, even across program restarts.
+// The zero value is an empty set ready for use.
+// It is safe for concurrent use.
+type DifferenceSet struct {
+	mu  sync.Mutex
+	set map[uint64]struct{}
+}
+
+// insert inserts a fingerprint and reports whether it was newly inserted.
+func (s *DifferenceSet) insert(fp uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.set[fp]; ok {
+		return false
+	}
+	if s.set == nil {
+		s.set = make(map[uint64]struct{})
+	}
+	s.set[fp] = struct{}{}
+	return true
+}
+
+// Len reports the number of fingerprints in the set.
+func (s *DifferenceSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.set)
+}
+
+// WriteTo writes the set in a compact binary format,
+// where each fingerprint is encoded as 8 bytes in big-endian order.
+// It implements [io.WriterTo].
+func (s *DifferenceSet) WriteTo(w io.Writer) (int64, error) {
+	s.mu.Lock()
+	fps := slices.Sorted(maps.Keys(s.set))
+	s.mu.Unlock()
+	b := make([]byte, 0, 8*len(fps))
+	for _, fp := range fps {
+		b = binary.BigEndian.AppendUint64(b, fp)
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom reads fingerprints previously written by [DifferenceSet.WriteTo]
+// and merges them into the set.
+// It implements [io.ReaderFrom].
+func (s *DifferenceSet) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	var buf [8]byte
+	br := bufio.NewReader(r)
+	for {
+		m, err := io.ReadFull(br, buf[:])
+		n += int64(m)
+		switch {
+		case err == io.EOF:
+			return n, nil
+		case err == io.ErrUnexpectedEOF:
+			return n, errors.New("truncated difference fingerprint")
+		case err != nil:
+			return n, err
+		}
+		s.insert(binary.BigEndian.Uint64(buf[:]))
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestKnownDifferences(t *testing.T) {
+	var reports int
+	newCodec := func(known *DifferenceSet) *Codec {
+		c := &Codec{
+			AutoDetectOptions: true,
+			ReportDifference:  func(Difference) { reports++ },
+			KnownDifferences:  known,
+		}
+		c.SetMarshalCallMode(CallBothButReturnV1)
+		return c
+	}
+
+	var known DifferenceSet
+	c := newCodec(&known)
+	c.Marshal("<html>")
+	c.Marshal("<html>")
+	c.Marshal([]int(nil))
+	if reports != 2 {
+		t.Errorf("reports = %d, want 2", reports)
+	}
+	if n := c.NumDifferencesSuppressed.Value(); n != 1 {
+		t.Errorf("NumDifferencesSuppressed = %d, want 1", n)
+	}
+
+	// Persist and restore the set as if the program restarted.
+	var buf bytes.Buffer
+	if _, err := known.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo error: %v", err)
+	}
+	if buf.Len() != 8*known.Len() {
+		t.Errorf("WriteTo wrote %d bytes, want %d", buf.Len(), 8*known.Len())
+	}
+	var restored DifferenceSet
+	if _, err := restored.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom error: %v", err)
+	}
+	reports = 0
+	c = newCodec(&restored)
+	c.Marshal("<html>")
+	c.Marshal([]int(nil))
+	c.Marshal(map[string]int(nil))
+	if reports != 1 {
+		t.Errorf("reports after restart = %d, want 1", reports)
+	}
+
+	if _, err := new(DifferenceSet).ReadFrom(bytes.NewReader(make([]byte, 12))); err == nil {
+		t.Errorf("ReadFrom of truncated input succeeded, want error")
+	}
+}
+
+func TestMaxReportsPerDifference(t *testing.T) {
+	var reports int
+	c := &Codec{
+		ReportDifference:        func(Difference) { reports++ },
+		MaxReportsPerDifference: 2,
+	}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	for range 5 {
+		c.Marshal([]int(nil))
+	}
+	c.Marshal(map[string]int(nil))
+	if reports != 3 {
+		t.Errorf("reports = %d, want 3", reports)
+	}
+	if n := c.NumDifferencesRepeatSuppressed.Value(); n != 3 {
+		t.Errorf("NumDifferencesRepeatSuppressed = %d, want 3", n)
+	}
+}
+
+func TestReportSampleRate(t *testing.T) {
+	var reports int64
+	c := &Codec{
+		AutoDetectOptions: true,
+		ReportDifference:  func(Difference) { reports++ },
+		ReportSampleRate:  0.5,
+	}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	const n = 1000
+	for range n {
+		c.Marshal([]int(nil))
+	}
+	if got := c.NumMarshalDiffs.Value(); got != n {
+		t.Errorf("NumMarshalDiffs = %d, want %d", got, n)
+	}
+	if got := reports + c.NumDifferencesUnsampled.Value(); got != n {
+		t.Errorf("reports + NumDifferencesUnsampled = %d, want %d", got, n)
+	}
+	if reports < n/4 || reports > 3*n/4 {
+		t.Errorf("reports = %d, want approximately %d", reports, n/2)
+	}
+	if got := c.MarshalOptionHistogram.Get("jsonv2.FormatNilSliceAsNull"); got == nil || got.String() != fmt.Sprint(reports) {
+		t.Errorf("MarshalOptionHistogram[jsonv2.FormatNilSliceAsNull] = %v, want %d", got, reports)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	d1 := Difference{Func: "Marshal", GoType: reflect.TypeFor[[]int](), JSONValueV1: []byte("null"), JSONValuePointer: "/0/k", Options: jsonv2.FormatNilSliceAsNull(true)}
+	for _, d := range []Difference{
+		{Func: "Marshal", GoType: d1.GoType, JSONValueV1: []byte("[1]"), JSONValuePointer: "/0/k", Options: d1.Options},
+		{Func: "Marshal", GoType: d1.GoType, JSONValuePointer: "/12/k"},
+		{Func: "Marshal", GoType: d1.GoType, JSONValuePointer: "/0/k", Options: jsonv2.FormatNilMapAsNull(true)},
+	} {
+		if d.Fingerprint() != d1.Fingerprint() {
+			t.Errorf("Fingerprint of %v differs from that of %v", d, d1)
+		}
+	}
+	for _, d := range []Difference{
+		{Func: "Unmarshal", GoType: d1.GoType, JSONValuePointer: d1.JSONValuePointer},
+		{Func: "Marshal", Check: "Interop", GoType: d1.GoType, JSONValuePointer: d1.JSONValuePointer},
+		{Func: "Marshal", GoType: reflect.TypeFor[[]string](), JSONValuePointer: d1.JSONValuePointer},
+		{Func: "Marshal", GoType: d1.GoType, JSONValuePointer: d1.JSONValuePointer, Severity: SeverityFormattingOnly},
+		{Func: "Marshal", GoType: d1.GoType, JSONValuePointer: "/0/v"},
+	} {
+		if d.Fingerprint() == d1.Fingerprint() {
+			t.Errorf("Fingerprint of %v unexpectedly equals that of %v", d, d1)
+		}
+	}
+	// The fingerprint must be stable across program restarts.
+	if got, want := d1.Fingerprint(), uint64(0x124910ab1fdd2e3f); got != want {
+		t.Errorf("Fingerprint = %#x, want %#x", got, want)
+	}
+}
+
+func TestCanonicalDivergence(t *testing.T) {
+	for _, tt := range []struct {
+		d    Difference
+		want string
+	}{
+		{Difference{}, ""},
+		{Difference{JSONValuePointer: "/users/12/aliases/0"}, "/users/*/aliases/*"},
+		{Difference{JSONValuePointer: "/k1"}, "/k1"},
+		{Difference{GoValuePath: `User.Aliases[2]`}, "User.Aliases[*]"},
+		{Difference{GoValuePath: `map[string][]int["k"][0]`}, `map[string][]int["k"][*]`},
+	} {
+		if got := canonicalDivergence(tt.d); got != tt.want {
+			t.Errorf("canonicalDivergence(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
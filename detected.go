@@ -0,0 +1,140 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// detectedByType counts the options detected for each operation and Go type.
+type detectedByType struct {
+	mu sync.Mutex
+	m  map[opTypeKey]map[string]int64 // number of differences needing each option
+	n  map[opTypeKey]int64            // number of differences resolved
+}
+
+// recordDetectedOptions records the options that resolved a difference
+// in op (e.g., "Marshal") for the Go type t for
+// [Codec.DetectedOptionsByType] and [Codec.OptionConfidences].
+// Unresolved detections are not recorded since their options
+// do not explain the difference.
+func (c *Codec) recordDetectedOptions(op string, t reflect.Type, opts jsonv2.Options) {
+	if t == nil {
+		return
+	}
+	k := opTypeKey{op, t}
+	d := &c.detectedByType
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.n == nil {
+		d.n = make(map[opTypeKey]int64)
+	}
+	d.n[k]++
+	for name := range optionNames(opts) {
+		if d.m == nil {
+			d.m = make(map[opTypeKey]map[string]int64)
+		}
+		if d.m[k] == nil {
+			d.m[k] = make(map[string]int64)
+		}
+		d.m[k][name]++
+	}
+}
+
+// DetectedOptionsByType returns the sorted names of all options
+// ever detected by [Codec.AutoDetectOptions] to resolve differences
+// in either marshal or unmarshal, grouped by the Go type of the
+// top-level value. It allows reviewing which compatibility options
+// each type requires. Types without any detected options are omitted.
+func (c *Codec) DetectedOptionsByType() map[reflect.Type][]string {
+	d := &c.detectedByType
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := make(map[reflect.Type][]string, len(d.m))
+	for k, names := range d.m {
+		for name := range names {
+			if !slices.Contains(m[k.t], name) {
+				m[k.t] = append(m[k.t], name)
+			}
+		}
+	}
+	for t := range m {
+		slices.Sort(m[t])
+	}
+	return m
+}
+
+// DetectedOptionsExpVar returns an expvar of [Codec.DetectedOptionsByType],
+// which is a JSON object mapping the fully qualified name of each Go type
+// (e.g., "example.com/pkg.User") to the sorted names of its options.
+// It is intended for use with [expvar.Publish].
+func (c *Codec) DetectedOptionsExpVar() expvar.Var {
+	return stringVar(func() string {
+		m := make(map[string][]string)
+		for t, names := range c.DetectedOptionsByType() {
+			m[typeString(t)] = names
+		}
+		b, _ := jsonv2.Marshal(m, jsonv2.Deterministic(true))
+		return string(b)
+	})
+}
+
+// OptionConfidence is how consistently an option resolved
+// the differences in a Go type (see [Codec.OptionConfidences]).
+type OptionConfidence struct {
+	// Resolved is the number of differences that needed the option.
+	Resolved int64
+	// Total is the number of differences in the Go type
+	// that were resolved by [Codec.AutoDetectOptions].
+	Total int64
+	// Score is the confidence in [0, 1] that the option is needed
+	// for the Go type, which is the lower bound of the Wilson score interval
+	// (at 95% confidence) for the proportion of Resolved out of Total.
+	// Unlike the proportion itself, it grows with the number of observations
+	// such that 1 detection out of 1 scores about 0.21,
+	// while 100 detections out of 100 score about 0.96.
+	Score float64
+}
+
+// OptionConfidences returns the confidence in each option
+// ever detected by [Codec.AutoDetectOptions] to resolve differences
+// in op (either "Marshal" or "Unmarshal"), grouped by
+// the Go type of the top-level value and keyed by option name.
+// It allows a single noisy detection to be distinguished from
+// many consistent ones when deciding which options to apply.
+// Types without any detected options are omitted.
+func (c *Codec) OptionConfidences(op string) map[reflect.Type]map[string]OptionConfidence {
+	d := &c.detectedByType
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := make(map[reflect.Type]map[string]OptionConfidence)
+	for k, counts := range d.m {
+		if k.op != op {
+			continue
+		}
+		m[k.t] = make(map[string]OptionConfidence, len(counts))
+		for name, n := range counts {
+			m[k.t][name] = OptionConfidence{Resolved: n, Total: d.n[k], Score: wilsonLowerBound(n, d.n[k])}
+		}
+	}
+	return m
+}
+
+// wilsonLowerBound returns the lower bound of the Wilson score interval
+// at 95% confidence for k successes out of n trials.
+func wilsonLowerBound(k, n int64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	const z = 1.96
+	p, nf := float64(k)/float64(n), float64(n)
+	return (p + z*z/(2*nf) - z*math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf))) / (1 + z*z/nf)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectedOptionsByType(t *testing.T) {
+	type user struct {
+		Name    string
+		Aliases []string
+	}
+	codec := &Codec{AutoDetectOptions: true}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	codec.Marshal([]int(nil))
+	codec.Marshal(user{})
+	codec.Unmarshal([]byte(`{"name":"x"}`), new(user))
+	codec.Marshal(map[string]int{}) // no difference
+
+	got := codec.DetectedOptionsByType()
+	want := map[reflect.Type][]string{
+		reflect.TypeFor[[]int](): {"jsonv2.FormatNilSliceAsNull"},
+		reflect.TypeFor[user]():  {"jsonv2.FormatNilSliceAsNull"},
+		reflect.TypeFor[*user](): {"jsonv2.MatchCaseInsensitiveNames"},
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("DetectedOptionsByType mismatch (-got +want):\n%s", d)
+	}
+
+	gotVar := codec.DetectedOptionsExpVar().String()
+	wantVar := `{"*github.com/go-json-experiment/jsonsplit.user":["jsonv2.MatchCaseInsensitiveNames"],"[]int":["jsonv2.FormatNilSliceAsNull"],"github.com/go-json-experiment/jsonsplit.user":["jsonv2.FormatNilSliceAsNull"]}`
+	if gotVar != wantVar {
+		t.Errorf("DetectedOptionsExpVar:\ngot  %s\nwant %s", gotVar, wantVar)
+	}
+}
+
+func TestOptionConfidences(t *testing.T) {
+	type config struct {
+		S []int
+		M map[string]int
+	}
+	codec := &Codec{AutoDetectOptions: true}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(config{})
+	for range 3 {
+		codec.Marshal(config{S: []int{}})
+	}
+	codec.Marshal(map[string]int{}) // no difference
+
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Unmarshal([]byte(`{"s":[]}`), new(config))
+
+	approx := cmp.Comparer(func(x, y float64) bool { return math.Abs(x-y) < 0.01 })
+	got := codec.OptionConfidences("Marshal")
+	want := map[reflect.Type]map[string]OptionConfidence{
+		reflect.TypeFor[config](): {
+			"jsonv2.FormatNilMapAsNull":   {Resolved: 4, Total: 4, Score: 0.51},
+			"jsonv2.FormatNilSliceAsNull": {Resolved: 1, Total: 4, Score: 0.05},
+		},
+	}
+	if d := cmp.Diff(got, want, approx); d != "" {
+		t.Errorf("OptionConfidences(Marshal) mismatch (-got +want):\n%s", d)
+	}
+	got = codec.OptionConfidences("Unmarshal")
+	want = map[reflect.Type]map[string]OptionConfidence{
+		reflect.TypeFor[*config](): {
+			"jsonv2.MatchCaseInsensitiveNames": {Resolved: 1, Total: 1, Score: 0.21},
+		},
+	}
+	if d := cmp.Diff(got, want, approx); d != "" {
+		t.Errorf("OptionConfidences(Unmarshal) mismatch (-got +want):\n%s", d)
+	}
+
+	// Unresolved detections are not recorded.
+	var calls int
+	codec = &Codec{
+		AutoDetectOptions: true,
+		EqualJSONValues: func(x, y jsontext.Value) bool {
+			calls++
+			return calls == 2 // only the sanity check with the v1 options
+		},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(config{})
+	if got := codec.OptionConfidences("Marshal"); len(got) > 0 {
+		t.Errorf("OptionConfidences(Marshal) = %v, want empty for unresolved detections", got)
+	}
+
+	for _, tt := range []struct {
+		k, n int64
+		want float64
+	}{{0, 0, 0}, {1, 1, 0.21}, {100, 100, 0.96}, {50, 100, 0.40}} {
+		if got := wilsonLowerBound(tt.k, tt.n); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("wilsonLowerBound(%d, %d) = %.3f, want %.2f", tt.k, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// Evaluation is the result of replaying recorded differences
+// under a proposed set of options. See [Codec.Evaluate].
+type Evaluation struct {
+	// Resolved are the differences where v1 and v2 behave identically
+	// only when v2 is called with the proposed options.
+	Resolved []Difference
+	// Unresolved are the differences where v1 and v2 still behave differently
+	// even when v2 is called with the proposed options.
+	Unresolved []Difference
+	// Fixed are the differences where v1 and v2 already behave identically
+	// without the proposed options (see [ReverifyFixed]).
+	Fixed []Difference
+	// Unavailable are the differences that could not be replayed
+	// (see [ReverifyUnavailable]).
+	Unavailable []Difference
+}
+
+// Evaluate replays recorded differences (e.g., from [ReadCorpus])
+// with v2 called using the proposed options and reports which
+// differences the options would resolve.
+// This allows a remediation plan (e.g., adding options to call sites)
+// to be validated offline before changing production code.
+//
+// Differences are replayed in the same way as [Codec.Reverify],
+// where the proposed options are applied after [Difference.CallerOptions].
+// It does not update any metrics or report any differences.
+func (c *Codec) Evaluate(ds []Difference, opts ...jsonv2.Options) Evaluation {
+	var e Evaluation
+	for _, d := range ds {
+		o, arshalEqual := c.replay(d)
+		switch {
+		case arshalEqual == nil:
+			e.Unavailable = append(e.Unavailable, d)
+		case arshalEqual(o...):
+			e.Fixed = append(e.Fixed, d)
+		case arshalEqual(append(o, opts...)...):
+			e.Resolved = append(e.Resolved, d)
+		default:
+			e.Unresolved = append(e.Unresolved, d)
+		}
+	}
+	return e
+}
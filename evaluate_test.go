@@ -0,0 +1,37 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestEvaluate(t *testing.T) {
+	ds := []Difference{
+		{Func: "Marshal", GoValue: map[string][]int{"k": nil}},                                           // resolved
+		{Func: "Unmarshal", GoType: reflect.TypeFor[*reverifyUser](), JSONValue: []byte(`{"NAME":"x"}`)}, // unresolved
+		{Func: "Unmarshal", GoType: reflect.TypeFor[*reverifyUser](), JSONValue: []byte(`{}`)},           // fixed
+		{Func: "Unmarshal", JSONValue: []byte(`{}`)},                                                     // unavailable
+	}
+	e := (&Codec{}).Evaluate(ds, jsonv2.FormatNilSliceAsNull(true))
+	got := []int{len(e.Resolved), len(e.Unresolved), len(e.Fixed), len(e.Unavailable)}
+	if want := []int{1, 1, 1, 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Evaluate counts = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(e.Resolved[0], ds[0]) || !reflect.DeepEqual(e.Unresolved[0], ds[1]) {
+		t.Errorf("Evaluate = %+v, want differences partitioned in order", e)
+	}
+
+	// Differences resolved by the options originally passed by the caller
+	// are already fixed regardless of the proposed options.
+	d := Difference{Func: "Marshal", GoValue: map[string][]int{"k": nil},
+		CallerOptions: jsonv2.JoinOptions(jsonv2.FormatNilSliceAsNull(true))}
+	if e := (&Codec{}).Evaluate([]Difference{d}); len(e.Fixed) != 1 {
+		t.Errorf("Evaluate = %+v, want a fixed difference", e)
+	}
+}
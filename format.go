@@ -0,0 +1,206 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	jsonv1std "encoding/json"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+var (
+	validCallModeKeys      = newCallModeKeys("Valid")
+	compactCallModeKeys    = newCallModeKeys("Compact")
+	indentCallModeKeys     = newCallModeKeys("Indent")
+	htmlEscapeCallModeKeys = newCallModeKeys("HTMLEscape")
+)
+
+// Valid reports whether data is valid JSON using either [jsonv1std.Valid]
+// or [jsontext.Value.IsValid] depending on the mode specified in
+// [Codec.SetUnmarshalCallRatio]. If both are called,
+// it checks whether they agree (e.g., on the validity of
+// invalid UTF-8 or duplicate object member names, which v2 rejects).
+func (c *Codec) Valid(data []byte) bool {
+	out, _ := c.format("Valid", validCallModeKeys, c.unmarshalRatio().loadRandomMode(), data,
+		func() ([]byte, error) {
+			return strconv.AppendBool(nil, jsonv1std.Valid(data)), nil
+		},
+		func() ([]byte, error) {
+			return strconv.AppendBool(nil, jsontext.Value(data).IsValid()), nil
+		})
+	return string(out) == "true"
+}
+
+// Compact appends to dst the JSON-encoded src with insignificant space
+// characters elided using either [jsonv1std.Compact] or [jsontext.Value.Compact]
+// depending on the mode specified in [Codec.SetMarshalCallRatio].
+// If both are called, it checks whether the outputs are identical.
+func (c *Codec) Compact(dst *bytes.Buffer, src []byte) error {
+	out, err := c.format("Compact", compactCallModeKeys, c.marshalRatio().loadRandomMode(), src,
+		func() ([]byte, error) {
+			var buf bytes.Buffer
+			err := jsonv1std.Compact(&buf, src)
+			return buf.Bytes(), err
+		},
+		func() ([]byte, error) {
+			v := jsontext.Value(bytes.Clone(src))
+			if err := v.Compact(); err != nil {
+				return nil, err
+			}
+			return v, nil
+		})
+	dst.Write(out)
+	return err
+}
+
+// Indent appends to dst an indented form of the JSON-encoded src
+// using either [jsonv1std.Indent] or [jsontext.Value.Indent]
+// depending on the mode specified in [Codec.SetMarshalCallRatio].
+// If both are called, it checks whether the outputs are identical.
+//
+// Since v2 only supports a prefix and indent composed of spaces and tabs,
+// calls with any other characters validate src with [jsontext.Value.Compact]
+// and then indent it with [jsonv1std.Indent] for the v2 call.
+func (c *Codec) Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	supported := strings.Trim(prefix, " \t") == "" && strings.Trim(indent, " \t") == ""
+	out, err := c.format("Indent", indentCallModeKeys, c.marshalRatio().loadRandomMode(), src,
+		func() ([]byte, error) {
+			var buf bytes.Buffer
+			err := jsonv1std.Indent(&buf, src, prefix, indent)
+			return buf.Bytes(), err
+		},
+		func() ([]byte, error) {
+			v := jsontext.Value(bytes.Clone(src))
+			if !supported {
+				if err := v.Compact(); err != nil {
+					return nil, err
+				}
+				var buf bytes.Buffer
+				err := jsonv1std.Indent(&buf, v, prefix, indent)
+				return buf.Bytes(), err
+			}
+			if err := v.Indent(jsontext.WithIndentPrefix(prefix), jsontext.WithIndent(indent)); err != nil {
+				return nil, err
+			}
+			return v, nil
+		})
+	dst.Write(out)
+	return err
+}
+
+// HTMLEscape appends to dst the JSON-encoded src with characters
+// within strings escaped for safe embedding within HTML
+// using either [jsonv1std.HTMLEscape] or an escaping pass equivalent to
+// [jsontext.EscapeForHTML] and [jsontext.EscapeForJS] depending on
+// the mode specified in [Codec.SetMarshalCallRatio].
+// If both are called, it checks whether the outputs are identical.
+//
+// Both implementations preserve all other characters (including whitespace).
+// Unlike v1, the v2 implementation only escapes characters within JSON strings.
+func (c *Codec) HTMLEscape(dst *bytes.Buffer, src []byte) {
+	out, _ := c.format("HTMLEscape", htmlEscapeCallModeKeys, c.marshalRatio().loadRandomMode(), src,
+		func() ([]byte, error) {
+			var buf bytes.Buffer
+			jsonv1std.HTMLEscape(&buf, src)
+			return buf.Bytes(), nil
+		},
+		func() ([]byte, error) {
+			return appendHTMLEscape(nil, src), nil
+		})
+	dst.Write(out)
+}
+
+// appendHTMLEscape appends src to dst with the characters '<', '>', and '&'
+// and the code points U+2028 and U+2029 within JSON strings escaped
+// as with [jsontext.EscapeForHTML] and [jsontext.EscapeForJS],
+// preserving all other characters as is (including whitespace).
+func appendHTMLEscape(dst, src []byte) []byte {
+	const hex = "0123456789abcdef"
+	var inString, escaped bool
+	start := 0
+	for i := 0; i < len(src); i++ {
+		switch b := src[i]; {
+		case !inString:
+			inString = b == '"'
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+		case b == '"':
+			inString = false
+		case b == '<' || b == '>' || b == '&':
+			dst = append(dst, src[start:i]...)
+			dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			start = i + 1
+		case b == 0xe2 && i+2 < len(src) && src[i+1] == 0x80 && src[i+2]&^1 == 0xa8:
+			// U+2028 LINE SEPARATOR or U+2029 PARAGRAPH SEPARATOR
+			dst = append(dst, src[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[src[i+2]&0xf])
+			start = i + 3
+			i += 2
+		}
+	}
+	return append(dst, src[start:]...)
+}
+
+// format calls formatV1, formatV2, or both according to the mode
+// and reports any difference in the output or errors as a [Difference]
+// where Func is the name of the operation.
+func (c *Codec) format(op string, keys [maxCallMode]callModeKeys, mode CallMode, src []byte, formatV1, formatV2 func() ([]byte, error)) ([]byte, error) {
+	if mode == CallBothV1StdAndV1Emulated || (mode.callsBoth() && !c.sampleSize(len(src))) {
+		mode = mode.withoutComparison()
+	}
+	c.addKey(&c.CallModeCounters, keys[mode].calls, 1)
+	var out1, out2 []byte
+	var err1, err2 error
+	switch mode {
+	case OnlyCallV1:
+		return formatV1()
+	case OnlyCallV2:
+		return formatV2()
+	case CallV1ButUponErrorReturnV2:
+		if out1, err1 = formatV1(); err1 == nil {
+			return out1, nil
+		}
+		out2, err2 = formatV2()
+	case CallV2ButUponErrorReturnV1:
+		if out2, err2 = formatV2(); err2 == nil {
+			return out2, nil
+		}
+		out1, err1 = formatV1()
+	case CallBothButReturnV1, CallBothButReturnV2:
+		out1, err1 = formatV1()
+		out2, err2 = formatV2()
+	default:
+		panic("unknown mode")
+	}
+
+	if !bytes.Equal(out1, out2) || (err1 == nil) != (err2 == nil) {
+		c.addKey(&c.CallModeCounters, keys[mode].diffs, 1)
+		d := Difference{
+			Caller:      c.caller(),
+			Func:        op,
+			JSONValue:   src,
+			JSONValueV1: out1,
+			JSONValueV2: out2,
+			ErrorV1:     err1,
+			ErrorV2:     err2,
+		}
+		c.classifyDifference(&d)
+		if c.reportsDifferences() && c.sampleReport() {
+			c.reportDifference(d)
+		}
+	}
+	switch mode {
+	case CallBothButReturnV1, CallV2ButUponErrorReturnV1:
+		return out1, err1
+	default:
+		return out2, err2
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	var got []Difference
+	c := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	c.SetUnmarshalCallMode(CallBothButReturnV2)
+
+	tests := []struct {
+		name     string
+		call     func() (string, error)
+		want     string
+		wantDiff bool
+	}{{
+		name: "Valid",
+		call: func() (string, error) {
+			if c.Valid([]byte(`{"a":1}`)) {
+				return "true", nil
+			}
+			return "false", nil
+		},
+		want: "true",
+	}, {
+		name: "Valid/DuplicateNames",
+		call: func() (string, error) {
+			if c.Valid([]byte(`{"a":1,"a":2}`)) {
+				return "true", nil
+			}
+			return "false", nil
+		},
+		want:     "false",
+		wantDiff: true,
+	}, {
+		name: "Compact",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			err := c.Compact(&buf, []byte(` { "a" : [ 1 , 2 ] } `))
+			return buf.String(), err
+		},
+		want: `{"a":[1,2]}`,
+	}, {
+		name: "Indent",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			err := c.Indent(&buf, []byte(`{"a":[1,2]}`), "", "\t")
+			return buf.String(), err
+		},
+		want: "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t]\n}",
+	}, {
+		name: "Indent/Unsupported",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			err := c.Indent(&buf, []byte(`{"a":[1]}`), ">", "--")
+			return buf.String(), err
+		},
+		want: "{\n>--\"a\": [\n>----1\n>--]\n>}",
+	}, {
+		name: "HTMLEscape",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			c.HTMLEscape(&buf, []byte(`{"a":"<b>"}`))
+			return buf.String(), nil
+		},
+		want: `{"a":"\u003cb\u003e"}`,
+	}, {
+		name: "HTMLEscape/Whitespace",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			c.HTMLEscape(&buf, []byte(`{"a": "<b>"}`))
+			return buf.String(), nil
+		},
+		want: `{"a": "\u003cb\u003e"}`,
+	}, {
+		name: "HTMLEscape/Indented",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			c.HTMLEscape(&buf, []byte("{\n\t\"a\": \"<b>&\u2028\\\"<\",\n\t\"c\": [ 1, 2 ]\n}\n"))
+			return buf.String(), nil
+		},
+		want: "{\n\t\"a\": \"\\u003cb\\u003e\\u0026\\u2028\\\"\\u003c\",\n\t\"c\": [ 1, 2 ]\n}\n",
+	}, {
+		name: "HTMLEscape/Invalid",
+		call: func() (string, error) {
+			var buf bytes.Buffer
+			c.HTMLEscape(&buf, []byte(`<"a">`))
+			return buf.String(), nil
+		},
+		want:     `\u003c"a"\u003e`,
+		wantDiff: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			out, err := tt.call()
+			if out != tt.want || err != nil {
+				t.Errorf("%s = (%q, %v), want (%q, nil)", tt.name, out, err, tt.want)
+			}
+			if gotDiff := len(got) > 0; gotDiff != tt.wantDiff {
+				t.Errorf("%s reported differences %v, want difference: %v", tt.name, got, tt.wantDiff)
+			}
+		})
+	}
+
+	if n := c.CallModeCounters.Get("Compact/CallBothButReturnV1/calls"); n == nil || n.String() != "1" {
+		t.Errorf("Compact/CallBothButReturnV1/calls = %v, want 1", n)
+	}
+	if n := c.CallModeCounters.Get("Indent/CallBothButReturnV1/calls"); n == nil || n.String() != "2" {
+		t.Errorf("Indent/CallBothButReturnV1/calls = %v, want 2", n)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"hash/fnv"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GoldenTestFile is the name of the test file written by [WriteGoldenTests].
+const GoldenTestFile = "jsonsplit_golden_test.go"
+
+// WriteGoldenTests reads a corpus of differences recorded in production
+// and generates golden files along with a table-driven test asserting that
+// v1 and v2 produce identical results when v2 is called with the detected options.
+// Checking the output into a repository allows continuous integration
+// to guard against regressing on behavior differences already resolved.
+//
+// The corpus is a directory of files that each contain a sequence of
+// differences encoded as JSON by [Difference.MarshalJSON]
+// or [Difference.MarshalJSONLossless] (e.g., JSON Lines).
+// Only differences between the results of v1 and v2 with detected options
+// (see [Codec.AutoDetectOptions]) are used since the others
+// do not yet have a known resolution.
+// The full set of options is replayed: the caller options
+// (including formatting options such as [jsontext.WithIndent]
+// and [jsontext.EscapeForHTML]) are passed to both v1 and v2,
+// and the detected options are additionally passed to v2.
+// Differences with options that cannot be expressed as Go source code
+// (e.g., [Decoder.UseNumber] or options registered by [RegisterOption])
+// are skipped.
+// Differences for Go types that cannot be named in Go source code
+// (e.g., unnamed struct types, generic types, or types in package main)
+// and duplicate differences are skipped.
+//
+// The JSON input of each difference is written as a golden file in
+// the "testdata/jsonsplit" directory within testDir.
+// For marshal differences, the input is the v1 output, which the test
+// unmarshals with v1 to reconstruct an approximation of the original Go value.
+// The test is written to [GoldenTestFile] within testDir
+// and is declared in the specified Go package, which should be an
+// external test package (e.g., "foo_test") if the types used are
+// declared in the package being tested.
+// Existing golden files are not removed.
+func WriteGoldenTests(corpusDir, testDir, pkgName string) error {
+	entries, err := readCorpus(corpusDir)
+	if err != nil {
+		return err
+	}
+
+	type goldenTest struct {
+		name          string // name of the golden file
+		fn            string // either "Marshal" or "Unmarshal"
+		typ           string // Go type expression
+		callerOptions []string
+		options       []string
+		input         []byte
+	}
+	var tests []goldenTest
+	seen := make(map[string]bool)
+	imports := make(map[string]bool)
+	for _, e := range entries {
+		var input []byte
+		switch e.Func {
+		case "Marshal":
+			input = e.JSONValueV1
+		case "Unmarshal":
+			input = e.JSONValue
+		}
+		if len(input) == 0 || e.Check != "" || len(e.Options) == 0 ||
+			!allOptionExprs(e.CallerOptions) || !allOptionExprs(e.Options) {
+			continue
+		}
+		typ, ok := goTypeExpr(e.GoType, imports)
+		if !ok {
+			continue
+		}
+		if e.Func == "Unmarshal" {
+			if !strings.HasPrefix(typ, "*") {
+				continue
+			}
+			typ = typ[len("*"):]
+		}
+
+		h := fnv.New64a()
+		for _, s := range []string{e.Func, e.GoType, string(input)} {
+			io.WriteString(h, s)
+			h.Write([]byte{0})
+		}
+		for _, s := range e.CallerOptions {
+			io.WriteString(h, s)
+			h.Write([]byte{0})
+		}
+		name := fmt.Sprintf("%016x.json", h.Sum64())
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tests = append(tests, goldenTest{name, e.Func, typ, e.CallerOptions, e.Options, input})
+	}
+	slices.SortFunc(tests, func(x, y goldenTest) int { return strings.Compare(x.name, y.name) })
+
+	goldenDir := filepath.Join(testDir, "testdata", "jsonsplit")
+	if err := os.MkdirAll(goldenDir, 0o777); err != nil {
+		return err
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(filepath.Join(goldenDir, tt.name), tt.input, 0o666); err != nil {
+			return err
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by jsonsplit.WriteGoldenTests. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	fmt.Fprintf(&b, "import (\n\"bytes\"\n\"os\"\n\"path/filepath\"\n\"reflect\"\n\"slices\"\n\"testing\"\n\n")
+	fmt.Fprintf(&b, "jsonv2 %q\n", "github.com/go-json-experiment/json")
+	if slices.ContainsFunc(tests, func(tt goldenTest) bool {
+		isJSONText := func(s string) bool { return strings.HasPrefix(s, "jsontext.") }
+		return slices.ContainsFunc(tt.callerOptions, isJSONText) || slices.ContainsFunc(tt.options, isJSONText)
+	}) {
+		fmt.Fprintf(&b, "jsontext %q\n", "github.com/go-json-experiment/json/jsontext")
+	}
+	fmt.Fprintf(&b, "jsonv1 %q\n", "github.com/go-json-experiment/json/v1")
+	for _, p := range slices.Sorted(maps.Keys(imports)) {
+		fmt.Fprintf(&b, "%q\n", p)
+	}
+	fmt.Fprintf(&b, ")\n\n")
+	fmt.Fprintf(&b, `// TestJSONSplitGolden asserts that v1 and v2 behave identically
+// for inputs recorded in production when both are called with
+// the caller options and v2 is additionally called with the detected options.
+func TestJSONSplitGolden(t *testing.T) {
+	tests := []struct {
+		name          string
+		fn            string
+		new           func() any
+		callerOptions []jsonv2.Options
+		options       []jsonv2.Options
+	}{
+`)
+	for _, tt := range tests {
+		fmt.Fprintf(&b, "{%s, %s, func() any { return new(%s) }, %s, %s},\n",
+			strconv.Quote(tt.name), strconv.Quote(tt.fn), tt.typ,
+			optionsExpr(tt.callerOptions), optionsExpr(tt.options))
+	}
+	fmt.Fprintf(&b, `}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("testdata", "jsonsplit", tt.name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			optsV1 := slices.Concat([]jsonv2.Options{jsonv1.DefaultOptionsV1()}, tt.callerOptions)
+			optsV2 := slices.Concat(tt.callerOptions, tt.options)
+			switch tt.fn {
+			case "Marshal":
+				v := tt.new()
+				if err := jsonv1.Unmarshal(in, v); err != nil {
+					t.Fatalf("jsonv1.Unmarshal error: %%v", err)
+				}
+				gotV1, errV1 := jsonv2.Marshal(v, optsV1...)
+				gotV2, errV2 := jsonv2.Marshal(v, optsV2...)
+				if !bytes.Equal(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {
+					t.Errorf("Marshal mismatch:\n\tv1: %%s, %%v\n\tv2: %%s, %%v", gotV1, errV1, gotV2, errV2)
+				}
+			case "Unmarshal":
+				gotV1, gotV2 := tt.new(), tt.new()
+				errV1 := jsonv2.Unmarshal(in, gotV1, optsV1...)
+				errV2 := jsonv2.Unmarshal(in, gotV2, optsV2...)
+				if !reflect.DeepEqual(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {
+					t.Errorf("Unmarshal mismatch:\n\tv1: %%+v, %%v\n\tv2: %%+v, %%v", gotV1, errV1, gotV2, errV2)
+				}
+			}
+		})
+	}
+}
+`)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(testDir, GoldenTestFile), src, 0o666)
+}
+
+// allOptionExprs reports whether every option name
+// as reported by [Difference.OptionNames] is expressible as Go source code
+// that only refers to the jsonv2, jsontext, and jsonv1 packages.
+func allOptionExprs(names []string) bool {
+	for _, name := range names {
+		if isOptionName(strings.TrimSuffix(name, "(false)")) {
+			continue
+		}
+		if _, ok := LookupOption(strings.TrimSuffix(name, "(false)")); ok {
+			return false // registered by [RegisterOption]
+		}
+		if _, ok := optionExpr(name); !ok {
+			return false // i.e., "jsonv1.Decoder.UseNumber"
+		}
+		if _, ok := parseOptionName(name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// optionsExpr formats option names as a Go []jsonv2.Options expression.
+func optionsExpr(names []string) string {
+	if len(names) == 0 {
+		return "nil"
+	}
+	var exprs []string
+	for _, name := range names {
+		expr, _ := optionExpr(name)
+		exprs = append(exprs, expr)
+	}
+	return "[]jsonv2.Options{" + strings.Join(exprs, ", ") + "}"
+}
+
+// isOptionName reports whether name is a known option name
+// as reported by [Difference.OptionNames].
+func isOptionName(name string) bool {
+	_, ok := builtinOption(name)
+	return ok
+}
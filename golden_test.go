@@ -0,0 +1,103 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsontext "github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteGoldenTests(t *testing.T) {
+	var corpus strings.Builder
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) {
+		corpus.WriteString(d.String() + "\n")
+	}}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+	codec.Marshal(map[string][]int{"k": nil}) // duplicate
+	codec.Marshal(map[string][]int{"k": nil}, jsontext.WithIndent("\t"), jsontext.EscapeForHTML(true))
+	codec.Unmarshal([]byte(`{"COUNTERS":{}}`), new(Snapshot))
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(struct{ Name string })) // unnamed type
+	codec.Unmarshal([]byte(`{"a":1,"a":2}`), new(map[string]int))
+	corpus.WriteString(`{"Func":"Unmarshal","GoType":"*int","JSONValue":1}` + "\n") // no detected options
+	corpusDir, testDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(corpusDir, "diffs.jsonl"), []byte(corpus.String()), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteGoldenTests(corpusDir, testDir, "foo_test"); err != nil {
+		t.Fatalf("WriteGoldenTests error: %v", err)
+	}
+	goldens, err := os.ReadDir(filepath.Join(testDir, "testdata", "jsonsplit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotGoldens := make(map[string]string)
+	for _, de := range goldens {
+		b, err := os.ReadFile(filepath.Join(testDir, "testdata", "jsonsplit", de.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotGoldens[de.Name()] = string(b)
+	}
+	wantGoldens := map[string]string{
+		"0a9eb4171ee1d9fc.json": `{"COUNTERS":{}}`,
+		"1dfbe37deff4a01b.json": `{"k":null}`,
+		"49e24200952e253d.json": `{"k":null}`,
+		"f8ca53798d697017.json": `{"a":1,"a":2}`,
+	}
+	if d := cmp.Diff(gotGoldens, wantGoldens); d != "" {
+		t.Errorf("golden files mismatch (-got +want):\n%s", d)
+	}
+
+	got, err := os.ReadFile(filepath.Join(testDir, GoldenTestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by jsonsplit.WriteGoldenTests. DO NOT EDIT.
+
+package foo_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	jsontext "github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"github.com/go-json-experiment/jsonsplit"
+)
+
+// TestJSONSplitGolden asserts that v1 and v2 behave identically
+// for inputs recorded in production when both are called with
+// the caller options and v2 is additionally called with the detected options.
+func TestJSONSplitGolden(t *testing.T) {
+	tests := []struct {
+		name          string
+		fn            string
+		new           func() any
+		callerOptions []jsonv2.Options
+		options       []jsonv2.Options
+	}{
+		{"0a9eb4171ee1d9fc.json", "Unmarshal", func() any { return new(jsonsplit.Snapshot) }, nil, []jsonv2.Options{jsonv2.MatchCaseInsensitiveNames(true)}},
+		{"1dfbe37deff4a01b.json", "Marshal", func() any { return new(map[string][]int) }, nil, []jsonv2.Options{jsonv2.FormatNilSliceAsNull(true)}},
+		{"49e24200952e253d.json", "Marshal", func() any { return new(map[string][]int) }, []jsonv2.Options{jsontext.EscapeForHTML(true), jsontext.WithIndent("\t")}, []jsonv2.Options{jsonv2.FormatNilSliceAsNull(true)}},
+		{"f8ca53798d697017.json", "Unmarshal", func() any { return new(map[string]int) }, nil, []jsonv2.Options{jsontext.AllowDuplicateNames(true)}},
+	}
+`
+	if !strings.HasPrefix(string(got), want) {
+		t.Errorf("test file mismatch (-got +want):\n%s", cmp.Diff(string(got)[:min(len(got), len(want))], want))
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import "slices"
+
+// DifferenceHandler is a function registered by [Codec.AddDifferenceHandler].
+type DifferenceHandler struct {
+	f func(Difference)
+}
+
+// AddDifferenceHandler registers f to be called with every difference
+// that is passed to [Codec.ReportDifference] (which need not be set)
+// so that differences can be handled in multiple ways simultaneously
+// (e.g., logged, counted, and recorded) without a custom fan-out function.
+// Handlers are called synchronously after [Codec.ReportDifference]
+// in the order they were added, and may be added or removed at any time.
+// As with [Codec.ReportDifference], the fields in [Difference] may alias
+// the call arguments and should not leak beyond the function call.
+//
+// It returns a handle for [Codec.RemoveDifferenceHandler].
+func (c *Codec) AddDifferenceHandler(f func(Difference)) *DifferenceHandler {
+	h := &DifferenceHandler{f}
+	defer c.notifyConfig() // after unlocking
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	hs := c.handlers.Load()
+	if hs == nil {
+		hs = new([]*DifferenceHandler)
+	}
+	hs2 := append(slices.Clip(*hs), h)
+	c.handlers.Store(&hs2)
+	return h
+}
+
+// RemoveDifferenceHandler unregisters a handler added by
+// [Codec.AddDifferenceHandler]. It is a no-op if h is already removed.
+// The handler may still be called by reports that are in progress.
+func (c *Codec) RemoveDifferenceHandler(h *DifferenceHandler) {
+	defer c.notifyConfig() // after unlocking
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	hs := c.handlers.Load()
+	if hs == nil {
+		return
+	}
+	hs2 := slices.DeleteFunc(slices.Clone(*hs), func(h2 *DifferenceHandler) bool { return h2 == h })
+	c.handlers.Store(&hs2)
+}
+
+// Sink is a destination for differences that buffers them
+// until it is closed, such as [FileSink] or [WebhookSink].
+type Sink interface {
+	Report(Difference)
+	Close() error
+}
+
+// AddSink registers the Report method of s as a difference handler
+// (see [Codec.AddDifferenceHandler]) and transfers ownership of s
+// to the codec, such that [Codec.Close] closes s after all queued
+// differences have been reported and returns any error from closing it.
+func (c *Codec) AddSink(s Sink) {
+	h := c.AddDifferenceHandler(s.Report)
+	c.addFlushHook(&flushHook{
+		flush: func() error { return nil },
+		close: func() error {
+			c.RemoveDifferenceHandler(h)
+			return s.Close()
+		},
+	})
+}
+
+// differenceHandlers returns the handlers added by [Codec.AddDifferenceHandler].
+func (c *Codec) differenceHandlers() []*DifferenceHandler {
+	if hs := c.handlers.Load(); hs != nil {
+		return *hs
+	}
+	return nil
+}
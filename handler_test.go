@@ -0,0 +1,82 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDifferenceHandlers(t *testing.T) {
+	var got []string
+	codec := &Codec{}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	h1 := codec.AddDifferenceHandler(func(d Difference) { got = append(got, "h1:"+d.Func) })
+	h2 := codec.AddDifferenceHandler(func(d Difference) { got = append(got, "h2:"+d.Func) })
+	if n := codec.Config().DifferenceHandlers; n != 2 {
+		t.Errorf("Config().DifferenceHandlers = %d, want 2", n)
+	}
+	codec.Marshal([]int(nil))
+	codec.RemoveDifferenceHandler(h1)
+	codec.RemoveDifferenceHandler(h1) // no-op
+	codec.Marshal([]int(nil))
+	codec.RemoveDifferenceHandler(h2)
+	codec.Marshal([]int(nil))
+	if want := []string{"h1:Marshal", "h2:Marshal", "h2:Marshal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("handled differences = %q, want %q", got, want)
+	}
+	if codec.reportsDifferences() {
+		t.Errorf("reportsDifferences = true, want false")
+	}
+}
+
+type errSink struct {
+	reported []Difference
+	closed   bool
+}
+
+func (s *errSink) Report(d Difference) { s.reported = append(s.reported, d) }
+func (s *errSink) Close() error {
+	s.closed = true
+	return errors.New("sink error")
+}
+
+func TestAddSink(t *testing.T) {
+	errState := errors.New("state error")
+	var sink errSink
+	fileSink := &FileSink{Dir: t.TempDir()}
+	var saved bool
+	codec := &Codec{
+		AsyncReportQueueSize: 4,
+		SaveState: func(c *Codec) error {
+			if !sink.closed {
+				t.Errorf("SaveState called before sinks were closed")
+			}
+			saved = true
+			return errState
+		},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.AddSink(&sink)
+	codec.AddSink(fileSink)
+	codec.Marshal([]int(nil))
+
+	err := codec.Close()
+	if !errors.Is(err, errState) || err == nil || !strings.Contains(err.Error(), "sink error") {
+		t.Errorf("Close error = %v, want both the sink and state errors", err)
+	}
+	if len(sink.reported) != 1 || !sink.closed || !saved {
+		t.Errorf("got %d reports (closed: %v, saved: %v), want 1 report closed and saved", len(sink.reported), sink.closed, saved)
+	}
+	ds, err := ReadCorpus(fileSink.Dir)
+	if err != nil || len(ds) != 1 {
+		t.Errorf("ReadCorpus = (%d differences, %v), want 1 difference", len(ds), err)
+	}
+	if codec.reportsDifferences() {
+		t.Errorf("reportsDifferences = true after Close, want false")
+	}
+}
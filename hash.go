@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"hash/maphash"
+	"sync"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// hashSeed is the seed used by [hashGoValue].
+// It is randomly chosen once per process.
+var hashSeed = maphash.MakeSeed()
+
+// hashOptions are the options used by [hashGoValue] to canonically
+// marshal a Go value, which preserve the distinction between
+// nil and empty slices and maps.
+var hashOptions = sync.OnceValue(func() jsonv2.Options {
+	return jsonv2.JoinOptions(
+		jsonv2.Deterministic(true),
+		jsonv2.FormatNilSliceAsNull(true),
+		jsonv2.FormatNilMapAsNull(true),
+	)
+})
+
+// hashGoValue hashes the canonical JSON representation of v
+// without buffering the entire representation in memory.
+// Unexported struct fields and values without a JSON representation
+// are not hashed, and so may differ between values with the same hash.
+func hashGoValue(v any) (uint64, error) {
+	var h maphash.Hash
+	h.SetSeed(hashSeed)
+	if err := jsonv2.MarshalWrite(&h, v, hashOptions()); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+// hashEqual reports whether v1 and v2 have the same hash according to
+// [hashGoValue]. If either value cannot be hashed, ok is false.
+func hashEqual(v1, v2 any) (equal, ok bool) {
+	h1, err1 := hashGoValue(v1)
+	h2, err2 := hashGoValue(v2)
+	if err1 != nil || err2 != nil {
+		return false, false
+	}
+	return h1 == h2, true
+}
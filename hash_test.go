@@ -0,0 +1,50 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import "testing"
+
+func TestHashEqual(t *testing.T) {
+	for _, tt := range []struct {
+		v1, v2    any
+		wantEqual bool
+		wantOK    bool
+	}{
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2, "a": 1}, true, true},
+		{map[string]int{"a": 1}, map[string]int{"a": 2}, false, true},
+		{[]int(nil), []int{}, false, true},
+		{map[string]int(nil), map[string]int{}, false, true},
+		{make(chan int), make(chan int), false, false},
+	} {
+		gotEqual, gotOK := hashEqual(tt.v1, tt.v2)
+		if gotEqual != tt.wantEqual || gotOK != tt.wantOK {
+			t.Errorf("hashEqual(%v, %v) = (%v, %v), want (%v, %v)", tt.v1, tt.v2, gotEqual, gotOK, tt.wantEqual, tt.wantOK)
+		}
+	}
+}
+
+func TestHashGoValuesAboveSize(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		HashGoValuesAboveSize: len(`{"Name":"x"}`) - 1,
+		// Only values compared by hashing can be considered equal.
+		EqualGoValues:    func(any, any) bool { return false },
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	type T struct{ Name string }
+	codec.Unmarshal([]byte(`{"Name":"x"}`), new(T))
+	if len(got) != 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(T))
+	if len(got) != 1 {
+		t.Errorf("got %d differences, want 1", len(got))
+	}
+	codec.Unmarshal([]byte(`{}`), new(T)) // too small to hash
+	if len(got) != 2 {
+		t.Errorf("got %d differences, want 2", len(got))
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// RouteCodecs manages a separate [Codec] per HTTP route,
+// so that each endpoint can have its own call ratios and metrics.
+//
+// For example:
+//
+//	var routes jsonsplit.RouteCodecs
+//	routes.Handle(mux, "GET /users/{id}", http.HandlerFunc(getUser))
+//	routes.Codec("GET /users/{id}").SetUnmarshalCallMode(jsonsplit.CallBothButReturnV1)
+//	expvar.Publish("jsonsplit_routes", routes.ExpVar())
+//
+// where getUser uses [FromContext] to obtain the codec for its route:
+//
+//	func getUser(w http.ResponseWriter, r *http.Request) {
+//		codec := jsonsplit.FromContext(r.Context())
+//		b, err := codec.MarshalContext(r.Context(), ...)
+//		...
+//	}
+//
+// The exported fields must be set before concurrent use.
+type RouteCodecs struct {
+	// NewCodec returns a new codec for the named route.
+	// It is called at most once per route and may be used to
+	// apply common configuration (e.g., [Codec.ReportDifference]).
+	// If nil, each route uses a new zero [Codec], which will [OnlyCallV1].
+	NewCodec func(route string) *Codec
+
+	mu     sync.Mutex
+	codecs map[string]*Codec
+}
+
+// Codec returns the codec for the named route, creating it if necessary.
+func (rc *RouteCodecs) Codec(route string) *Codec {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if c, ok := rc.codecs[route]; ok {
+		return c
+	}
+	var c *Codec
+	if rc.NewCodec != nil {
+		c = rc.NewCodec(route)
+	}
+	if c == nil {
+		c = new(Codec)
+	}
+	if rc.codecs == nil {
+		rc.codecs = make(map[string]*Codec)
+	}
+	rc.codecs[route] = c
+	return c
+}
+
+// Handler wraps h such that the request context carries
+// the codec for the named route, which is retrievable with [FromContext],
+// and the route as the caller label (see [WithCallerLabel]),
+// unless the context already carries a caller label.
+// Thus, differences reported by calls that use the request context
+// (e.g., [Codec.MarshalContext] and [Codec.WriteJSONResponse])
+// are attributed to the route.
+func (rc *RouteCodecs) Handler(route string, h http.Handler) http.Handler {
+	c := rc.Codec(route)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(r.Context(), c)
+		if callerLabel(ctx) == "" && route != "" {
+			ctx = WithCallerLabel(ctx, route)
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Handle registers h with mux for the given pattern,
+// using the pattern as the route name for [RouteCodecs.Handler].
+func (rc *RouteCodecs) Handle(mux *http.ServeMux, pattern string, h http.Handler) {
+	mux.Handle(pattern, rc.Handler(pattern, h))
+}
+
+// ExpVar returns an expvar mapping of each route name
+// to the metrics of its codec (see [CodecMetrics.ExpVar]).
+func (rc *RouteCodecs) ExpVar() expvar.Var {
+	return stringVar(func() string {
+		rc.mu.Lock()
+		codecs := maps.Clone(rc.codecs)
+		rc.mu.Unlock()
+		var m expvar.Map
+		for route, c := range codecs {
+			m.Set(route, c.ExpVar())
+		}
+		return m.String()
+	})
+}
+
+// WriteJSONResponse marshals v with [Codec.Marshal] and writes it
+// as the response body with the provided status code and
+// a Content-Type of "application/json".
+// If marshaling fails, nothing is written to w and the error is returned,
+// such that the handler may still respond with an error status.
+//
+// Differences are attributed to the caller label carried by
+// the context of r (see [WithCallerLabel]), otherwise to the
+// [http.ServeMux] pattern that matched r (e.g., "GET /users/{id}"),
+// and otherwise to the caller.
+func (c *Codec) WriteJSONResponse(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	b, err := c.marshal(r.Context(), callSite{label: routeLabel(r)}, nil, jsonv1Marshal, jsonv2.Marshal, v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadJSONRequest reads the entire request body of r and
+// unmarshals it into v with [Codec.Unmarshal].
+// Differences are attributed in the same way as [Codec.WriteJSONResponse].
+func (c *Codec) ReadJSONRequest(r *http.Request, v any) error {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(r.Context(), callSite{label: routeLabel(r)}, nil, b, v)
+}
+
+// routeLabel returns the caller label for an HTTP request,
+// or the empty string if differences should be attributed to the caller.
+func routeLabel(r *http.Request) string {
+	if label := callerLabel(r.Context()); label != "" {
+		return label
+	}
+	return r.Pattern
+}
+
+// stringVar is an [expvar.Var] that lazily produces its JSON representation.
+type stringVar func() string
+
+func (f stringVar) String() string { return f() }
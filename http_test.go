@@ -0,0 +1,98 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRouteCodecs(t *testing.T) {
+	var diffs []Difference
+	routes := RouteCodecs{NewCodec: func(string) *Codec {
+		c := &Codec{ReportDifference: func(d Difference) { diffs = append(diffs, d) }}
+		c.SetMarshalCallMode(CallBothButReturnV1)
+		return c
+	}}
+	mux := http.NewServeMux()
+	var got []*Codec
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := FromContext(r.Context())
+		got = append(got, c)
+		c.MarshalContext(r.Context(), []int(nil))
+	})
+	routes.Handle(mux, "GET /a", h)
+	routes.Handle(mux, "GET /b", h)
+
+	for _, path := range []string{"/a", "/b", "/a"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	ca, cb := routes.Codec("GET /a"), routes.Codec("GET /b")
+	if len(got) != 3 || got[0] != ca || got[1] != cb || got[2] != ca {
+		t.Fatalf("handlers did not observe the per-route codecs")
+	}
+	if ca == &GlobalCodec || ca == cb {
+		t.Fatalf("per-route codecs are not distinct")
+	}
+
+	var callers []string
+	for _, d := range diffs {
+		callers = append(callers, d.Caller)
+	}
+	if want := []string{"GET /a", "GET /b", "GET /a"}; !slices.Equal(callers, want) {
+		t.Errorf("difference callers = %v, want %v", callers, want)
+	}
+
+	var metrics map[string]map[string]any
+	if err := json.Unmarshal([]byte(routes.ExpVar().String()), &metrics); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	for route, want := range map[string]float64{"GET /a": 2, "GET /b": 1} {
+		if got := metrics[route]["num_marshal_total"]; got != want {
+			t.Errorf("%s: num_marshal_total = %v, want %v", route, got, want)
+		}
+	}
+}
+
+func TestJSONRequestResponse(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var v struct{ Name string }
+		if err := codec.ReadJSONRequest(r, &v); err != nil {
+			t.Errorf("ReadJSONRequest error: %v", err)
+		}
+		if err := codec.WriteJSONResponse(w, r, http.StatusCreated, map[string][]string{v.Name: nil}); err != nil {
+			t.Errorf("WriteJSONResponse error: %v", err)
+		}
+	})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/users/1", strings.NewReader(`{"NAME":"x"}`)))
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Type") != "application/json" || rec.Body.String() != `{"x":null}` {
+		t.Errorf("response = (%d, %q, %s), want (201, application/json, %s)",
+			rec.Code, rec.Header().Get("Content-Type"), rec.Body.String(), `{"x":null}`)
+	}
+	if len(got) != 2 || got[0].Caller != "POST /users/{id}" || got[1].Caller != "POST /users/{id}" {
+		t.Errorf("got differences %v, want differences from the route", got)
+	}
+	if got := codec.UnmarshalCallerHistogram.Get("POST /users/{id}"); got == nil || got.String() != "1" {
+		t.Errorf("UnmarshalCallerHistogram[route] = %v, want 1", got)
+	}
+
+	// Marshal errors do not write a response.
+	rec = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	if err := codec.WriteJSONResponse(rec, r, http.StatusOK, make(chan int)); err == nil || rec.Body.Len() > 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("WriteJSONResponse = %v with body %q, want error and no response", err, rec.Body.String())
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package httpsplit provides HTTP middleware that measures the risk of
+// migrating HTTP handlers from [jsonv1] to [jsonv2] without modifying them.
+//
+// The [Middleware] tees the JSON request and response bodies of
+// each request and asynchronously parses them with both v1 and v2
+// using a [jsonsplit.Codec], such that any differences in how the
+// JSON text itself is handled (e.g., duplicate object member names or
+// invalid UTF-8) are reported with the HTTP route as the caller.
+//
+// Since the bodies are only parsed into a Go any value
+// without knowledge of the Go types that the handlers use,
+// only differences in the JSON syntax are detected.
+// Differences that depend on the Go types, which are most of
+// the differences relevant to a migration (e.g., case-insensitive matching
+// of object names, the handling of omitempty, or nil slices and maps),
+// are invisible to this package and are only detected by marshaling and
+// unmarshaling the Go values within the handlers with a [jsonsplit.Codec].
+//
+// [jsonv1]: https://pkg.go.dev/encoding/json
+// [jsonv2]: https://pkg.go.dev/github.com/go-json-experiment/json
+package httpsplit
+
+import (
+	"bytes"
+	"expvar"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-json-experiment/jsonsplit"
+)
+
+// DefaultMaxBodySize is the default for [Middleware.MaxBodySize].
+const DefaultMaxBodySize = 1 << 20
+
+// DefaultMaxPending is the default for [Middleware.MaxPending].
+const DefaultMaxPending = 64
+
+// Middleware shadow-parses JSON request and response bodies.
+// The exported fields must be set before concurrent use.
+//
+// For example:
+//
+//	codec := new(jsonsplit.Codec)
+//	codec.SetUnmarshalCallRatio(jsonsplit.OnlyCallV1, jsonsplit.CallBothButReturnV1, 0.1)
+//	codec.ReportDifference = func(d jsonsplit.Difference) { ... }
+//	mw := &httpsplit.Middleware{Codec: codec}
+//	http.ListenAndServe(addr, mw.Handler(mux))
+type Middleware struct {
+	// Codec is the codec used to parse the bodies with
+	// [jsonsplit.Codec.Unmarshal] into a Go any value.
+	// Its unmarshal call ratio (see [jsonsplit.Codec.SetUnmarshalCallRatio])
+	// determines which bodies are parsed by both v1 and v2.
+	// Bodies are not buffered at all while the ratio
+	// never calls both (see [jsonsplit.Codec.MayCompareUnmarshal]).
+	// If nil, it uses [jsonsplit.GlobalCodec].
+	Codec *jsonsplit.Codec
+
+	// Route returns the caller label for a request
+	// (see [jsonsplit.Codec.WithCallerLabel]).
+	// It is called after the handler returns.
+	// If nil, it uses the [http.ServeMux] pattern that matched
+	// the request (e.g., "GET /users/{id}"), otherwise the request method.
+	Route func(*http.Request) string
+
+	// MaxBodySize is the maximum size of a body that is shadow-parsed.
+	// Larger bodies are passed through, but not parsed.
+	// If zero, it uses [DefaultMaxBodySize].
+	MaxBodySize int
+
+	// SampleRate is the fraction of requests (between 0 and 1)
+	// whose bodies are buffered and shadow-parsed, which is decided
+	// before the handler is called such that unsampled requests
+	// incur no buffering. If zero (or at least one), every request is sampled.
+	SampleRate float64
+
+	// MaxPending is the maximum number of requests whose bodies
+	// are being shadow-parsed concurrently. The bodies of requests
+	// that complete while the limit is reached are not shadow-parsed
+	// and counted in NumDropped.
+	// If zero or negative, it uses [DefaultMaxPending].
+	MaxPending int
+
+	// NumDropped is the number of requests whose bodies were not
+	// shadow-parsed since MaxPending requests were already pending.
+	NumDropped expvar.Int
+
+	wg       sync.WaitGroup
+	semaOnce sync.Once
+	sema     chan struct{} // limits the number of pending shadow-parses
+}
+
+// Handler wraps h such that the JSON request and response bodies
+// of every sampled request (see [Middleware.SampleRate])
+// are shadow-parsed once h returns.
+// Only bodies with a JSON media type (e.g., "application/json")
+// are considered, and a request body is only parsed if h read it entirely.
+// The response is passed through to the client unmodified.
+func (m *Middleware) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codec := m.Codec
+		if codec == nil {
+			codec = &jsonsplit.GlobalCodec
+		}
+		if !codec.MayCompareUnmarshal() {
+			h.ServeHTTP(w, r) // e.g., only calls v1
+			return
+		}
+		if rate := m.SampleRate; rate > 0 && rate < 1 && rand.Float64() >= rate {
+			h.ServeHTTP(w, r)
+			return
+		}
+		maxSize := m.MaxBodySize
+		if maxSize == 0 {
+			maxSize = DefaultMaxBodySize
+		}
+		var req *bodyReader
+		if r.Body != nil && r.Body != http.NoBody && isJSON(r.Header.Get("Content-Type")) {
+			req = &bodyReader{ReadCloser: r.Body, recorder: recorder{max: maxSize}}
+			r.Body = req
+		}
+		resp := &responseWriter{ResponseWriter: w, recorder: recorder{max: maxSize}}
+		if _, ok := w.(http.Flusher); ok {
+			h.ServeHTTP(flushResponseWriter{resp}, r)
+		} else {
+			h.ServeHTTP(resp, r)
+		}
+
+		var bodies [][]byte
+		if req != nil && req.eof && !req.overflow && req.buf.Len() > 0 {
+			bodies = append(bodies, req.buf.Bytes())
+		}
+		if resp.json && !resp.overflow && resp.buf.Len() > 0 {
+			bodies = append(bodies, resp.buf.Bytes())
+		}
+		if len(bodies) == 0 {
+			return
+		}
+		lc := codec.WithCallerLabel(m.route(r))
+		m.semaOnce.Do(func() {
+			maxPending := m.MaxPending
+			if maxPending <= 0 {
+				maxPending = DefaultMaxPending
+			}
+			m.sema = make(chan struct{}, maxPending)
+		})
+		select {
+		case m.sema <- struct{}{}:
+		default:
+			m.NumDropped.Add(1)
+			return
+		}
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer func() { <-m.sema }()
+			for _, b := range bodies {
+				var v any
+				lc.Unmarshal(b, &v)
+			}
+		}()
+	})
+}
+
+// Wait waits for all pending shadow-parses to complete
+// (e.g., before reading the metrics of the codec during shutdown).
+func (m *Middleware) Wait() {
+	m.wg.Wait()
+}
+
+func (m *Middleware) route(r *http.Request) string {
+	if m.Route != nil {
+		if route := m.Route(r); route != "" {
+			return route
+		}
+	}
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if r.Method != "" {
+		return r.Method
+	}
+	return http.MethodGet
+}
+
+// isJSON reports whether the media type is JSON
+// (i.e., "application/json" or any type with a "+json" suffix).
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// recorder records up to max bytes and
+// discards everything once the limit is exceeded.
+type recorder struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (rec *recorder) record(b []byte) {
+	switch {
+	case rec.overflow:
+	case rec.buf.Len()+len(b) > rec.max:
+		rec.overflow = true
+		rec.buf = bytes.Buffer{}
+	default:
+		rec.buf.Write(b)
+	}
+}
+
+// bodyReader records a request body as it is read by the handler.
+type bodyReader struct {
+	io.ReadCloser
+	recorder
+	eof bool
+}
+
+func (r *bodyReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.record(b[:n])
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// responseWriter records a response body as it is written by the handler.
+type responseWriter struct {
+	http.ResponseWriter
+	recorder
+	wroteHeader bool
+	json        bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.json = isJSON(w.Header().Get("Content-Type"))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	if w.json {
+		w.record(b[:n])
+	}
+	return n, err
+}
+
+// Unwrap returns the underlying writer for use by [http.ResponseController].
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flushResponseWriter is a [responseWriter] that implements [http.Flusher]
+// for underlying writers that implement it.
+type flushResponseWriter struct{ *responseWriter }
+
+func (w flushResponseWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
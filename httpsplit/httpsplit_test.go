@@ -0,0 +1,176 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpsplit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-json-experiment/jsonsplit"
+)
+
+func TestMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var got []jsonsplit.Difference
+	codec := &jsonsplit.Codec{ReportDifference: func(d jsonsplit.Difference) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, d)
+	}}
+	codec.SetUnmarshalCallMode(jsonsplit.CallBothButReturnV1)
+	mw := &Middleware{Codec: codec}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+	mux.HandleFunc("POST /text", func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(io.LimitReader(r.Body, 1)) // partially read
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, `{"a":1,"a":2}`)
+	})
+	h := mw.Handler(mux)
+
+	serve := func(path, body string) string {
+		r := httptest.NewRequest("POST", path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+	const dupNames = `{"a":1,"a":2}`
+	if resp := serve("/echo", dupNames); resp != dupNames {
+		t.Errorf("response = %s, want %s", resp, dupNames)
+	}
+	serve("/text", dupNames)
+	serve("/echo", `{"a":1}`)
+	mw.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("got %d differences, want 2 (for the request and response bodies)", len(got))
+	}
+	for _, d := range got {
+		if d.Caller != "POST /echo" || string(d.JSONValue) != dupNames {
+			t.Errorf("got difference from %s for %s, want difference from %s for %s", d.Caller, d.JSONValue, "POST /echo", dupNames)
+		}
+	}
+	if n := codec.NumUnmarshalTotal.Value(); n != 4 {
+		t.Errorf("NumUnmarshalTotal = %d, want 4", n)
+	}
+}
+
+func TestMiddlewareSampleRate(t *testing.T) {
+	codec := &jsonsplit.Codec{}
+	codec.SetUnmarshalCallMode(jsonsplit.CallBothButReturnV1)
+	mw := &Middleware{Codec: codec, SampleRate: 1e-9}
+	h := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Body.(*bodyReader); ok {
+			t.Errorf("request body of unsampled request is buffered")
+		}
+		if _, ok := w.(*responseWriter); ok {
+			t.Errorf("response body of unsampled request is buffered")
+		}
+		io.ReadAll(r.Body)
+	}))
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	mw.Wait()
+	if n := codec.NumUnmarshalTotal.Value(); n != 0 {
+		t.Errorf("NumUnmarshalTotal = %d, want 0", n)
+	}
+}
+
+func TestMiddlewareOnlyCallV1(t *testing.T) {
+	codec := &jsonsplit.Codec{}
+	codec.SetUnmarshalCallMode(jsonsplit.OnlyCallV1)
+	mw := &Middleware{Codec: codec}
+	h := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Body.(*bodyReader); ok {
+			t.Errorf("request body is buffered despite never comparing")
+		}
+		if _, ok := w.(*responseWriter); ok {
+			t.Errorf("response body is buffered despite never comparing")
+		}
+		io.ReadAll(r.Body)
+	}))
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	mw.Wait()
+	if n := codec.NumUnmarshalTotal.Value(); n != 0 {
+		t.Errorf("NumUnmarshalTotal = %d, want 0", n)
+	}
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	codec := &jsonsplit.Codec{}
+	codec.SetUnmarshalCallMode(jsonsplit.CallBothButReturnV1)
+	mw := &Middleware{Codec: codec}
+	var isFlusher bool
+	h := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var f http.Flusher
+		f, isFlusher = w.(http.Flusher)
+		if isFlusher {
+			f.Flush()
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if !isFlusher || !rec.Flushed {
+		t.Errorf("Flusher = %v, Flushed = %v, want true, true", isFlusher, rec.Flushed)
+	}
+
+	h.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil))
+	if isFlusher {
+		t.Errorf("wrapped writer implements http.Flusher, but the underlying writer does not")
+	}
+}
+
+func TestMiddlewareMaxPending(t *testing.T) {
+	release := make(chan struct{})
+	codec := &jsonsplit.Codec{ReportDifference: func(jsonsplit.Difference) { <-release }}
+	codec.SetUnmarshalCallMode(jsonsplit.CallBothButReturnV1)
+	mw := &Middleware{Codec: codec, MaxPending: 1}
+	h := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	}))
+	for range 3 {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":1,"a":2}`))
+		r.Header.Set("Content-Type", "application/json")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	close(release)
+	mw.Wait()
+	if n := mw.NumDropped.Value(); n != 2 {
+		t.Errorf("NumDropped = %d, want 2", n)
+	}
+	if n := codec.NumUnmarshalTotal.Value(); n != 1 {
+		t.Errorf("NumUnmarshalTotal = %d, want 1", n)
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"application/problem+json":        true,
+		"text/plain":                      false,
+		"":                                false,
+	} {
+		if got := isJSON(contentType); got != want {
+			t.Errorf("isJSON(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
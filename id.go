@@ -0,0 +1,34 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// newID returns a new ULID (Universally Unique Lexicographically Sortable
+// Identifier) for the given time, as specified by https://github.com/ulid/spec.
+// It is 26 characters of Crockford's base32, where the first 10 characters
+// encode the Unix time in milliseconds and the remaining 16 characters
+// encode 80 bits of randomness.
+func newID(t time.Time) string {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(t.UnixMilli())<<16)
+	rand.Read(b[6:])
+
+	// Encode 128 bits as 26 groups of 5 bits, where the first group
+	// only has 3 bits since 26×5 = 130 bits.
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i] = alphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewID(t *testing.T) {
+	// Example from https://github.com/ulid/spec.
+	id := newID(time.UnixMilli(1469918176385))
+	if !strings.HasPrefix(id, "01ARYZ6S41") || len(id) != 26 {
+		t.Errorf("newID = %q, want 26 characters with prefix %q", id, "01ARYZ6S41")
+	}
+	if strings.ContainsAny(id[10:], "ILOU") {
+		t.Errorf("newID = %q, want only Crockford base32 characters", id)
+	}
+
+	seen := make(map[string]bool)
+	now := time.Now()
+	prev := newID(now.Add(-time.Millisecond))
+	for range 100 {
+		id := newID(now)
+		if seen[id] {
+			t.Fatalf("newID = %q, want unique IDs", id)
+		}
+		seen[id] = true
+		if id <= prev[:10] {
+			t.Fatalf("newID = %q, want sorted after %q", id, prev)
+		}
+	}
+}
+
+func TestDifferenceID(t *testing.T) {
+	var got Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = d }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	if len(got.ID) != 26 {
+		t.Fatalf("Difference.ID = %q, want a ULID", got.ID)
+	}
+	if s := got.String(); !strings.HasPrefix(s, `{"ID":"`+got.ID+`",`) {
+		t.Errorf("Difference.String = %s, want ID as the first member", s)
+	}
+}
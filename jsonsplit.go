@@ -30,9 +30,7 @@
 //		jsonsplit.GlobalCodec.AutoDetectOptions = true
 //
 //		// Log every time we detect a difference between v1 and v2.
-//		jsonsplit.GlobalCodec.ReportDifference(func(d jsonsplit.Difference) {
-//			slog.Warn("detected jsonv1-to-jsonv2 difference", "diff", d)
-//		})
+//		jsonsplit.GlobalCodec.ReportDifference = jsonsplit.SlogReporter(slog.Default(), slog.LevelWarn)
 //
 //		// Specify that we try both v1 and v2 with some probability,
 //		// but to always return v1 results.
@@ -121,18 +119,24 @@ package jsonsplit
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"expvar"
 	"fmt"
+	"io"
 	"iter"
+	"log"
 	"maps"
 	"math"
 	"math/bits"
 	"math/rand/v2"
+	"os"
+	"path"
 	"reflect"
 	"runtime"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -150,6 +154,12 @@ var currentFile = func() string {
 	return file
 }()
 
+// isPackageFrame reports whether the frame is within a non-test file
+// of this package (e.g., [Encoder.Encode]), which is never the caller.
+func isPackageFrame(fr runtime.Frame) bool {
+	return fr.File == currentFile || (path.Dir(fr.File) == path.Dir(currentFile) && !strings.HasSuffix(fr.File, "_test.go"))
+}
+
 // Helper marks the calling function as a helper function.
 // When producing a [Difference], that function will be skipped
 // when deriving the caller for marshal or unmarshal.
@@ -163,17 +173,27 @@ func (c *Codec) Helper() {
 	c.helperEntries.Store(pcToFrame(pcs[0]).Entry, struct{}{})
 }
 
+const maxStackLen = 50 // same as "testing".maxStackLen
+
 // caller determines the caller of Marshal or Unmarshal,
 // skipping over frames within functions marked as [Codec.Helper].
 func (c *Codec) caller() string {
-	const maxStackLen = 50 // same as "testing".maxStackLen
 	var pcs [maxStackLen]uintptr
 	n := runtime.Callers(2, pcs[:]) // skip [runtime.Callers] + [Codec.caller]
-	frames := runtime.CallersFrames(pcs[:n])
+	return c.callerFrom(pcs[:n])
+}
+
+// callerFrom is like [Codec.caller], but for a stack of PCs
+// previously captured by [runtime.Callers].
+func (c *Codec) callerFrom(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
 	for {
 		fr, more := frames.Next()
 		_, skip := c.helperEntries.Load(fr.Entry)
-		skip = skip || fr.File == currentFile
+		skip = skip || isPackageFrame(fr)
+		skip = skip || slices.ContainsFunc(c.CallerSkipPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(fr.Function, prefix)
+		})
 		if !skip || !more {
 			// Prefer using unique function name with a relative line offset.
 			// This representation is more stable against version drift.
@@ -198,6 +218,30 @@ func (c *Codec) caller() string {
 	}
 }
 
+// callSite identifies what differences are attributed to
+// (see [Difference.Caller]).
+type callSite struct {
+	// label is the caller label (see [Codec.WithCallerLabel]), if any.
+	label string
+	// pcs is the stack captured ahead of the call (see [Codec.Wrap]), if any.
+	// It is only symbolized once the caller is actually needed.
+	pcs []uintptr
+}
+
+// labelOrCaller returns the label of s if non-empty,
+// otherwise the caller resolved from the captured stack of s if any,
+// otherwise it determines the caller of Marshal or Unmarshal.
+func (c *Codec) labelOrCaller(s callSite) string {
+	switch {
+	case s.label != "":
+		return s.label
+	case s.pcs != nil:
+		return c.callerFrom(s.pcs)
+	default:
+		return c.caller()
+	}
+}
+
 func pcToFrame(pc uintptr) runtime.Frame {
 	pcs := []uintptr{pc}
 	frames := runtime.CallersFrames(pcs)
@@ -208,6 +252,18 @@ func pcToFrame(pc uintptr) runtime.Frame {
 // GlobalCodec is a global instantiation of [Codec].
 var GlobalCodec Codec
 
+// usesStandardV1 reports whether the options oV1 returned by
+// [Codec.v1Options] select [jsonv1std] rather than [jsonv1].
+func usesStandardV1(oV1 []jsonv2.Options) bool {
+	return len(oV1) == 1 && oV1[0] == jsonv1.DefaultOptionsV1()
+}
+
+// effectiveOptions returns the options in effect for a call
+// with the options o on top of the defaults.
+func effectiveOptions(defaults jsonv2.Options, o []jsonv2.Options) jsonv2.Options {
+	return jsonv2.JoinOptions(append([]jsonv2.Options{defaults}, o...)...)
+}
+
 // Marshal marshals from v with either [jsonv1.Marshal] or [jsonv2.Marshal]
 // depending on the mode specified in [Codec.SetMarshalCallRatio]
 // on the [GlobalCodec] variable.
@@ -215,6 +271,12 @@ func Marshal(v any, o ...jsonv2.Options) (b []byte, err error) {
 	return GlobalCodec.Marshal(v, o...)
 }
 
+// MarshalIndent is like [Marshal], but applies indentation
+// (see [Codec.MarshalIndent]) on the [GlobalCodec] variable.
+func MarshalIndent(v any, prefix, indent string, o ...jsonv2.Options) ([]byte, error) {
+	return GlobalCodec.MarshalIndent(v, prefix, indent, o...)
+}
+
 // Unmarshal unmarshals into v with either [jsonv1.Unmarshal] or [jsonv2.Unmarshal]
 // depending on the mode specified in [Codec.SetUnmarshalCallRatio]
 // on the [GlobalCodec] variable.
@@ -222,56 +284,516 @@ func Unmarshal(b []byte, v any, o ...jsonv2.Options) error {
 	return GlobalCodec.Unmarshal(b, v, o...)
 }
 
-// Publish calls [expvar.Publish] with [CodecMetrics.ExpVar] under the name "jsonsplit".
+// MarshalContext is like [Marshal], but uses the codec carried by ctx
+// (see [FromContext]) and attributes any differences to the
+// caller label carried by ctx, if any (see [Codec.MarshalContext]).
+func MarshalContext(ctx context.Context, v any, o ...jsonv2.Options) ([]byte, error) {
+	return FromContext(ctx).MarshalContext(ctx, v, o...)
+}
+
+// UnmarshalContext is like [Unmarshal], but uses the codec carried by ctx
+// (see [FromContext]) and attributes any differences to the
+// caller label carried by ctx, if any (see [Codec.UnmarshalContext]).
+func UnmarshalContext(ctx context.Context, b []byte, v any, o ...jsonv2.Options) error {
+	return FromContext(ctx).UnmarshalContext(ctx, b, v, o...)
+}
+
+// Publish calls [PublishAs] with the [GlobalCodec] under the name "jsonsplit".
 func Publish() {
-	expvar.Publish("jsonsplit", GlobalCodec.ExpVar())
+	PublishAs("jsonsplit", &GlobalCodec)
+}
+
+// published is the set of names registered by [PublishAs].
+var published struct {
+	mu sync.Mutex
+	m  map[string]*publishedVar
+}
+
+// publishedVar is an [expvar.Var] whose underlying variable
+// can be removed by [Unpublish] and later replaced by [PublishAs].
+type publishedVar struct {
+	v atomic.Pointer[expvar.Var]
+}
+
+func (p *publishedVar) String() string {
+	if v := p.v.Load(); v != nil {
+		return (*v).String()
+	}
+	return "null"
+}
+
+// PublishAs calls [expvar.Publish] with [CodecMetrics.ExpVar] of c
+// under the provided name, such that multiple codecs can be
+// exported side by side (e.g., "jsonsplit_billing").
+// Like [expvar.Publish], it panics if the name is already in use,
+// unless it was previously removed by [Unpublish].
+func PublishAs(name string, c *Codec) {
+	published.mu.Lock()
+	defer published.mu.Unlock()
+	p := published.m[name]
+	if p == nil {
+		p = new(publishedVar)
+		expvar.Publish(name, p)
+		if published.m == nil {
+			published.m = make(map[string]*publishedVar)
+		}
+		published.m[name] = p
+	}
+	v := c.ExpVar()
+	if !p.v.CompareAndSwap(nil, &v) {
+		panic(fmt.Sprintf("reuse of published name %q", name))
+	}
+}
+
+// Unpublish removes the codec published under the name by [PublishAs]
+// (or [Publish] if the name is "jsonsplit"), such that the name may be
+// published again (e.g., when cleaning up after a test).
+// Since [expvar] provides no way to remove a variable,
+// the name remains listed with a value of null until it is published again.
+// It reports whether a codec was published under the name.
+func Unpublish(name string) bool {
+	published.mu.Lock()
+	defer published.mu.Unlock()
+	p := published.m[name]
+	return p != nil && p.v.Swap(nil) != nil
 }
 
 // Codec configures how to execute marshal and unmarshal calls.
 // The exported fields must be set before concurrent use.
 // The zero value is ready for use and by default will [OnlyCallV1].
 type Codec struct {
+	// DefaultOptions are options applied to every marshal and unmarshal call
+	// (e.g., [jsonv2.MatchCaseInsensitiveNames] to set fleet-wide behavior
+	// without threading the option through every call site).
+	// They are applied on top of the default v1 or v2 options,
+	// but underneath any options specified by the caller,
+	// and are therefore also reported in [Difference.CallerOptions].
+	// Options for specific types may be registered with [RegisterTypeOptions].
+	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+	DefaultOptions jsonv2.Options
+
+	// V1Implementation specifies which implementation of v1 is called.
+	// By default, it is [AutoSelectV1].
+	V1Implementation V1Implementation
+
 	// AutoDetectOptions specifies whether to automatically detect which
 	// [jsontext], [jsonv1], or [jsonv2] options are needed to preserve
 	// identical behavior between v1 and v2 once a difference has been detected.
+	// For marshal, this includes formatting options (e.g., [jsontext.Multiline])
+	// such that purely cosmetic differences are attributed as such.
 	//
 	// Auto-detection is relatively slow and will need to run marshal/unmarshal
-	// many extra times. In performance sensitive systems,
+	// extra times, which grows logarithmically with the number of probed options
+	// for each detected option. In performance sensitive systems,
 	// configure [Codec.SetMarshalCallRatio] and [Codec.SetUnmarshalCallRatio]
 	// such that [CallBothButReturnV1] or [CallBothButReturnV2] call modes
 	// occur with relatively low probability.
+	// If the individually detected options do not resolve the difference,
+	// then combinations of up to two additional options are searched,
+	// which is quadratic in the number of probed options.
+	// Differences that remain unresolved are marked by
+	// [Difference.OptionsIncomplete].
+	// Options that resolve the difference are then reduced to a minimal set
+	// by dropping any option that is redundant given the others,
+	// which runs marshal/unmarshal once more for each detected option.
 	AutoDetectOptions bool
 
+	// AutoDetectParallelism is the maximum number of concurrent marshal
+	// or unmarshal calls used to probe options during auto-detection.
+	// If zero or one, all probes run sequentially within the calling goroutine.
+	// If negative, it uses [runtime.GOMAXPROCS].
+	// If greater than one, then [Codec.EqualJSONValues], [Codec.EqualGoValues],
+	// [Codec.EqualErrors], and [Codec.CloneGoValue] must be safe for
+	// concurrent use, and the Go value being marshaled must be safe to
+	// marshal concurrently (e.g., has no racy MarshalJSON methods).
+	AutoDetectParallelism int
+
+	// MaxDetectionsPerMinute is the maximum number of differences
+	// within any one minute window for which [Codec.AutoDetectOptions]
+	// runs detection, which bounds the CPU spent on detection during
+	// an incident where differences suddenly occur on every call.
+	// Differences beyond the budget are still reported, but without
+	// detected options, and are counted in
+	// [CodecMetrics.NumAutoDetectBudgetExceeded].
+	// If zero or negative, the number of detections is unlimited.
+	MaxDetectionsPerMinute int
+
+	// MaxDetectionsPerType is like [Codec.MaxDetectionsPerMinute],
+	// but limits the number of detections for each Go type
+	// within any one minute window so that a single hot type
+	// cannot consume the entire budget.
+	// If zero or negative, the number of detections is unlimited.
+	MaxDetectionsPerType int
+
+	// AutoApplyDetectedOptions specifies whether options detected by
+	// [Codec.AutoDetectOptions] to resolve a difference for a Go type
+	// are automatically appended to the options of all subsequent
+	// v2 calls for that type. This allows for a self-healing rollout,
+	// where a difference is reported once and then the behavior of v2
+	// converges on that of v1. Only options that fully resolve a difference
+	// are applied and they are counted in [CodecMetrics.NumAutoApplied].
+	// Options are applied based on the dynamic type of the top-level value,
+	// such that calls for the same type with different caller options
+	// share the same detected options.
+	AutoApplyDetectedOptions bool
+
 	// ReportDifference is a custom function to report detected differences
-	// in marshal or unmarshal. If nil, structured differences are ignored.
+	// in marshal or unmarshal. If nil, structured differences are ignored
+	// unless there are handlers added by [Codec.AddDifferenceHandler]
+	// or subscribers to [Codec.Differences].
 	// The fields in [Difference] alias the call arguments for marshal/unmarshal
 	// and should therefore avoid leaking beyond the function call.
 	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
 	ReportDifference func(Difference)
 
+	// ReportEmulationRegression is a custom function to report differences
+	// where [Codec.AutoDetectOptions] found that v2 configured with
+	// [jsonv1.DefaultOptionsV1] still behaves differently from v1.
+	// This indicates a regression in how v2 emulates v1 (rather than a
+	// difference resolvable by some options) and should be reported upstream.
+	// The difference has a Check of "EmulationRegression" and
+	// is also reported to [Codec.ReportDifference] without any options.
+	// If nil, emulation regressions are only counted in
+	// [CodecMetrics.NumEmulationRegressions].
+	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+	ReportEmulationRegression func(Difference)
+
+	// ReportSampleRate is the fraction (within 0 and 1) of detected
+	// differences that are attributed by [Codec.AutoDetectOptions] and
+	// passed to [Codec.ReportDifference] and [Codec.ReportEmulationRegression].
+	// Counters of detected differences (e.g., [CodecMetrics.NumMarshalDiffs])
+	// remain exact, while the expensive detection and reporting
+	// is only performed for a sample of them.
+	// The option histograms and [CodecMetrics.NumEmulationRegressions]
+	// only account for sampled differences.
+	// Skipped differences are counted in [CodecMetrics.NumDifferencesUnsampled].
+	// If zero (or at least one), every difference is reported.
+	ReportSampleRate float64
+
+	// CopyDifferenceValues specifies that all JSON and Go values
+	// in a [Difference] are deeply copied before being passed to
+	// [Codec.ReportDifference] such that they may be safely retained
+	// beyond the function call (e.g., sent to another goroutine).
+	// Copying is relatively expensive and unexported struct fields
+	// are only shallow copied.
+	CopyDifferenceValues bool
+
+	// AsyncReportQueueSize is the capacity of a queue of differences
+	// that are passed to [Codec.ReportDifference]
+	// (and [Codec.ReportEmulationRegression]) by a background goroutine
+	// so that slow reporting does not add latency to marshal and unmarshal calls.
+	// Differences reported while the queue is full are dropped and
+	// counted in [CodecMetrics.NumDifferencesDropped].
+	// Only sampling (see [Codec.ReportSampleRate]) and copying the values
+	// of a difference occur synchronously, while detection by
+	// [Codec.AutoDetectOptions] and populating the other fields of
+	// [Difference] occur on the background goroutine.
+	// Use [Codec.FlushReports] to wait for queued differences to be reported
+	// and [Codec.Close] to also stop the background goroutine.
+	// If zero or negative, differences are reported synchronously.
+	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+	AsyncReportQueueSize int
+
+	// DiffGoValues specifies that [Difference.GoValueDiff] is populated
+	// with a human-readable diff of the Go values so that differences
+	// can be triaged without reproducing the call locally.
+	// Diffing is relatively expensive and the diff may be large.
+	DiffGoValues bool
+
+	// KnownDifferences is an optional set of fingerprints of differences
+	// that have already been reported. If non-nil, a detected difference
+	// is only passed to [Codec.ReportDifference] if its [Difference.Fingerprint]
+	// is not yet in the set, upon which the fingerprint is added.
+	// The set may be persisted across restarts using
+	// [DifferenceSet.WriteTo] and [DifferenceSet.ReadFrom].
+	KnownDifferences *DifferenceSet
+
+	// MaxReportsPerDifference is the maximum number of times that
+	// differences with the same [Difference.Fingerprint] are passed to
+	// [Codec.ReportDifference] (or [Codec.ReportEmulationRegression])
+	// so that a single frequently used Go type does not flood the reports.
+	// Subsequent repeats are only counted in
+	// [CodecMetrics.NumDifferencesRepeatSuppressed].
+	// If zero or negative, every difference is reported.
+	MaxReportsPerDifference int
+
+	// ReportSeverities is the set of severities of differences that are
+	// passed to [Codec.ReportDifference] (or [Codec.ReportEmulationRegression])
+	// so that cosmetic differences do not drown out semantic ones
+	// (e.g., []Severity{SeveritySemantic, SeverityErrorOnly} to ignore
+	// differences of [SeverityFormattingOnly]).
+	// All differences are still counted in [CodecMetrics.SeverityHistogram],
+	// while those of other severities are only counted in
+	// [CodecMetrics.NumDifferencesSeverityFiltered].
+	// If empty, differences of every severity are reported.
+	ReportSeverities []Severity
+
+	// VerifyCrossDecode specifies whether marshal calls that compare v1 and v2
+	// also verify that the returned JSON output can be unmarshaled by
+	// the other implementation into a new value of the same Go type
+	// (e.g., that v2 output is accepted by v1), which guards downstream
+	// consumers that may still be using the other implementation.
+	// A failure is reported as a [Difference] with a Check of "CrossDecode",
+	// where the error is from the implementation that rejected the output.
+	// It is not reported if the output is also rejected by the same implementation.
+	VerifyCrossDecode bool
+
+	// VerifyRoundTrip specifies whether marshal calls that compare v1 and v2
+	// also verify that the output of each implementation can be unmarshaled
+	// back by the same implementation into a new value equal to the input
+	// according to [Codec.EqualGoValues] and [Codec.Transformers].
+	// This surfaces lossy round-trips (e.g., time truncation) that are not
+	// visible by comparing the outputs of v1 and v2 with each other.
+	// A lossy round-trip is reported as a [Difference] with a Check of
+	// "RoundTrip", where only the fields for the lossy implementation
+	// are populated with the JSON output and the round-tripped Go value.
+	// Since unexported fields are never unmarshaled, types with
+	// non-zero unexported fields never round-trip with [reflect.DeepEqual].
+	VerifyRoundTrip bool
+
+	// VerifyInterop specifies whether marshal calls that compare v1 and v2
+	// also verify that unmarshaling the v1 output with v2 and
+	// unmarshaling the v2 output with v1 produce equal Go values
+	// according to [Codec.EqualGoValues] and [Codec.Transformers].
+	// This detects asymmetric incompatibilities that matter during
+	// mixed-version rollouts where a producer and consumer
+	// use different implementations.
+	// An incompatibility is reported as a [Difference] with a Check of
+	// "Interop", where GoValueV1 and ErrorV1 are the result of unmarshaling
+	// JSONValueV2 with v1 and GoValueV2 and ErrorV2 are the result of
+	// unmarshaling JSONValueV1 with v2.
+	// It is only verified if both v1 and v2 marshal successfully.
+	VerifyInterop bool
+
+	// VerifyStandardV1 specifies whether marshal and unmarshal calls that
+	// compare v1 and v2 also call the standard library [jsonv1std]
+	// and verify that it produces the same result as the
+	// emulation of v1 in [jsonv1] (which is implemented in terms of v2).
+	// Together with the comparison of v1 and v2, this is a three-way
+	// comparison that distinguishes a difference where v2 changed behavior
+	// from a difference where the emulation of v1 is incorrect.
+	// A divergence between [jsonv1std] and [jsonv1] is reported as a
+	// [Difference] with a Check of "StandardV1", where the v1 fields
+	// are the result of [jsonv1std] and the v2 fields are the result of [jsonv1].
+	// Since [jsonv1std] does not support options, it is only verified
+	// for calls without any options (including [Codec.DefaultOptions]).
+	VerifyStandardV1 bool
+
+	// TranslateV2Errors specifies whether errors from v2 are translated
+	// into the equivalent error types reported by [jsonv1] whenever
+	// the result of v2 is returned (e.g., [CallBothButReturnV2]),
+	// so that existing error handling that type asserts on
+	// [jsonv1.SyntaxError] or [jsonv1.UnmarshalTypeError] keeps working.
+	// For example, a [jsonv2.SemanticError] from unmarshal is translated
+	// into a [jsonv1.UnmarshalTypeError] with the Offset, Struct, Field,
+	// and Type populated in the same way as [jsonv1.Unmarshal].
+	// Errors without a v1 equivalent are returned unchanged.
+	// Differences always report the untranslated error in [Difference.ErrorV2].
+	TranslateV2Errors bool
+
+	// Transformers is a list of transformers to apply in order to
+	// both the v1 and v2 results before they are compared
+	// with [Codec.EqualJSONValues] or [Codec.EqualGoValues].
+	// Transformers allow normalizations to be layered
+	// (e.g., rounding timestamps and scrubbing identifiers)
+	// without replacing the equality functions.
+	// The reported [Difference] contains the untransformed values.
+	Transformers []Transformer
+
 	// EqualJSONValues is a custom function to compare JSON values after marshal.
 	// If nil, it uses [bytes.Equal].
 	EqualJSONValues func(jsontext.Value, jsontext.Value) bool
 
+	// StreamMarshalComparison specifies whether [Codec.Marshal] with
+	// [CallBothButReturnV1] streams the v2 output through an incremental
+	// comparison against the v1 output rather than
+	// materializing the entire v2 output.
+	// This avoids allocating a second buffer when the outputs are identical,
+	// while the v2 output from the first divergence onwards is buffered
+	// to obtain the complete output for reporting.
+	// If v2 fails, only the output written before the error is reported.
+	// It has no effect if [Codec.EqualJSONValues] or
+	// [Codec.Transformers] is set, which need the complete output,
+	// nor on [Codec.MarshalAppend], which marshals directly into its buffer.
+	StreamMarshalComparison bool
+
 	// EqualGoValues is a custom function to compare Go values after unmarshal.
 	// If nil, it uses [reflect.DeepEqual].
 	EqualGoValues func(any, any) bool
 
+	// HashGoValuesAboveSize is the JSON input size in bytes above which
+	// Go values after unmarshal are compared by hashing a canonical
+	// re-marshal of each value with v2 (rather than with [Codec.EqualGoValues]),
+	// which is dramatically cheaper for multi-megabyte structures.
+	// Hashing is less precise: unexported fields and values without
+	// a JSON representation are not compared, and values that cannot be
+	// marshaled are compared with [Codec.EqualGoValues] instead.
+	// If zero or negative, Go values are never compared by hashing.
+	HashGoValuesAboveSize int
+
 	// EqualErrors is a custom function to compare errors from marshal or unmarshal.
 	// If nil, it only checks whether the errors are both non-nil or both nil.
 	EqualErrors func(error, error) bool
 
+	// ShouldCompareMarshal is a custom function that reports whether
+	// a given Go value provided to marshal is worth comparing between v1 and v2.
+	// If it reports false, then [CallBothButReturnV1] is treated as [OnlyCallV1]
+	// and [CallBothButReturnV2] is treated as [OnlyCallV2].
+	// The [CallV1ButUponErrorReturnV2] and [CallV2ButUponErrorReturnV1] modes
+	// are unaffected since calling the other implementation affects the result.
+	// It is only called for modes that call both v1 and v2.
+	// If nil, all values are compared.
+	ShouldCompareMarshal func(any) bool
+
+	// ShouldCompareUnmarshal is a custom function that reports whether
+	// a given JSON value provided to unmarshal is worth comparing between v1 and v2.
+	// It affects call modes in the same way as [Codec.ShouldCompareMarshal].
+	// The JSON value must not be retained beyond the function call.
+	// If nil, all values are compared.
+	ShouldCompareUnmarshal func(jsontext.Value) bool
+
+	// CompareSizeProbability is a custom function that reports the probability
+	// (within 0 and 1) that a call in [CallBothButReturnV1] or [CallBothButReturnV2]
+	// mode actually compares v1 and v2 given the size of the JSON value.
+	// For unmarshal, the size is of the JSON input.
+	// For marshal, the size is of the JSON output from the implementation
+	// whose result is returned, which is therefore called first.
+	// Unsampled calls are treated as [OnlyCallV1] or [OnlyCallV2].
+	// This allows comparing cheap small payloads while only occasionally
+	// paying the cost to compare large ones (e.g., see [InverseSizeProbability]).
+	// It is only called for modes that call both v1 and v2.
+	// If nil, all calls are compared regardless of size.
+	CompareSizeProbability func(size int) float64
+
+	// IgnoreGoValuesOnErrors specifies that if both the v1 and v2 unmarshal
+	// calls report an error, then only the errors are compared and
+	// the partially populated Go values are ignored.
+	// Since v1 and v2 legitimately differ in how much of the Go value
+	// is populated before failing, this avoids reporting noisy differences.
+	IgnoreGoValuesOnErrors bool
+
 	// CloneGoValue is a custom function to deeply clone an arbitrary Go value
 	// for use as the output for calling unmarshal.
 	// If nil (or the function returns nil), then it clones any
 	// pointers to a zero'd value by simply allocating a new one.
 	CloneGoValue func(v any) any
 
+	// CallerSkipPrefixes is a list of function name prefixes
+	// (e.g., "example.com/project/internal/jsonutil.") where any frames
+	// within matching functions are skipped when deriving [Difference.Caller].
+	// This is similar to [Codec.Helper], but applies to entire packages
+	// such as shared wrappers around marshal and unmarshal.
+	// Function names are fully qualified by the package path.
+	CallerSkipPrefixes []string
+
+	// TrackCallerDiffRates enables [CodecMetrics.MarshalCallerDiffRates]
+	// and [CodecMetrics.UnmarshalCallerDiffRates].
+	// It is disabled by default since it derives the caller
+	// (see [Difference.Caller]) of every call that compares v1 and v2,
+	// rather than only of calls that detect a difference.
+	TrackCallerDiffRates bool
+
+	// ReportSummary is a custom function that is called by [Codec.Close]
+	// with a final snapshot of all metrics. If nil, no summary is reported.
+	ReportSummary func(Snapshot)
+
+	// SaveState is a custom function that is called by [Codec.Close]
+	// after all queued differences have been reported and all sinks
+	// added by [Codec.AddSink] have been closed, so that the state
+	// learned by the codec can be persisted across restarts
+	// (e.g., the call modes in [Codec.ConfigJSON], the options in
+	// [Codec.DetectedOptionsByType], or the fingerprints in
+	// [Codec.KnownDifferences] as written by [DifferenceSet.WriteTo]).
+	// Any error is returned by Close. If nil, no state is saved.
+	SaveState func(*Codec) error
+
+	// MetricsBackend is an optional backend that receives every update
+	// to the metrics in [CodecMetrics] as it occurs.
+	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+	MetricsBackend MetricsBackend
+
+	// RollUpMetrics specifies whether every update to the metrics
+	// of a codec created by [Codec.NewChild] is also applied to
+	// the metrics of its parent (and recursively to any ancestors
+	// that also roll up their metrics), such that the parent
+	// reports the aggregate of itself and all of its children.
+	// It has no effect on a codec without a parent.
+	// Must be set before any [Codec.Marshal] or [Codec.Unmarshal] calls.
+	RollUpMetrics bool
+
+	// parent is the codec that this codec was created from by [Codec.NewChild].
+	parent *Codec
+
+	// children weakly references the codecs created from this codec
+	// by [Codec.NewChild] such that they are notified of configuration changes.
+	children sync.Map // map[weak.Pointer[Codec]]struct{}
+
+	// parentMetrics caches the metric in the parent for each metric
+	// in this codec when [Codec.RollUpMetrics] is enabled.
+	parentMetrics sync.Map // map[expvar.Var]expvar.Var
+
 	marshalCallRatio   callModeRatio
 	unmarshalCallRatio callModeRatio
 
+	// goValueOmitRatio is the float32 bits of 1 minus the ratio
+	// specified by [Codec.SetGoValueCaptureRatio],
+	// such that the zero value means to always capture Go values.
+	goValueOmitRatio atomic.Uint32
+	// goValueOmitRatioSet reports whether goValueOmitRatio was set,
+	// otherwise it is resolved through the parent (if any).
+	goValueOmitRatioSet atomic.Bool
+
 	CodecMetrics
 
+	// metricNames caches the name of each metric for [Codec.MetricsBackend].
+	metricNames sync.Map // map[expvar.Var]string
+
+	// reportCounts counts the number of reported differences
+	// per fingerprint for [Codec.MaxReportsPerDifference].
+	reportCounts sync.Map // map[uint64]*atomic.Int64
+
+	// reportQueue is the queue for [Codec.AsyncReportQueueSize],
+	// which is lazily started upon the first queued report
+	// and stopped by [Codec.Close]. The background goroutine
+	// closes reportQueueDone once the queue is closed and drained.
+	reportQueueMu   sync.RWMutex
+	reportQueue     chan func()
+	reportQueueDone chan struct{}
+
+	// handlers is the copy-on-write list of handlers
+	// added by [Codec.AddDifferenceHandler].
+	handlersMu sync.Mutex
+	handlers   atomic.Pointer[[]*DifferenceHandler]
+
+	// detectionBudget tracks [Codec.MaxDetectionsPerMinute]
+	// and [Codec.MaxDetectionsPerType].
+	detectionBudget detectionBudget
+
+	// subscribers is the set of channels for [Codec.Differences].
+	subscribers    sync.Map // map[chan Difference]bool
+	numSubscribers atomic.Int32
+
+	// typeStates caches the state for each Go type
+	// operated upon by [MarshalFor] and [UnmarshalFor].
+	typeStates sync.Map // map[reflect.Type]*typeState
+
+	// detectedByType tracks [Codec.DetectedOptionsByType].
+	detectedByType detectedByType
+
+	// registeredOptions holds the options registered by [RegisterTypeOptions].
+	registeredOptions sync.Map // map[reflect.Type]jsonv2.Options
+	// typeOptionsGen is incremented by [RegisterTypeOptions]
+	// to invalidate the registered options cached in each typeState.
+	typeOptionsGen atomic.Uint64
+
+	// appliedOptions holds the options to apply to v2 calls
+	// for [Codec.AutoApplyDetectedOptions].
+	appliedOptions sync.Map // map[opTypeKey]jsonv2.Options
+
+	// unexplained is the set of operations on Go types for which
+	// [searchOptionCombinations] found no options that resolve a difference.
+	unexplained sync.Map // map[opTypeKey]struct{}
+
 	// helperCallers is the set of PCs that called [Codec.Helper].
 	// It is used as a cache to avoid fetching the [runtime.Frame],
 	// so that repeated calls to [Codec.Helper] remain fast.
@@ -281,6 +803,77 @@ type Codec struct {
 	// each function that called [Codec.Helper].
 	// This is what is actually used to elide frames in [Caller].
 	helperEntries sync.Map // map[uintptr]struct{}
+
+	// flushHooks is the set of background workers (e.g., [Codec.StartPusher])
+	// that need to be flushed by [Codec.Flush] and stopped by [Codec.Close].
+	flushHooksMu sync.Mutex
+	flushHooks   map[*flushHook]struct{}
+
+	// configWatchers is the set of functions registered by [Codec.WatchConfig].
+	configWatchersMu sync.Mutex
+	configWatchers   map[*configWatcher]struct{}
+}
+
+// flushHook is a registered background worker (or sink).
+type flushHook struct {
+	flush func() error // emits any buffered data
+	close func() error // stops the worker
+}
+
+func (c *Codec) addFlushHook(h *flushHook) {
+	c.flushHooksMu.Lock()
+	defer c.flushHooksMu.Unlock()
+	if c.flushHooks == nil {
+		c.flushHooks = make(map[*flushHook]struct{})
+	}
+	c.flushHooks[h] = struct{}{}
+}
+
+func (c *Codec) removeFlushHook(h *flushHook) {
+	c.flushHooksMu.Lock()
+	defer c.flushHooksMu.Unlock()
+	delete(c.flushHooks, h)
+}
+
+// Flush synchronously flushes all data buffered by background workers
+// (e.g., causing every pusher started by [Codec.StartPusher] to push).
+func (c *Codec) Flush() error {
+	c.flushHooksMu.Lock()
+	hooks := slices.Collect(maps.Keys(c.flushHooks))
+	c.flushHooksMu.Unlock()
+	var errs []error
+	for _, h := range hooks {
+		errs = append(errs, h.flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close reports all queued differences (see [Codec.AsyncReportQueueSize]),
+// flushes and stops all background workers, closes all sinks added by
+// [Codec.AddSink], saves the state with [Codec.SaveState],
+// and then reports a final summary to [Codec.ReportSummary].
+// It returns all errors encountered along the way.
+// It is intended to be called upon graceful shutdown
+// so that short-lived programs do not silently drop data.
+// The codec remains usable for marshal and unmarshal after Close,
+// but no background workers remain running.
+func (c *Codec) Close() error {
+	c.stopReportQueue()
+	c.flushHooksMu.Lock()
+	hooks := slices.Collect(maps.Keys(c.flushHooks))
+	clear(c.flushHooks)
+	c.flushHooksMu.Unlock()
+	var errs []error
+	for _, h := range hooks {
+		errs = append(errs, h.flush(), h.close())
+	}
+	if c.SaveState != nil {
+		errs = append(errs, c.SaveState(c))
+	}
+	if c.ReportSummary != nil {
+		c.ReportSummary(c.CodecMetrics.Snapshot())
+	}
+	return errors.Join(errs...)
 }
 
 // CodecMetrics contains metrics about marshal and unmarshal calls.
@@ -308,6 +901,26 @@ type CodecMetrics struct {
 	// NumMarshalDiffs is the number of times that [Codec.Marshal] detected
 	// a difference between the outputs of [jsonv1.Marshal] and [jsonv2.Marshal].
 	NumMarshalDiffs expvar.Int
+	// NumMarshalCrossDecodeErrors is the number of times that [Codec.Marshal]
+	// returned output that could not be unmarshaled by the other implementation.
+	// It is only populated if [Codec.VerifyCrossDecode] is enabled.
+	NumMarshalCrossDecodeErrors expvar.Int
+	// NumMarshalRoundTripDiffs is the number of times that the output of
+	// [jsonv1.Marshal] or [jsonv2.Marshal] could not be unmarshaled back
+	// by the same implementation into a value equal to the input.
+	// It is only populated if [Codec.VerifyRoundTrip] is enabled.
+	NumMarshalRoundTripDiffs expvar.Int
+	// NumMarshalInteropDiffs is the number of times that unmarshaling
+	// the output of [jsonv1.Marshal] with [jsonv2.Unmarshal] and
+	// the output of [jsonv2.Marshal] with [jsonv1.Unmarshal]
+	// produced different Go values.
+	// It is only populated if [Codec.VerifyInterop] is enabled.
+	NumMarshalInteropDiffs expvar.Int
+	// NumMarshalStandardV1Diffs is the number of times that
+	// [jsonv1std.Marshal] and [jsonv1.Marshal] produced different outputs.
+	// It is only populated if [Codec.VerifyStandardV1] is enabled
+	// or calls use [CallBothV1StdAndV1Emulated].
+	NumMarshalStandardV1Diffs expvar.Int
 
 	// ExecTimeMarshalV1Nanos is the total number of nanoseconds
 	// spent in a [jsonv1.Marshal] call when comparing both v1 and v2.
@@ -327,6 +940,10 @@ type CodecMetrics struct {
 	// MarshalOptionHistogram is a histogram of JSON options
 	// that could be specified to [Codec.Marshal] to avoid a difference.
 	MarshalOptionHistogram expvar.Map
+	// MarshalCallerDiffRates is the per-caller rate of differences
+	// among [Codec.Marshal] calls that called both v1 and v2
+	// (if [Codec.TrackCallerDiffRates] is enabled).
+	MarshalCallerDiffRates CallerDiffRates
 
 	// NumUnmarshalTotal is the total number of [Codec.Unmarshal] calls.
 	NumUnmarshalTotal expvar.Int
@@ -361,6 +978,15 @@ type CodecMetrics struct {
 	// as inability to check for differences is treated as a difference
 	// to avoid false assurance that there are no differences.
 	NumUnmarshalDiffs expvar.Int
+	// NumUnmarshalTokenDiffs is the number of [Decoder] token streams
+	// where [Decoder.Token] detected a divergence between the tokens
+	// produced by [jsonv1std.Decoder] and [jsontext.Decoder].
+	NumUnmarshalTokenDiffs expvar.Int
+	// NumUnmarshalStandardV1Diffs is the number of times that
+	// [jsonv1std.Unmarshal] and [jsonv1.Unmarshal] produced different outputs.
+	// It is only populated if [Codec.VerifyStandardV1] is enabled
+	// or calls use [CallBothV1StdAndV1Emulated].
+	NumUnmarshalStandardV1Diffs expvar.Int
 
 	// ExecTimeUnmarshalV1Nanos is the total number of nanoseconds
 	// spent in a [jsonv1.Unmarshal] call when comparing both v1 and v2.
@@ -378,16 +1004,150 @@ type CodecMetrics struct {
 	// UnmarshalOptionHistogram is a histogram of JSON options
 	// that could be specified to [Codec.Unmarshal] to avoid a difference.
 	UnmarshalOptionHistogram expvar.Map
+	// UnmarshalCallerDiffRates is the per-caller rate of differences
+	// among [Codec.Unmarshal] calls that attempted to call both v1 and v2
+	// (if [Codec.TrackCallerDiffRates] is enabled).
+	UnmarshalCallerDiffRates CallerDiffRates
+
+	// NumAutoDetectResolved is the number of detected differences
+	// where [Codec.AutoDetectOptions] found a set of options
+	// that fully resolves the difference between v1 and v2.
+	NumAutoDetectResolved expvar.Int
+	// NumAutoDetectUnexplained is the number of detected differences
+	// where [Codec.AutoDetectOptions] found no set of options
+	// that fully resolves the difference between v1 and v2
+	// (e.g., no options were detected, or only some of the
+	// behavior differences are attributable to known options).
+	// A high count relative to [CodecMetrics.NumAutoDetectResolved]
+	// suggests that the option histograms are incomplete.
+	NumAutoDetectUnexplained expvar.Int
+	// NumAutoDetectCombinations is the number of detected differences
+	// counted in [CodecMetrics.NumAutoDetectResolved] that were only
+	// resolved by searching for combinations of options
+	// since no individually significant option explained the difference.
+	NumAutoDetectCombinations expvar.Int
+	// NumAutoDetectReduced is the number of detected differences
+	// counted in [CodecMetrics.NumAutoDetectResolved] where
+	// some of the detected options were dropped as redundant
+	// given the other detected options (see [Codec.AutoDetectOptions]).
+	NumAutoDetectReduced expvar.Int
+	// NumAutoDetectBudgetExceeded is the number of detected differences
+	// for which [Codec.AutoDetectOptions] did not run detection since
+	// [Codec.MaxDetectionsPerMinute] or [Codec.MaxDetectionsPerType]
+	// was exceeded.
+	NumAutoDetectBudgetExceeded expvar.Int
+	// NumAutoApplied is the number of marshal and unmarshal calls
+	// for which previously detected options were appended to the v2 options
+	// according to [Codec.AutoApplyDetectedOptions].
+	NumAutoApplied expvar.Int
+	// NumEmulationRegressions is the number of detected differences
+	// where v2 configured with [jsonv1.DefaultOptionsV1] still behaved
+	// differently from v1 (see [Codec.ReportEmulationRegression]),
+	// such that detection failed (see [Difference.DetectionFailed]).
+	// It is only populated if [Codec.AutoDetectOptions] is enabled.
+	NumEmulationRegressions expvar.Int
+	// CallModeCounters contains counts of calls and detected differences
+	// keyed by operation and [CallMode] name
+	// (e.g., "Marshal/CallBothButReturnV1/calls" for the number of
+	// [Codec.Marshal] calls that selected [CallBothButReturnV1], and
+	// "Marshal/CallBothButReturnV1/diffs" for the number of those calls
+	// that detected a difference) so that they can be iterated generically.
+	// Formatting functions are counted under their own operation names
+	// (e.g., "Compact/OnlyCallV1/calls" for [Codec.Compact]).
+	// The mode is the one selected for each call before any fallback
+	// (e.g., [CallV1ButUponErrorReturnV2] is counted even if v1 succeeds).
+	CallModeCounters expvar.Map
+
+	// SeverityHistogram is a histogram of the severities of
+	// detected differences keyed by [Severity] name (e.g., "FormattingOnly")
+	// regardless of whether they are sampled by [Codec.ReportSampleRate],
+	// suppressed as known or repeated differences,
+	// or filtered by [Codec.ReportSeverities].
+	SeverityHistogram expvar.Map
+	// NumDifferencesSeverityFiltered is the number of detected differences
+	// that were not passed to [Codec.ReportDifference]
+	// since their severity is not in [Codec.ReportSeverities].
+	NumDifferencesSeverityFiltered expvar.Int
+	// NumDifferencesSuppressed is the number of detected differences
+	// that were not passed to [Codec.ReportDifference]
+	// since they were already present in [Codec.KnownDifferences].
+	NumDifferencesSuppressed expvar.Int
+	// NumDifferencesRepeatSuppressed is the number of detected differences
+	// that were not passed to [Codec.ReportDifference] since differences
+	// with the same fingerprint were already reported
+	// [Codec.MaxReportsPerDifference] times.
+	NumDifferencesRepeatSuppressed expvar.Int
+	// NumDifferencesUnsampled is the number of detected differences
+	// that were neither attributed nor reported
+	// according to [Codec.ReportSampleRate].
+	NumDifferencesUnsampled expvar.Int
+	// NumDifferencesDropped is the number of detected differences
+	// that were not reported since the queue for
+	// [Codec.AsyncReportQueueSize] was full.
+	NumDifferencesDropped expvar.Int
+	// NumDifferencesUndelivered is the number of reported differences
+	// that were not delivered to a subscriber of [Codec.Differences]
+	// since it was not keeping up.
+	NumDifferencesUndelivered expvar.Int
+	// DifferenceSizeHistogram is a histogram of the estimated sizes of the
+	// JSON representation (see [Difference.MarshalJSON]) of every difference
+	// passed to [Codec.ReportDifference] or [Codec.ReportEmulationRegression],
+	// which approximates the log or storage volume generated by reporting.
+	// Sizes are estimated from the lengths of the populated fields
+	// without marshaling the difference.
+	DifferenceSizeHistogram SizeHistogram
+}
+
+// FieldDifference is a struct field whose value differs between
+// [Difference.GoValueV1] and [Difference.GoValueV2].
+type FieldDifference struct {
+	// Field is the Go name of the struct field (e.g., "Aliases").
+	Field string
+	// ValueV1 is the field value populated by v1 formatted as Go syntax
+	// (e.g., `[]string(nil)`), where pointers are dereferenced.
+	ValueV1 string
+	// ValueV2 is the field value populated by v2 formatted as Go syntax
+	// (e.g., `[]string{}`), where pointers are dereferenced.
+	ValueV2 string
 }
 
 // Difference is a structured representation of the difference detected
 // between the outputs of a v1 and v2 marshal or unmarshal call.
 type Difference struct {
+	// ID uniquely identifies this difference as a ULID
+	// (e.g., "01J2Z3Q4R5S6T7V8W9X0Y1Z2A3"), which is lexically sortable
+	// by the time the difference was reported.
+	// It allows correlating multiple records of the same event
+	// (e.g., a log line, a recorded entry, and a metrics exemplar).
+	ID string `json:",omitzero"`
 	// Caller is the function name and relative line offset of the caller.
 	// For example, "path/to/package.Function+123".
+	// It is instead the caller label if one was provided
+	// (see [WithCallerLabel] and [Codec.WithCallerLabel]),
+	// or the caller of [Codec.Wrap] for wrapped values.
 	Caller string `json:",omitzero"`
-	// Func is the operation and is either "Marshal" or "Unmarshal".
+	// Labels are the profiler labels (see [runtime/pprof.Labels]) carried by
+	// the context of the call (e.g., request IDs or tenant tags)
+	// so that differences can be correlated with specific traffic.
+	// They are only available for calls that provide a context
+	// (e.g., [Codec.MarshalContext] and [Codec.UnmarshalContext]).
+	Labels map[string]string `json:",omitzero"`
+	// Func is the operation and is either "Marshal" or "Unmarshal",
+	// or the name of a formatting function (e.g., "Compact" for [Codec.Compact]).
 	Func string `json:",omitzero"`
+	// Severity classifies how consequential the difference is.
+	// It is populated when reported.
+	Severity Severity `json:",omitzero"`
+	// Check is the name of the additional verification that detected
+	// the difference (e.g., "CrossDecode" for [Codec.VerifyCrossDecode],
+	// "RoundTrip" for [Codec.VerifyRoundTrip],
+	// "Interop" for [Codec.VerifyInterop],
+	// "StandardV1" for [Codec.VerifyStandardV1] or [CallBothV1StdAndV1Emulated],
+	// "EmulationRegression" for [Codec.ReportEmulationRegression], or
+	// "Token" for [Decoder.Token], where GoValueV1 and GoValueV2
+	// are the first tokens that diverged).
+	// It is empty if the difference is between the results of v1 and v2.
+	Check string `json:",omitzero"`
 	// GoType is the Go type being operated upon.
 	GoType reflect.Type `json:",omitzero"`
 
@@ -404,16 +1164,123 @@ type Difference struct {
 	GoValueV1 any `json:"-"`
 	// GoValueV2 is the output Go value populated by a v2 unmarshal call.
 	GoValueV2 any `json:"-"`
+	// GoValuePath is the path to the first divergent Go value
+	// between GoValueV1 and GoValueV2 for an unmarshal call
+	// (e.g., "User.Aliases[2]"). It is empty if the difference
+	// is only in the errors or could not be located.
+	GoValuePath string `json:",omitzero"`
+	// FieldDifferences are the fields that differ between
+	// GoValueV1 and GoValueV2 if they are (pointers to) struct values,
+	// which surfaces the typical difference in a single field directly.
+	// Unlike GoValueV1 and GoValueV2, it is retained when serialized
+	// or when the Go values are omitted (see [Codec.SetGoValueCaptureRatio]).
+	FieldDifferences []FieldDifference `json:",omitzero"`
+	// GoValueDiff is a human-readable diff from GoValueV1 to GoValueV2
+	// (as reported by [github.com/google/go-cmp/cmp.Diff]) if [Codec.DiffGoValues] is enabled.
+	// The format is not stable and must not be parsed.
+	// Unlike GoValueV1 and GoValueV2, it is retained when serialized
+	// or when the Go values are omitted (see [Codec.SetGoValueCaptureRatio]).
+	GoValueDiff string `json:",omitzero"`
+	// InputOffset is the byte offset into the input stream
+	// just before the first divergent token for a "Token" check.
+	InputOffset int64 `json:",omitzero"`
+	// JSONValuePointer is the JSON pointer to the first divergent token
+	// between JSONValueV1 and JSONValueV2 (e.g., "/Users/2/Name"),
+	// as determined by walking both token streams in lock step.
+	// It is empty if the outputs diverge at the top-level value.
+	// It is only populated when reported if both outputs are non-empty.
+	JSONValuePointer jsontext.Pointer `json:",omitzero"`
+	// JSONValueOffset is the byte offset into JSONValueV1
+	// just before the first divergent token.
+	JSONValueOffset int64 `json:",omitzero"`
 
 	// ErrorV1 is the error produced by a v1 marshal/unmarshal call.
 	ErrorV1 error `json:",omitzero"`
 	// ErrorV2 is the error produced by a v2 marshal/unmarshal call.
 	ErrorV2 error `json:",omitzero"`
 
+	// CallerOptions is the set of options explicitly passed by the caller
+	// to the marshal/unmarshal call, including any [Codec.DefaultOptions].
+	// These options were already in effect for both v1 and v2,
+	// as opposed to Options, which still need to be enabled.
+	CallerOptions jsonv2.Options `json:",omitzero"`
+	// OptionsV1 is the full set of options in effect for the v1 call,
+	// which is the v1 default options merged with CallerOptions.
+	OptionsV1 jsonv2.Options `json:",omitzero"`
+	// OptionsV2 is the full set of options in effect for the v2 call,
+	// which is the v2 default options merged with CallerOptions
+	// and any options applied by [Codec.AutoApplyDetectedOptions].
+	OptionsV2 jsonv2.Options `json:",omitzero"`
+	// StandardV1 reports whether the v1 call was performed by [jsonv1std]
+	// rather than by the emulation of v1 in [jsonv1]
+	// (see [Codec.V1Implementation]).
+	StandardV1 bool `json:",omitzero"`
 	// Options is the set of options that need to be enabled
 	// in order to resolve any behavior difference between v1 and v2.
 	// It is only populated if [Codec.AutoDetectOptions] is enabled.
 	Options jsonv2.Options `json:",omitzero"`
+	// OptionsIncomplete reports whether [Codec.AutoDetectOptions] found
+	// no set of options that fully resolves the difference,
+	// such that Options (which may be empty) only explains part of it
+	// and the difference requires manual diagnosis.
+	OptionsIncomplete bool `json:",omitzero"`
+	// OptionCategories maps the name of every option in Options
+	// (as reported by [Difference.OptionNames]) to its category
+	// so that cosmetic differences can be distinguished from behavioral ones
+	// (e.g., {"jsontext.EscapeForHTML": "Formatting"}).
+	// It is populated when reported.
+	OptionCategories map[string]OptionCategory `json:",omitzero"`
+	// OptionScopes maps the name of every option in Options
+	// (as reported by [Difference.OptionNames]) to whether it can be
+	// specified as a struct tag option on the struct field at which
+	// the values diverged or only as an option to the call
+	// (e.g., {"jsonv2.MatchCaseInsensitiveNames": "Tag"}),
+	// so that fixes can be routed to the owners of types or of services.
+	// It is populated when reported.
+	OptionScopes map[string]OptionScope `json:",omitzero"`
+	// OptionLocations maps the name of every option in Options to the
+	// location at which disabling that option alone changes the result of v2,
+	// so that the nested value requiring each option can be pinpointed.
+	// For marshal, the location is a JSON pointer into the JSON output
+	// (e.g., {"jsonv1.FormatDurationAsNano": "/config/timeout"}).
+	// For unmarshal, the location is a path as formatted for GoValuePath
+	// (e.g., {"jsonv1.MergeWithLegacySemantics": "Config.Timeout"}),
+	// unless disabling the option makes v2 fail, in which case it is
+	// a JSON pointer into JSONValue (e.g., "/config/timeout").
+	// It is only populated if [Codec.AutoDetectOptions] is enabled.
+	OptionLocations map[string]string `json:",omitzero"`
+	// CausingOptions is the subset of CallerOptions that cause
+	// the behavior difference between v1 and v2 for an unmarshal call,
+	// where omitting any one of them makes v1 and v2 behave identically
+	// (e.g., [jsonv2.RejectUnknownMembers] from [Decoder.DisallowUnknownFields]).
+	// It is only populated if [Codec.AutoDetectOptions] is enabled.
+	CausingOptions jsonv2.Options `json:",omitzero"`
+	// DetectionFailed reports whether [Codec.AutoDetectOptions] could not
+	// detect any options since its sanity check failed, where v2 configured
+	// with [jsonv1.DefaultOptionsV1] still behaves differently from v1.
+	// This is a probable bug in the emulation of v1 that should be escalated
+	// rather than ignored as a difference without any options.
+	// Such differences are also counted in [CodecMetrics.NumEmulationRegressions]
+	// and reported to [Codec.ReportEmulationRegression].
+	DetectionFailed bool `json:",omitzero"`
+	// DetectionFailedReason describes why detection failed.
+	// It is only populated if DetectionFailed is true.
+	DetectionFailedReason string `json:",omitzero"`
+	// SuggestedCall is Go source for a [Marshal] or [Unmarshal] call
+	// that specifies the Options that are not already among
+	// the CallerOptions (e.g.,
+	// "jsonsplit.Unmarshal(b, v, jsonv2.MatchCaseInsensitiveNames(true))")
+	// so that the remediation can be copied directly from a report.
+	// A detected [Decoder.UseNumber] is suggested as a trailing comment.
+	// It is only populated if such options were detected.
+	SuggestedCall string `json:",omitzero"`
+	// TagSuggestions are struct tags that resolve the difference
+	// at the declaration of the struct field at which the values diverged
+	// (as located by GoValuePath or JSONValuePointer),
+	// for any of Options that are expressible as struct tag options
+	// (e.g., `json:",case:ignore"` for [jsonv2.MatchCaseInsensitiveNames]).
+	// This allows the owner of a type to fix it once instead of at every call site.
+	TagSuggestions []TagSuggestion `json:",omitzero"`
 }
 
 var differenceOptions = sync.OnceValue(func() jsonv2.Options {
@@ -435,7 +1302,7 @@ var differenceOptions = sync.OnceValue(func() jsonv2.Options {
 				return e.WriteToken(jsontext.String(err.Error()))
 			}),
 			jsonv2.MarshalToFunc(func(e *jsontext.Encoder, opts jsonv2.Options) error {
-				return jsonv2.MarshalEncode(e, slices.Collect(optionNames(opts)))
+				return jsonv2.MarshalEncode(e, slices.Collect(optionNamesOf(opts, true)))
 			}),
 		)),
 	)
@@ -465,12 +1332,187 @@ func typeString(t reflect.Type) string {
 // In particular, it uses:
 //   - [reflect.Type.String] to encode a Go type
 //   - [error.Error] to encode a Go error
-//   - [Difference.OptionNames] to encode a [jsonv2.Options]
+//   - [Difference.CallerOptionNames] to encode a [jsonv2.Options]
+//
+// See [Difference.MarshalJSONLossless] for a representation
+// that preserves the exact JSON payloads.
 func (d Difference) MarshalJSON() ([]byte, error) {
 	type difference Difference
 	return jsonv2.Marshal(difference(d), differenceOptions())
 }
 
+// estimatedSize estimates the size of the JSON representation of d
+// (see [Difference.MarshalJSON]) without marshaling it
+// by summing the lengths of the names and values of the populated fields,
+// ignoring any escaping of strings.
+func (d Difference) estimatedSize() int {
+	n := len("{}")
+	add := func(name string, size int) {
+		if size > 0 {
+			n += len(`"":,`) + len(name) + size
+		}
+	}
+	str := func(s string) int {
+		if s == "" {
+			return 0
+		}
+		return len(`""`) + len(s)
+	}
+	errStr := func(err error) int {
+		if err == nil {
+			return 0
+		}
+		return str(err.Error())
+	}
+	strs := func(m map[string]string) int {
+		size := len("{}")
+		for k, v := range m {
+			size += len(`:,`) + str(k) + str(v)
+		}
+		return size
+	}
+	opts := func(o jsonv2.Options) int {
+		if o == nil {
+			return 0
+		}
+		size := len("[]")
+		for name := range optionNamesOf(o, true) {
+			size += len(`,`) + str(name)
+		}
+		return size
+	}
+
+	add("ID", str(d.ID))
+	add("Caller", str(d.Caller))
+	if len(d.Labels) > 0 {
+		add("Labels", strs(d.Labels))
+	}
+	add("Func", str(d.Func))
+	if d.Severity != SeveritySemantic {
+		add("Severity", str(d.Severity.String()))
+	}
+	add("Check", str(d.Check))
+	if d.GoType != nil {
+		add("GoType", str(typeString(d.GoType)))
+	}
+	add("JSONValue", len(d.JSONValue))
+	add("JSONValueV1", len(d.JSONValueV1))
+	add("JSONValueV2", len(d.JSONValueV2))
+	add("GoValuePath", str(d.GoValuePath))
+	if len(d.FieldDifferences) > 0 {
+		size := len("[]")
+		for _, f := range d.FieldDifferences {
+			size += len(`{"Field":,"ValueV1":,"ValueV2":},`) + str(f.Field) + str(f.ValueV1) + str(f.ValueV2)
+		}
+		add("FieldDifferences", size)
+	}
+	add("GoValueDiff", str(d.GoValueDiff))
+	if d.InputOffset != 0 {
+		add("InputOffset", len(strconv.FormatInt(d.InputOffset, 10)))
+	}
+	add("JSONValuePointer", str(string(d.JSONValuePointer)))
+	if d.JSONValueOffset != 0 {
+		add("JSONValueOffset", len(strconv.FormatInt(d.JSONValueOffset, 10)))
+	}
+	add("ErrorV1", errStr(d.ErrorV1))
+	add("ErrorV2", errStr(d.ErrorV2))
+	add("CallerOptions", opts(d.CallerOptions))
+	add("OptionsV1", opts(d.OptionsV1))
+	add("OptionsV2", opts(d.OptionsV2))
+	if d.StandardV1 {
+		add("StandardV1", len("true"))
+	}
+	add("Options", opts(d.Options))
+	if d.OptionsIncomplete {
+		add("OptionsIncomplete", len("true"))
+	}
+	if len(d.OptionCategories) > 0 {
+		size := len("{}")
+		for name, cat := range d.OptionCategories {
+			size += len(`:,`) + str(name) + str(cat.String())
+		}
+		add("OptionCategories", size)
+	}
+	if len(d.OptionScopes) > 0 {
+		size := len("{}")
+		for name, scope := range d.OptionScopes {
+			size += len(`:,`) + str(name) + str(scope.String())
+		}
+		add("OptionScopes", size)
+	}
+	if len(d.OptionLocations) > 0 {
+		add("OptionLocations", strs(d.OptionLocations))
+	}
+	add("CausingOptions", opts(d.CausingOptions))
+	if d.DetectionFailed {
+		add("DetectionFailed", len("true"))
+	}
+	add("DetectionFailedReason", str(d.DetectionFailedReason))
+	add("SuggestedCall", str(d.SuggestedCall))
+	if len(d.TagSuggestions) > 0 {
+		size := len("[]")
+		for _, ts := range d.TagSuggestions {
+			size += len(`{"Type":,"Field":,"Tag":},`) + str(ts.Type) + str(ts.Field) + str(ts.Tag)
+		}
+		add("TagSuggestions", size)
+	}
+	return n
+}
+
+// MarshalJSONLossless marshals d as JSON in the same format as
+// [Difference.MarshalJSON], except that any JSON payload
+// (i.e., JSONValue, JSONValueV1, or JSONValueV2) that is not valid JSON
+// or not already formatted as it would be encoded is instead encoded
+// as base64 in a sibling field with a "Base64" suffix
+// (e.g., "JSONValueBase64"), such that [Difference.UnmarshalJSON]
+// can restore the exact payload. It is intended for recording
+// differences for later analysis by a separate process.
+//
+// Go types and Go values are still not reversible (see [ReadCorpus]).
+func (d Difference) MarshalJSONLossless() ([]byte, error) {
+	type difference Difference
+	var dl struct {
+		difference
+		JSONValueBase64   []byte `json:",omitzero"`
+		JSONValueV1Base64 []byte `json:",omitzero"`
+		JSONValueV2Base64 []byte `json:",omitzero"`
+	}
+	dl.difference = difference(d)
+	for _, p := range []struct {
+		val *jsontext.Value
+		b64 *[]byte
+	}{
+		{&dl.JSONValue, &dl.JSONValueBase64},
+		{&dl.JSONValueV1, &dl.JSONValueV1Base64},
+		{&dl.JSONValueV2, &dl.JSONValueV2Base64},
+	} {
+		if *p.val == nil {
+			continue
+		}
+		v := jsontext.Value(bytes.Clone(*p.val))
+		if err := v.Format(jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)); err != nil || !bytes.Equal(v, *p.val) {
+			*p.val, *p.b64 = nil, *p.val
+		}
+	}
+	return jsonv2.Marshal(dl, differenceOptions())
+}
+
+// UnmarshalJSON unmarshals d from JSON produced by
+// [Difference.MarshalJSON] or [Difference.MarshalJSONLossless].
+//
+// Options are reconstructed from their names (unknown names are ignored)
+// and errors only preserve the error message.
+// [Difference.GoType] is never populated since only its name is recorded
+// (see [ReadCorpus] for how to resolve Go types by name).
+func (d *Difference) UnmarshalJSON(b []byte) error {
+	var e corpusEntry
+	if err := jsonv2.Unmarshal(b, &e, corpusOptions()); err != nil {
+		return err
+	}
+	*d = e.difference(nil)
+	return nil
+}
+
 // String returns the difference as JSON.
 func (d Difference) String() string {
 	b, _ := d.MarshalJSON()
@@ -483,23 +1525,77 @@ func (d Difference) OptionNames() iter.Seq[string] {
 	return optionNames(d.Options)
 }
 
-// sortedOptionNames is list a sorted list of all options that
-// define behavior differences between v1 and v2.
-var sortedOptionNames = sync.OnceValue(func() []string {
-	names := slices.Collect(maps.Keys(defaultOptionsV1))
-	slices.Sort(names)
-	return names
-})
+// CallerOptionNames returns an iterator over the names of all the options
+// in [Difference.CallerOptions] that were explicitly set by the caller.
+// Options explicitly disabled by the caller are reported with a "(false)" suffix
+// (e.g., "jsonv2.Deterministic(false)").
+func (d Difference) CallerOptionNames() iter.Seq[string] {
+	return optionNamesOf(d.CallerOptions, true)
+}
+
+// callerOptions joins the options explicitly passed by the caller,
+// returning nil if there are none.
+func callerOptions(o []jsonv2.Options) jsonv2.Options {
+	if len(o) == 0 {
+		return nil
+	}
+	return jsonv2.JoinOptions(o...)
+}
 
 func optionNames(opts jsonv2.Options) iter.Seq[string] {
+	return optionNamesOf(opts, false)
+}
+
+// optionNamesOf iterates over the names of all the enabled options in opts.
+// If explicit is specified, then options explicitly disabled in opts
+// are also reported with a "(false)" suffix.
+func optionNamesOf(opts jsonv2.Options, explicit bool) iter.Seq[string] {
 	return func(yield func(string) bool) {
-		for _, name := range sortedOptionNames() {
-			if v, ok := jsonv2.GetOption(opts, defaultOptionsV1[name]); v && ok {
-				if !yield(name) {
-					return
-				}
+		report := func(name string, v, ok bool) bool {
+			switch {
+			case !ok || (!v && !explicit):
+				return true
+			case !v:
+				return yield(name + "(false)")
+			default:
+				return yield(name)
+			}
+		}
+		number := opts != nil && usesNumber(opts)
+		for _, p := range optionProbes() {
+			v, ok := jsonv2.GetOption(opts, p.option)
+			if !report(p.name, v, ok) {
+				return
+			}
+		}
+
+		// Report options that v1 and v2 never enable by default,
+		// but which callers commonly specify (e.g., through [Decoder]).
+		if v, _ := jsonv2.GetOption(opts, jsonv2.RejectUnknownMembers); v {
+			if !yield("jsonv2.RejectUnknownMembers") {
+				return
+			}
+		}
+		if number {
+			if !yield("jsonv1.Decoder.UseNumber") {
+				return
 			}
 		}
+
+		// Report formatting options (see formatOptions).
+		if v, ok := jsonv2.GetOption(opts, jsontext.WithIndent); ok {
+			if !yield("jsontext.WithIndent(" + strconv.Quote(v) + ")") {
+				return
+			}
+		} else if v, ok := jsonv2.GetOption(opts, jsontext.Multiline); !report("jsontext.Multiline", v, ok) {
+			return
+		}
+		if v, ok := jsonv2.GetOption(opts, jsontext.SpaceAfterColon); !report("jsontext.SpaceAfterColon", v, ok) {
+			return
+		}
+		if v, ok := jsonv2.GetOption(opts, jsontext.SpaceAfterComma); !report("jsontext.SpaceAfterComma", v, ok) {
+			return
+		}
 	}
 }
 
@@ -524,6 +1620,16 @@ const (
 	CallV2ButUponErrorReturnV1
 	// OnlyCallV2 specifies to only call v2 functionality.
 	OnlyCallV2
+	// CallBothV1StdAndV1Emulated specifies to call both [jsonv1std]
+	// and the emulation of v1 in [jsonv1] (which is implemented in terms of v2),
+	// but to return the results for [jsonv1std].
+	// It verifies the emulation of v1 itself (e.g., during toolchain upgrades)
+	// independent of any v2 behavior, where differences are reported
+	// with a Check of "StandardV1" (see [Codec.VerifyStandardV1]).
+	// Since [jsonv1std] does not support options, calls with any options
+	// (including [Codec.DefaultOptions]) are treated as [OnlyCallV1],
+	// as are the formatting functions (e.g., [Codec.Compact]).
+	CallBothV1StdAndV1Emulated
 
 	maxCallMode
 )
@@ -535,6 +1641,7 @@ var callModeNames = map[CallMode]string{
 	CallBothButReturnV2:        "CallBothButReturnV2",
 	CallV2ButUponErrorReturnV1: "CallV2ButUponErrorReturnV1",
 	OnlyCallV2:                 "OnlyCallV2",
+	CallBothV1StdAndV1Emulated: "CallBothV1StdAndV1Emulated",
 }
 
 func (m CallMode) String() string {
@@ -544,12 +1651,107 @@ func (m CallMode) String() string {
 	return fmt.Sprintf("CallMode(%d)", m)
 }
 
+// callModeKeys are the keys in [CodecMetrics.CallModeCounters]
+// for a particular operation and call mode.
+type callModeKeys struct{ calls, diffs string }
+
+var (
+	marshalCallModeKeys   = newCallModeKeys("Marshal")
+	unmarshalCallModeKeys = newCallModeKeys("Unmarshal")
+)
+
+func newCallModeKeys(op string) (keys [maxCallMode]callModeKeys) {
+	for m := range maxCallMode {
+		keys[m] = callModeKeys{op + "/" + m.String() + "/calls", op + "/" + m.String() + "/diffs"}
+	}
+	return keys
+}
+
+// withoutComparison returns the equivalent mode that only calls
+// a single implementation if the mode always calls both.
+func (m CallMode) withoutComparison() CallMode {
+	switch m {
+	case CallBothButReturnV1:
+		return OnlyCallV1
+	case CallBothButReturnV2:
+		return OnlyCallV2
+	case CallBothV1StdAndV1Emulated:
+		return OnlyCallV1
+	default:
+		return m
+	}
+}
+
+// callsBoth reports whether the mode always calls both implementations,
+// such that only then is it worth deciding whether to compare
+// (e.g., with [Codec.ShouldCompareMarshal]).
+func (m CallMode) callsBoth() bool {
+	return m.withoutComparison() != m
+}
+
+// mayCompare reports whether the mode may call both implementations
+// (e.g., [CallV1ButUponErrorReturnV2] calls both if v1 fails).
+func (m CallMode) mayCompare() bool {
+	return m != OnlyCallV1 && m != OnlyCallV2
+}
+
 func (m CallMode) checkValid() {
 	if m < 0 || m >= maxCallMode {
 		panic("invalid mode")
 	}
 }
 
+// V1Implementation specifies which implementation of v1 is called
+// by [Codec.Marshal] and [Codec.Unmarshal] (see [Codec.V1Implementation]),
+// which is both the baseline for comparisons with v2 and
+// the source of results returned for v1.
+type V1Implementation int
+
+const (
+	// AutoSelectV1 specifies to call [jsonv1std] if the options are
+	// exactly [jsonv1.DefaultOptionsV1], and otherwise to call
+	// the emulation of v1 in [jsonv1] (which is implemented in terms of v2).
+	AutoSelectV1 V1Implementation = iota
+	// AlwaysUseStdV1 specifies to call [jsonv1std].
+	// Since [jsonv1std] does not support options, calls with any options
+	// other than [jsonv1.DefaultOptionsV1] still call [jsonv1].
+	AlwaysUseStdV1
+	// AlwaysUseEmulatedV1 specifies to call the emulation of v1 in [jsonv1].
+	AlwaysUseEmulatedV1
+)
+
+func (impl V1Implementation) String() string {
+	switch impl {
+	case AutoSelectV1:
+		return "AutoSelectV1"
+	case AlwaysUseStdV1:
+		return "AlwaysUseStdV1"
+	case AlwaysUseEmulatedV1:
+		return "AlwaysUseEmulatedV1"
+	default:
+		return fmt.Sprintf("V1Implementation(%d)", int(impl))
+	}
+}
+
+// v1Options returns the options o to pass to the v1 functions
+// (e.g., [jsonv1Marshal]) such that they call the implementation
+// specified by [Codec.V1Implementation].
+func (c *Codec) v1Options(o []jsonv2.Options) []jsonv2.Options {
+	switch c.V1Implementation {
+	case AlwaysUseStdV1:
+		if len(o) == 0 {
+			return []jsonv2.Options{jsonv1.DefaultOptionsV1()}
+		}
+	case AlwaysUseEmulatedV1:
+		if len(o) == 1 && o[0] == jsonv1.DefaultOptionsV1() {
+			// A joined copy of the default options is semantically identical,
+			// but is not the sentinel that selects [jsonv1std].
+			return []jsonv2.Options{jsonv2.JoinOptions(o[0])}
+		}
+	}
+	return o
+}
+
 // Marshal marshals from v with either [jsonv1.Marshal] or [jsonv2.Marshal]
 // depending on the mode specified in [Codec.SetMarshalCallRatio].
 // If both v1 and v2 are called, it checks whether any differences
@@ -560,24 +1762,76 @@ func (m CallMode) checkValid() {
 // then this calls [jsonv1std.Marshal] instead of [jsonv1.Marshal]
 // when operating in v1 mode. This allows for detection of differences
 // between [jsonv1std] and [jsonv1].
+// The implementation of v1 may also be selected with [Codec.V1Implementation].
 func (c *Codec) Marshal(v any, o ...jsonv2.Options) (b []byte, err error) {
-	c.NumMarshalTotal.Add(1)
+	return c.marshal(context.Background(), callSite{}, nil, jsonv1Marshal, jsonv2.Marshal, v, o...)
+}
+
+// MarshalIndent is like [Codec.Marshal], but applies indentation
+// to format the output in the same way as [jsonv1.MarshalIndent].
+//
+// The v1 call indents the output with [jsonv1.Indent],
+// while the v2 call uses [jsontext.WithIndentPrefix] and [jsontext.WithIndent],
+// such that any differences in indentation (or escaping)
+// between v1 and [jsontext] are also detected.
+// Since v2 only supports a prefix and indent composed of spaces and tabs,
+// calls with any other characters compare the compact outputs of v1 and v2
+// and then indent the returned output with [jsonv1.Indent].
+func (c *Codec) MarshalIndent(v any, prefix, indent string, o ...jsonv2.Options) ([]byte, error) {
+	if strings.Trim(prefix, " \t") != "" || strings.Trim(indent, " \t") != "" {
+		b, err := c.marshal(context.Background(), callSite{}, nil, jsonv1Marshal, jsonv2.Marshal, v, o...)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := jsonv1.Indent(&buf, b, prefix, indent); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	oV1 := c.v1Options(c.withDefaultOptions(c.typeOptions(reflect.TypeOf(v)), o))
+	marshalV1 := func(v any, _ ...jsonv2.Options) ([]byte, error) {
+		return jsonv1MarshalIndent(v, prefix, indent, oV1...)
+	}
+	o = append(slices.Clip(o), jsontext.WithIndentPrefix(prefix), jsontext.WithIndent(indent))
+	return c.marshal(context.Background(), callSite{}, nil, marshalV1, jsonv2.Marshal, v, o...)
+}
+
+// marshal implements [Codec.Marshal], where differences are attributed
+// to the provided call site if non-zero (see [Codec.WithCallerLabel]).
+// The per-type state ts is non-nil if the type is known (see [MarshalFor]).
+// The v1 call is performed by marshalV1 (e.g., [jsonv1Marshal]) and
+// the v2 call is performed by marshalV2 (e.g., [jsonv2.Marshal]).
+func (c *Codec) marshal(ctx context.Context, site callSite, ts *typeState, marshalV1, marshalV2 func(any, ...jsonv2.Options) ([]byte, error), v any, o ...jsonv2.Options) (b []byte, err error) {
+	t, typeOpts := c.topLevelType("Marshal", ts, v)
+	o = c.withDefaultOptions(typeOpts, o)
+	oV1 := c.v1Options(o)
+	oV2 := c.withDetectedOptions("Marshal", t, o)
+	c.add(&c.NumMarshalTotal, 1)
 	defer func() {
-		c.MarshalSizeHistogram.insertSize(len(b))
+		c.insertSize(&c.MarshalSizeHistogram, len(b))
 		if err != nil {
-			c.NumMarshalErrors.Add(1)
+			c.add(&c.NumMarshalErrors, 1)
 		}
 	}()
 
-	switch mode := c.marshalCallRatio.loadRandomMode(); mode {
+	mode := c.marshalRatio().loadRandomMode()
+	if mode.callsBoth() && c.ShouldCompareMarshal != nil && !c.ShouldCompareMarshal(v) {
+		mode = mode.withoutComparison()
+	}
+	c.addKey(&c.CallModeCounters, marshalCallModeKeys[mode].calls, 1)
+	switch mode {
 	case OnlyCallV1:
-		c.NumMarshalOnlyCallV1.Add(1)
-		c.NumMarshalReturnV1.Add(1)
-		return jsonv1Marshal(v, o...)
+		c.add(&c.NumMarshalOnlyCallV1, 1)
+		c.add(&c.NumMarshalReturnV1, 1)
+		return marshalV1(v, oV1...)
 	case OnlyCallV2:
-		c.NumMarshalOnlyCallV2.Add(1)
-		c.NumMarshalReturnV2.Add(1)
-		return jsonv2.Marshal(v, o...)
+		c.add(&c.NumMarshalOnlyCallV2, 1)
+		c.add(&c.NumMarshalReturnV2, 1)
+		b, err := marshalV2(v, oV2...)
+		return b, c.translateMarshalError(err)
+	case CallBothV1StdAndV1Emulated:
+		return c.marshalStandardV1(ctx, site, t, marshalV1, v, o...)
 	case CallV1ButUponErrorReturnV2, CallBothButReturnV1, CallBothButReturnV2, CallV2ButUponErrorReturnV1:
 		// Marshal both through v1 and v2 and verify results are identical.
 		var buf1, buf2 []byte
@@ -585,74 +1839,150 @@ func (c *Codec) Marshal(v any, o ...jsonv2.Options) (b []byte, err error) {
 		var dur1, dur2 time.Duration
 		switch mode {
 		case CallV1ButUponErrorReturnV2:
-			dur1 = elapsed(func() { buf1, err1 = jsonv1Marshal(v, o...) })
+			dur1 = elapsed(func() { buf1, err1 = marshalV1(v, oV1...) })
 			if err1 == nil {
-				c.NumMarshalOnlyCallV1.Add(1)
-				c.NumMarshalReturnV1.Add(1)
+				c.add(&c.NumMarshalOnlyCallV1, 1)
+				c.add(&c.NumMarshalReturnV1, 1)
 				return buf1, nil
 			}
-			dur2 = elapsed(func() { buf2, err2 = jsonv2.Marshal(v, o...) })
+			dur2 = elapsed(func() { buf2, err2 = marshalV2(v, oV2...) })
 		case CallV2ButUponErrorReturnV1:
-			dur2 = elapsed(func() { buf2, err2 = jsonv2.Marshal(v, o...) })
+			dur2 = elapsed(func() { buf2, err2 = marshalV2(v, oV2...) })
 			if err2 == nil {
-				c.NumMarshalOnlyCallV2.Add(1)
-				c.NumMarshalReturnV2.Add(1)
+				c.add(&c.NumMarshalOnlyCallV2, 1)
+				c.add(&c.NumMarshalReturnV2, 1)
 				return buf2, nil
 			}
-			dur1 = elapsed(func() { buf1, err1 = jsonv1Marshal(v, o...) })
-		case CallBothButReturnV1, CallBothButReturnV2:
-			dur1 = elapsed(func() { buf1, err1 = jsonv1Marshal(v, o...) })
-			dur2 = elapsed(func() { buf2, err2 = jsonv2.Marshal(v, o...) })
+			dur1 = elapsed(func() { buf1, err1 = marshalV1(v, oV1...) })
+		case CallBothButReturnV1:
+			dur1 = elapsed(func() { buf1, err1 = marshalV1(v, oV1...) })
+			if !c.sampleSize(len(buf1)) {
+				c.add(&c.NumMarshalOnlyCallV1, 1)
+				c.add(&c.NumMarshalReturnV1, 1)
+				return buf1, err1
+			}
+			dur2 = elapsed(func() { buf2, err2 = c.streamMarshalV2(buf1, marshalV2, v, oV2...) })
+		case CallBothButReturnV2:
+			dur2 = elapsed(func() { buf2, err2 = marshalV2(v, oV2...) })
+			if !c.sampleSize(len(buf2)) {
+				c.add(&c.NumMarshalOnlyCallV2, 1)
+				c.add(&c.NumMarshalReturnV2, 1)
+				return buf2, c.translateMarshalError(err2)
+			}
+			dur1 = elapsed(func() { buf1, err1 = marshalV1(v, oV1...) })
 		}
-		c.NumMarshalCallBoth.Add(1)
-		c.ExecTimeMarshalV1Nanos.Add(int64(dur1))
-		c.ExecTimeMarshalV2Nanos.Add(int64(dur2))
+		c.add(&c.NumMarshalCallBoth, 1)
+		c.add(&c.ExecTimeMarshalV1Nanos, int64(dur1))
+		c.add(&c.ExecTimeMarshalV2Nanos, int64(dur2))
 
 		// Check for differences.
-		if !(c.jsonEqual(buf1, buf2) && c.errorsEqual(err1, err2)) {
-			caller := c.caller()
-			c.NumMarshalDiffs.Add(1)
-			c.MarshalCallerHistogram.Add(caller, 1)
-
-			var options jsonv2.Options
-			if c.AutoDetectOptions {
-				options = autoDetectOptions(func(o ...jsonv2.Options) bool {
-					buf2, err2 := jsonv2.Marshal(v, o...)
-					return c.jsonEqual(buf1, buf2) && c.errorsEqual(err1, err2)
-				}, o...)
-				for name := range optionNames(options) {
-					c.MarshalOptionHistogram.Add(name, 1)
-				}
+		hasDiff := !(c.jsonEqual(buf1, buf2) && c.errorsEqual(err1, err2))
+		var caller string
+		if hasDiff || c.TrackCallerDiffRates {
+			caller = c.labelOrCaller(site)
+		}
+		if c.TrackCallerDiffRates {
+			c.observeCaller(&c.MarshalCallerDiffRates, caller, hasDiff)
+		}
+		if hasDiff {
+			c.add(&c.NumMarshalDiffs, 1)
+			c.addKey(&c.CallModeCounters, marshalCallModeKeys[mode].diffs, 1)
+			c.addKey(&c.MarshalCallerHistogram, caller, 1)
+			d := Difference{
+				Caller:        caller,
+				Labels:        contextLabels(ctx),
+				Func:          "Marshal",
+				GoType:        t,
+				GoValue:       v,
+				JSONValueV1:   buf1,
+				JSONValueV2:   buf2,
+				ErrorV1:       err1,
+				ErrorV2:       err2,
+				CallerOptions: callerOptions(o),
+				OptionsV1:     effectiveOptions(jsonv1.DefaultOptionsV1(), oV1),
+				OptionsV2:     effectiveOptions(jsonv2.DefaultOptionsV2(), oV2),
+				StandardV1:    usesStandardV1(oV1),
 			}
-
-			if c.ReportDifference != nil {
-				c.ReportDifference(Difference{
-					Caller:      caller,
-					Func:        "Marshal",
-					GoType:      reflect.TypeOf(v),
-					GoValue:     v,
-					JSONValueV1: buf1,
-					JSONValueV2: buf2,
-					ErrorV1:     err1,
-					ErrorV2:     err2,
-					Options:     options,
-				})
+			c.classifyDifference(&d)
+			if c.sampleReport() && (c.AutoDetectOptions || c.reportsDifferences()) {
+				c.analyzeDifference(d, func(d Difference) { c.analyzeMarshalDifference(ts, d, oV2...) })
 			}
 		}
 
+		returnV1 := mode == CallBothButReturnV1 || mode == CallV2ButUponErrorReturnV1
+		c.verifyMarshal(ctx, caller, t, returnV1, v, buf1, buf2, err1, err2, o...)
+
 		// Select the appropriate return value.
 		switch mode {
 		case CallBothButReturnV1, CallV2ButUponErrorReturnV1:
-			c.NumMarshalReturnV1.Add(1)
+			c.add(&c.NumMarshalReturnV1, 1)
 			return buf1, err1
 		case CallBothButReturnV2, CallV1ButUponErrorReturnV2:
-			c.NumMarshalReturnV2.Add(1)
-			return buf2, err2
+			c.add(&c.NumMarshalReturnV2, 1)
+			return buf2, c.translateMarshalError(err2)
 		}
 	}
 	panic("unknown mode")
 }
 
+// analyzeMarshalDifference detects the options that explain
+// a sampled difference d between marshal calls with the v2 options o
+// (see [Codec.AutoDetectOptions]) and reports it.
+// It only relies on the values in d, which may be a copy
+// (see [Codec.analyzeDifference]).
+func (c *Codec) analyzeMarshalDifference(ts *typeState, d Difference, o ...jsonv2.Options) {
+	t, v, buf1, err1 := d.GoType, d.GoValue, d.JSONValueV1, d.ErrorV1
+	emulated := true
+	if c.AutoDetectOptions {
+		var options jsonv2.Options
+		var resolved, detected bool
+		options, resolved, emulated, detected = c.detectOptions("Marshal", t, ts.detectedOptions("Marshal"), func(o ...jsonv2.Options) bool {
+			buf2, err2 := jsonv2.Marshal(v, o...)
+			return c.jsonEqual(buf1, buf2) && c.errorsEqual(err1, err2)
+		}, o...)
+		if detected {
+			if resolved {
+				c.storeDetectedOptions("Marshal", t, options)
+			}
+			d.Options, d.OptionsIncomplete = options, emulated && !resolved
+			for name := range optionNames(options) {
+				c.addKey(&c.MarshalOptionHistogram, name, 1)
+			}
+			d.OptionLocations = optionLocations(options, func(with, without []jsonv2.Options) (string, bool) {
+				buf1, err1 := jsonv2.Marshal(v, with...)
+				buf2, err2 := jsonv2.Marshal(v, without...)
+				ptr, _, ok := jsonValueDivergence(buf1, buf2)
+				switch {
+				case err1 != nil:
+					return "", false
+				case err2 != nil:
+					ptr, ok = errorPointer(err2)
+				}
+				return string(ptr), ok
+			}, o...)
+			if resolved {
+				c.recordDetectedOptions("Marshal", t, options)
+			}
+		}
+	}
+	c.reportAnalyzedDifference(d, emulated)
+}
+
+// reportAnalyzedDifference reports a difference d analyzed by
+// [Codec.AutoDetectOptions], including to [Codec.ReportEmulationRegression]
+// if v2 with the v1 default options did not emulate v1.
+func (c *Codec) reportAnalyzedDifference(d Difference, emulated bool) {
+	if !emulated {
+		d.DetectionFailed, d.DetectionFailedReason = true, emulationRegressionReason
+	}
+	if c.reportsDifferences() {
+		c.reportDifferenceTo(c.publishDifference, d)
+	}
+	if !emulated {
+		c.reportEmulationRegression(d)
+	}
+}
+
 // Unmarshal unmarshals to v with either [jsonv1.Unmarshal] or [jsonv2.Unmarshal]
 // depending on the mode specified in [Codec.SetUnmarshalCallRatio].
 // If both v1 and v2 are called, it checks whether any differences
@@ -663,65 +1993,105 @@ func (c *Codec) Marshal(v any, o ...jsonv2.Options) (b []byte, err error) {
 // then this calls [jsonv1std.Unmarshal] instead of [jsonv1.Unmarshal]
 // when operating in v1 mode. This allows for detection of differences
 // between [jsonv1std] and [jsonv1].
+// The implementation of v1 may also be selected with [Codec.V1Implementation].
 func (c *Codec) Unmarshal(b []byte, v any, o ...jsonv2.Options) (err error) {
-	c.NumUnmarshalTotal.Add(1)
-	c.UnmarshalSizeHistogram.insertSize(len(b))
+	return c.unmarshal(context.Background(), callSite{}, nil, b, v, o...)
+}
+
+// unmarshal implements [Codec.Unmarshal], where differences are attributed
+// to the provided call site if non-zero (see [Codec.WithCallerLabel]).
+// The per-type state ts is non-nil if the type is known (see [UnmarshalFor]).
+func (c *Codec) unmarshal(ctx context.Context, site callSite, ts *typeState, b []byte, v any, o ...jsonv2.Options) (err error) {
+	t, typeOpts := c.topLevelType("Unmarshal", ts, v)
+	o = c.withDefaultOptions(typeOpts, o)
+	oV1 := c.v1Options(o)
+	oV2 := c.withDetectedOptions("Unmarshal", t, o)
+	c.add(&c.NumUnmarshalTotal, 1)
+	c.insertSize(&c.UnmarshalSizeHistogram, len(b))
 	if !isPointerToZero(reflect.ValueOf(v)) {
-		c.NumUnmarshalMerge.Add(1)
+		c.add(&c.NumUnmarshalMerge, 1)
 	}
 	defer func() {
 		if err != nil {
-			c.NumUnmarshalErrors.Add(1)
+			c.add(&c.NumUnmarshalErrors, 1)
 		}
 	}()
 
-	switch mode := c.unmarshalCallRatio.loadRandomMode(); mode {
+	mode := c.unmarshalRatio().loadRandomMode()
+	if mode.callsBoth() && c.ShouldCompareUnmarshal != nil && !c.ShouldCompareUnmarshal(b) {
+		mode = mode.withoutComparison()
+	}
+	if mode.callsBoth() && !c.sampleSize(len(b)) {
+		mode = mode.withoutComparison()
+	}
+	c.addKey(&c.CallModeCounters, unmarshalCallModeKeys[mode].calls, 1)
+	switch mode {
 	case OnlyCallV1:
-		c.NumUnmarshalOnlyCallV1.Add(1)
-		c.NumUnmarshalReturnV1.Add(1)
-		return jsonv1Unmarshal(b, v, o...)
+		c.add(&c.NumUnmarshalOnlyCallV1, 1)
+		c.add(&c.NumUnmarshalReturnV1, 1)
+		return jsonv1Unmarshal(b, v, oV1...)
 	case OnlyCallV2:
-		c.NumUnmarshalOnlyCallV2.Add(1)
-		c.NumUnmarshalReturnV2.Add(1)
-		return jsonv2.Unmarshal(b, v, o...)
+		c.add(&c.NumUnmarshalOnlyCallV2, 1)
+		c.add(&c.NumUnmarshalReturnV2, 1)
+		return c.translateUnmarshalError(b, v, jsonv2.Unmarshal(b, v, oV2...))
+	case CallBothV1StdAndV1Emulated:
+		return c.unmarshalStandardV1(ctx, site, ts, t, b, v, o...)
 	case CallV1ButUponErrorReturnV2, CallBothButReturnV1, CallBothButReturnV2, CallV2ButUponErrorReturnV1:
 		// Make sure we can clone the output, otherwise we cannot call both.
-		valOrig := c.cloneGoValue(v)
+		valOrig := c.cloneGoValueFor(ts, v)
 		if valOrig == nil {
 			// Treat uncloneable inputs as a difference.
-			caller := c.caller()
-			c.NumUnmarshalDiffs.Add(1)
-			c.NumUnmarshalCallBothSkipped.Add(1)
-			c.UnmarshalCallerHistogram.Add(caller, 1)
+			caller := c.labelOrCaller(site)
+			c.add(&c.NumUnmarshalDiffs, 1)
+			c.addKey(&c.CallModeCounters, unmarshalCallModeKeys[mode].diffs, 1)
+			c.add(&c.NumUnmarshalCallBothSkipped, 1)
+			c.addKey(&c.UnmarshalCallerHistogram, caller, 1)
+			if c.TrackCallerDiffRates {
+				c.observeCaller(&c.UnmarshalCallerDiffRates, caller, true)
+			}
 			switch mode {
 			case CallV1ButUponErrorReturnV2, CallBothButReturnV1:
-				if c.ReportDifference != nil {
-					c.ReportDifference(Difference{
-						Caller:    caller,
-						Func:      "Unmarshal",
-						GoType:    reflect.TypeOf(v),
-						JSONValue: b,
-						GoValueV1: v,
-						ErrorV2:   ErrNotCloneable,
-					})
+				d := Difference{
+					Caller:        caller,
+					Labels:        contextLabels(ctx),
+					Func:          "Unmarshal",
+					GoType:        t,
+					JSONValue:     b,
+					GoValueV1:     v,
+					ErrorV2:       ErrNotCloneable,
+					CallerOptions: callerOptions(o),
+					OptionsV1:     effectiveOptions(jsonv1.DefaultOptionsV1(), oV1),
+					OptionsV2:     effectiveOptions(jsonv2.DefaultOptionsV2(), oV2),
+					StandardV1:    usesStandardV1(oV1),
+				}
+				c.classifyDifference(&d)
+				if c.reportsDifferences() && c.sampleReport() {
+					c.reportDifference(d)
 				}
-				c.NumUnmarshalOnlyCallV1.Add(1)
-				c.NumUnmarshalReturnV1.Add(1)
-				return jsonv1Unmarshal(b, v, o...)
+				c.add(&c.NumUnmarshalOnlyCallV1, 1)
+				c.add(&c.NumUnmarshalReturnV1, 1)
+				return jsonv1Unmarshal(b, v, oV1...)
 			case CallBothButReturnV2, CallV2ButUponErrorReturnV1:
-				if c.ReportDifference != nil {
-					c.ReportDifference(Difference{
-						Caller:    caller,
-						Func:      "Unmarshal",
-						GoType:    reflect.TypeOf(v),
-						JSONValue: b,
-						GoValueV2: v,
-						ErrorV1:   ErrNotCloneable,
-					})
+				d := Difference{
+					Caller:        caller,
+					Labels:        contextLabels(ctx),
+					Func:          "Unmarshal",
+					GoType:        t,
+					JSONValue:     b,
+					GoValueV2:     v,
+					ErrorV1:       ErrNotCloneable,
+					CallerOptions: callerOptions(o),
+					OptionsV1:     effectiveOptions(jsonv1.DefaultOptionsV1(), oV1),
+					OptionsV2:     effectiveOptions(jsonv2.DefaultOptionsV2(), oV2),
+					StandardV1:    usesStandardV1(oV1),
 				}
-				c.NumUnmarshalOnlyCallV2.Add(1)
-				c.NumUnmarshalReturnV2.Add(1)
-				return jsonv2.Unmarshal(b, v, o...)
+				c.classifyDifference(&d)
+				if c.reportsDifferences() && c.sampleReport() {
+					c.reportDifference(d)
+				}
+				c.add(&c.NumUnmarshalOnlyCallV2, 1)
+				c.add(&c.NumUnmarshalReturnV2, 1)
+				return c.translateUnmarshalError(b, v, jsonv2.Unmarshal(b, v, oV2...))
 			}
 		}
 
@@ -732,87 +2102,281 @@ func (c *Codec) Unmarshal(b []byte, v any, o ...jsonv2.Options) (err error) {
 		switch mode {
 		case CallV1ButUponErrorReturnV2:
 			val1 = v
-			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, o...) })
+			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, oV1...) })
 			if err1 == nil {
-				c.NumUnmarshalOnlyCallV1.Add(1)
-				c.NumUnmarshalReturnV1.Add(1)
+				c.add(&c.NumUnmarshalOnlyCallV1, 1)
+				c.add(&c.NumUnmarshalReturnV1, 1)
 				return nil
 			}
-			val2 = c.cloneGoValue(valOrig)
-			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, o...) })
+			val2 = c.cloneGoValueFor(ts, valOrig)
+			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, oV2...) })
 			val1 = shallowCopy(v, val2) // v has v1 results, but needs v2
 		case CallV2ButUponErrorReturnV1:
 			val2 = v
-			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, o...) })
+			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, oV2...) })
 			if err2 == nil {
-				c.NumUnmarshalOnlyCallV2.Add(1)
-				c.NumUnmarshalReturnV2.Add(1)
+				c.add(&c.NumUnmarshalOnlyCallV2, 1)
+				c.add(&c.NumUnmarshalReturnV2, 1)
 				return nil
 			}
-			val1 = c.cloneGoValue(valOrig)
-			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, o...) })
+			val1 = c.cloneGoValueFor(ts, valOrig)
+			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, oV1...) })
 			val2 = shallowCopy(v, val1) // v has v2 results, but needs v1
 		case CallBothButReturnV1:
 			val1 = v
-			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, o...) })
-			val2 = c.cloneGoValue(valOrig)
-			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, o...) })
+			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, oV1...) })
+			val2 = c.cloneGoValueFor(ts, valOrig)
+			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, oV2...) })
 		case CallBothButReturnV2:
-			val1 = c.cloneGoValue(valOrig)
-			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, o...) })
+			val1 = c.cloneGoValueFor(ts, valOrig)
+			dur1 = elapsed(func() { err1 = jsonv1Unmarshal(b, val1, oV1...) })
 			val2 = v
-			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, o...) })
+			dur2 = elapsed(func() { err2 = jsonv2.Unmarshal(b, val2, oV2...) })
 		}
-		c.NumUnmarshalCallBoth.Add(1)
-		c.ExecTimeUnmarshalV1Nanos.Add(int64(dur1))
-		c.ExecTimeUnmarshalV2Nanos.Add(int64(dur2))
+		c.add(&c.NumUnmarshalCallBoth, 1)
+		c.add(&c.ExecTimeUnmarshalV1Nanos, int64(dur1))
+		c.add(&c.ExecTimeUnmarshalV2Nanos, int64(dur2))
 
 		// Check for differences.
-		if !(c.goEqual(val1, val2) && c.errorsEqual(err1, err2)) {
-			caller := c.caller()
-			c.NumUnmarshalDiffs.Add(1)
-			c.UnmarshalCallerHistogram.Add(caller, 1)
-
-			var options jsonv2.Options
-			if c.AutoDetectOptions {
-				options = autoDetectOptions(func(o ...jsonv2.Options) bool {
-					val2 := c.cloneGoValue(valOrig)
-					err2 := jsonv2.Unmarshal(b, val2, o...)
-					return c.goEqual(val1, val2) && c.errorsEqual(err1, err2)
-				}, o...)
-				for name := range optionNames(options) {
-					c.UnmarshalOptionHistogram.Add(name, 1)
-				}
+		hasDiff := !c.unmarshalEqual(val1, val2, err1, err2, len(b))
+		var caller string
+		if hasDiff || c.TrackCallerDiffRates {
+			caller = c.labelOrCaller(site)
+		}
+		if c.TrackCallerDiffRates {
+			c.observeCaller(&c.UnmarshalCallerDiffRates, caller, hasDiff)
+		}
+		if hasDiff {
+			c.add(&c.NumUnmarshalDiffs, 1)
+			c.addKey(&c.CallModeCounters, unmarshalCallModeKeys[mode].diffs, 1)
+			c.addKey(&c.UnmarshalCallerHistogram, caller, 1)
+			d := Difference{
+				Caller:        caller,
+				Labels:        contextLabels(ctx),
+				Func:          "Unmarshal",
+				GoType:        t,
+				JSONValue:     b,
+				GoValueV1:     val1,
+				GoValueV2:     val2,
+				GoValuePath:   goValuePath(val1, val2),
+				ErrorV1:       err1,
+				ErrorV2:       err2,
+				CallerOptions: callerOptions(o),
+				OptionsV1:     effectiveOptions(jsonv1.DefaultOptionsV1(), oV1),
+				OptionsV2:     effectiveOptions(jsonv2.DefaultOptionsV2(), oV2),
+				StandardV1:    usesStandardV1(oV1),
 			}
-
-			if c.ReportDifference != nil {
-				c.ReportDifference(Difference{
-					Caller:    caller,
-					Func:      "Unmarshal",
-					GoType:    reflect.TypeOf(v),
-					JSONValue: b,
-					GoValueV1: val1,
-					GoValueV2: val2,
-					ErrorV1:   err1,
-					ErrorV2:   err2,
-					Options:   options,
-				})
+			c.classifyDifference(&d)
+			if c.sampleReport() && (c.AutoDetectOptions || c.reportsDifferences()) {
+				c.analyzeDifference(d, func(d Difference) { c.analyzeUnmarshalDifference(ts, valOrig, d, o, oV2) })
 			}
 		}
 
+		c.verifyUnmarshal(ctx, caller, ts, t, b, valOrig, val1, err1, o...)
+
 		// Select the appropriate return value.
 		switch mode {
 		case CallBothButReturnV1, CallV2ButUponErrorReturnV1:
-			c.NumUnmarshalReturnV1.Add(1)
+			c.add(&c.NumUnmarshalReturnV1, 1)
 			return err1
 		case CallBothButReturnV2, CallV1ButUponErrorReturnV2:
-			c.NumUnmarshalReturnV2.Add(1)
-			return err2
+			c.add(&c.NumUnmarshalReturnV2, 1)
+			return c.translateUnmarshalError(b, v, err2)
 		}
 	}
 	panic("unknown mode")
 }
 
+// analyzeUnmarshalDifference detects the options that explain
+// a sampled difference d between unmarshal calls with the v2 options oV2
+// (see [Codec.AutoDetectOptions]) and reports it,
+// where valOrig is a clone of the original Go value
+// and o are the options specified by the caller.
+// It only relies on the values in d, which may be a copy
+// (see [Codec.analyzeDifference]).
+func (c *Codec) analyzeUnmarshalDifference(ts *typeState, valOrig any, d Difference, o, oV2 []jsonv2.Options) {
+	t, b, val1, err1 := d.GoType, d.JSONValue, d.GoValueV1, d.ErrorV1
+	emulated := true
+	if c.AutoDetectOptions {
+		var options jsonv2.Options
+		var resolved, detected bool
+		options, resolved, emulated, detected = c.detectOptions("Unmarshal", t, ts.detectedOptions("Unmarshal"), func(o ...jsonv2.Options) bool {
+			val2 := c.cloneGoValueFor(ts, valOrig)
+			err2 := jsonv2.Unmarshal(b, val2, o...)
+			return c.unmarshalEqual(val1, val2, err1, err2, len(b))
+		}, oV2...)
+		if detected {
+			if resolved {
+				c.storeDetectedOptions("Unmarshal", t, options)
+			}
+			d.Options, d.OptionsIncomplete = options, emulated && !resolved
+			for name := range optionNames(options) {
+				c.addKey(&c.UnmarshalOptionHistogram, name, 1)
+			}
+			d.OptionLocations = optionLocations(options, func(with, without []jsonv2.Options) (string, bool) {
+				val1, val2 := c.cloneGoValueFor(ts, valOrig), c.cloneGoValueFor(ts, valOrig)
+				err1, err2 := jsonv2.Unmarshal(b, val1, with...), jsonv2.Unmarshal(b, val2, without...)
+				switch {
+				case err1 != nil:
+					return "", false
+				case err2 != nil:
+					ptr, ok := errorPointer(err2)
+					return string(ptr), ok
+				}
+				path := goValuePath(val1, val2)
+				return path, path != ""
+			}, oV2...)
+			if resolved {
+				c.recordDetectedOptions("Unmarshal", t, options)
+			}
+			d.CausingOptions = detectCausingOptions(func(o ...jsonv2.Options) bool {
+				val1, val2 := c.cloneGoValueFor(ts, valOrig), c.cloneGoValueFor(ts, valOrig)
+				err1, err2 := jsonv1Unmarshal(b, val1, c.v1Options(o)...), jsonv2.Unmarshal(b, val2, o...)
+				return c.unmarshalEqual(val1, val2, err1, err2, len(b))
+			}, o...)
+		}
+	}
+	c.reportAnalyzedDifference(d, emulated)
+}
+
+// MarshalToValue is like [Codec.Marshal], but returns a [jsontext.Value]
+// for callers that operate in terms of raw JSON values.
+func (c *Codec) MarshalToValue(v any, o ...jsonv2.Options) (jsontext.Value, error) {
+	return c.Marshal(v, o...)
+}
+
+// UnmarshalFromValue is like [Codec.Unmarshal], but accepts a [jsontext.Value]
+// for callers that operate in terms of raw JSON values.
+func (c *Codec) UnmarshalFromValue(val jsontext.Value, v any, o ...jsonv2.Options) error {
+	return c.Unmarshal(val, v, o...)
+}
+
+// MarshalWrite is like [Codec.Marshal], but writes the output to w
+// for callers that operate in terms of an [io.Writer]
+// (e.g., an HTTP response body).
+// The output is buffered internally, such that both v1 and v2
+// can be called and compared, and is only written if marshaling succeeds.
+func (c *Codec) MarshalWrite(w io.Writer, v any, o ...jsonv2.Options) error {
+	b, err := c.Marshal(v, o...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// MarshalAppend is like [Codec.Marshal], but appends the output to dst
+// and returns the extended buffer. If marshaling fails,
+// it returns dst unmodified along with the error.
+//
+// When the v2 result is returned, v2 marshals directly into
+// the spare capacity of dst, avoiding an extra copy.
+// When the v1 result is returned, it is copied into dst
+// since v1 always allocates its own output.
+func (c *Codec) MarshalAppend(dst []byte, v any, o ...jsonv2.Options) ([]byte, error) {
+	var out []byte // the extended buffer produced by the last v2 call
+	marshalV2 := func(v any, o ...jsonv2.Options) ([]byte, error) {
+		buf := bytes.NewBuffer(dst)
+		err := jsonv2.MarshalWrite(buf, v, o...)
+		out = buf.Bytes()
+		return out[len(dst):], err
+	}
+	b, err := c.marshal(context.Background(), callSite{}, nil, jsonv1Marshal, marshalV2, v, o...)
+	switch {
+	case err != nil:
+		return dst, err
+	case len(out) == len(dst)+len(b) && (len(b) == 0 || &out[len(dst)] == &b[0]):
+		return out, nil // b is the v2 output already within out
+	default:
+		return append(dst, b...), nil
+	}
+}
+
+// UnmarshalRead is like [Codec.Unmarshal], but reads the input from r
+// until [io.EOF] for callers that operate in terms of an [io.Reader]
+// (e.g., an HTTP request body).
+// The input is buffered internally, such that both v1 and v2
+// can be called and compared against the same input.
+func (c *Codec) UnmarshalRead(r io.Reader, v any, o ...jsonv2.Options) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal(b, v, o...)
+}
+
+// MarshalEncode is like [Codec.Marshal], but writes the output
+// as the next value in enc for callers that operate in terms of
+// a [jsontext.Encoder]. The output is buffered internally, such that
+// both v1 and v2 can be called and compared, and is then written with
+// [jsontext.Encoder.WriteValue], which formats it according to
+// the options of enc. Unlike [jsonv2.MarshalEncode],
+// the options of enc do not otherwise affect marshaling.
+func (c *Codec) MarshalEncode(enc *jsontext.Encoder, v any, o ...jsonv2.Options) error {
+	b, err := c.Marshal(v, o...)
+	if err != nil {
+		return err
+	}
+	return enc.WriteValue(b)
+}
+
+// UnmarshalDecode is like [Codec.Unmarshal], but reads the input
+// as the next value in dec for callers that operate in terms of
+// a [jsontext.Decoder]. The value is read with [jsontext.Decoder.ReadValue],
+// such that both v1 and v2 can be called and compared against the same input.
+// Unlike [jsonv2.UnmarshalDecode],
+// the options of dec do not otherwise affect unmarshaling.
+func (c *Codec) UnmarshalDecode(dec *jsontext.Decoder, v any, o ...jsonv2.Options) error {
+	b, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal(b, v, o...)
+}
+
+// MarshalContext is like [Codec.Marshal], but attributes any differences
+// to the caller label carried by ctx (see [WithCallerLabel]), if any,
+// and records the profiler labels carried by ctx in [Difference.Labels].
+func (c *Codec) MarshalContext(ctx context.Context, v any, o ...jsonv2.Options) ([]byte, error) {
+	return c.marshal(ctx, callSite{label: callerLabel(ctx)}, nil, jsonv1Marshal, jsonv2.Marshal, v, o...)
+}
+
+// UnmarshalContext is like [Codec.Unmarshal], but attributes any differences
+// to the caller label carried by ctx (see [WithCallerLabel]), if any,
+// and records the profiler labels carried by ctx in [Difference.Labels].
+func (c *Codec) UnmarshalContext(ctx context.Context, b []byte, v any, o ...jsonv2.Options) error {
+	return c.unmarshal(ctx, callSite{label: callerLabel(ctx)}, nil, b, v, o...)
+}
+
+// WithCallerLabel returns a view of c whose calls attribute any differences
+// to the provided label instead of the source location of the caller.
+// Labels are stable human-chosen names (e.g., "checkout-v2-handler")
+// that do not change as the surrounding source code is edited.
+// It panics if the label is empty.
+func (c *Codec) WithCallerLabel(label string) LabeledCodec {
+	if label == "" {
+		panic("empty caller label")
+	}
+	return LabeledCodec{c, label}
+}
+
+// LabeledCodec is a [Codec] whose calls attribute any differences
+// to a fixed caller label. See [Codec.WithCallerLabel].
+type LabeledCodec struct {
+	codec *Codec
+	label string
+}
+
+// Marshal is like [Codec.Marshal], but attributes any differences to the label.
+func (lc LabeledCodec) Marshal(v any, o ...jsonv2.Options) ([]byte, error) {
+	return lc.codec.marshal(context.Background(), callSite{label: lc.label}, nil, jsonv1Marshal, jsonv2.Marshal, v, o...)
+}
+
+// Unmarshal is like [Codec.Unmarshal], but attributes any differences to the label.
+func (lc LabeledCodec) Unmarshal(b []byte, v any, o ...jsonv2.Options) error {
+	return lc.codec.unmarshal(context.Background(), callSite{label: lc.label}, nil, b, v, o...)
+}
+
 // SetMarshalCallRatio sets the ratio of [Codec.Marshal] calls
 // that will use the marshal functionality of v1, v2, or both.
 //
@@ -834,6 +2398,7 @@ func (c *Codec) Unmarshal(b []byte, v any, o ...jsonv2.Options) (err error) {
 // This is safe to call concurrently with [Codec.Marshal].
 func (c *Codec) SetMarshalCallRatio(mode1, mode2 CallMode, ratio float64) {
 	c.marshalCallRatio.storeModeRatio(mode1, mode2, float32(ratio))
+	c.notifyConfig()
 }
 
 // SetMarshalCallMode specifies the [CallMode] for marshaling.
@@ -841,15 +2406,26 @@ func (c *Codec) SetMarshalCallRatio(mode1, mode2 CallMode, ratio float64) {
 // This is safe to call concurrently with [Codec.Marshal].
 func (c *Codec) SetMarshalCallMode(mode CallMode) {
 	c.marshalCallRatio.storeModeRatio(mode, mode, 1.0)
+	c.notifyConfig()
 }
 
 // MarshalCallRatio retrieves the mode and ratio parameters
 // previously set by [Codec.SetMarshalCallRatio].
+// If c was created by [Codec.NewChild] and the ratio was not set on c,
+// it reports the ratio of the parent.
 func (c *Codec) MarshalCallRatio() (mode1, mode2 CallMode, ratio float64) {
-	mode1, mode2, ratio32 := c.marshalCallRatio.loadModeRatio()
+	mode1, mode2, ratio32 := c.marshalRatio().loadModeRatio()
 	return mode1, mode2, float64(ratio32)
 }
 
+// MayCompareMarshal reports whether the current marshal call ratio
+// (see [Codec.SetMarshalCallRatio]) may select a mode that calls
+// both v1 and v2, such that callers can cheaply avoid any preparation
+// for a comparison that cannot occur (e.g., buffering the output).
+func (c *Codec) MayCompareMarshal() bool {
+	return c.marshalRatio().mayCompare()
+}
+
 // SetUnmarshalCallRatio sets the ratio of [Codec.Unmarshal] calls
 // that will use the unmarshal functionality of v1, v2, or both.
 //
@@ -871,6 +2447,7 @@ func (c *Codec) MarshalCallRatio() (mode1, mode2 CallMode, ratio float64) {
 // This is safe to call concurrently with [Codec.Unmarshal].
 func (c *Codec) SetUnmarshalCallRatio(mode1, mode2 CallMode, ratio float64) {
 	c.unmarshalCallRatio.storeModeRatio(mode1, mode2, float32(ratio))
+	c.notifyConfig()
 }
 
 // SetUnmarshalCallMode specifies the [CallMode] for unmarshaling.
@@ -878,18 +2455,85 @@ func (c *Codec) SetUnmarshalCallRatio(mode1, mode2 CallMode, ratio float64) {
 // This is safe to call concurrently with [Codec.Unmarshal].
 func (c *Codec) SetUnmarshalCallMode(mode CallMode) {
 	c.unmarshalCallRatio.storeModeRatio(mode, mode, 1.0)
+	c.notifyConfig()
 }
 
 // UnmarshalCallRatio retrieves the mode and ratio parameters
 // previously set by [Codec.SetUnmarshalCallRatio].
+// If c was created by [Codec.NewChild] and the ratio was not set on c,
+// it reports the ratio of the parent.
 func (c *Codec) UnmarshalCallRatio() (mode1, mode2 CallMode, ratio float64) {
-	mode1, mode2, ratio32 := c.unmarshalCallRatio.loadModeRatio()
+	mode1, mode2, ratio32 := c.unmarshalRatio().loadModeRatio()
 	return mode1, mode2, float64(ratio32)
 }
 
+// MayCompareUnmarshal reports whether the current unmarshal call ratio
+// (see [Codec.SetUnmarshalCallRatio]) may select a mode that calls
+// both v1 and v2, such that callers can cheaply avoid any preparation
+// for a comparison that cannot occur (e.g., buffering the input).
+func (c *Codec) MayCompareUnmarshal() bool {
+	return c.unmarshalRatio().mayCompare()
+}
+
+// SetGoValueCaptureRatio sets the ratio of reported differences that include
+// the Go values in [Difference.GoValue], [Difference.GoValueV1],
+// and [Difference.GoValueV2]. The ratio must be within 0 and 1.
+// For unsampled reports, those fields are nil, but all other fields
+// (e.g., the caller, type, and options) are still populated.
+// This balances debuggability against the cost and sensitivity
+// of retaining full Go values within the reporter.
+//
+// By default, Go values are always captured (i.e., a ratio of 1).
+// This is safe to call concurrently with [Codec.Marshal] and [Codec.Unmarshal].
+func (c *Codec) SetGoValueCaptureRatio(ratio float64) {
+	if ratio != min(max(0, ratio), 1) {
+		panic("ratio out of range")
+	}
+	c.goValueOmitRatio.Store(math.Float32bits(float32(1 - ratio)))
+	c.goValueOmitRatioSet.Store(true)
+	c.notifyConfig()
+}
+
+// GoValueCaptureRatio retrieves the ratio
+// previously set by [Codec.SetGoValueCaptureRatio].
+// If c was created by [Codec.NewChild] and the ratio was not set on c,
+// it reports the ratio of the parent.
+func (c *Codec) GoValueCaptureRatio() float64 {
+	return float64(1 - c.loadGoValueOmitRatio())
+}
+
+// marshalRatio returns the marshal call mode ratio of c,
+// which is resolved through the ancestors of c unless set on c.
+func (c *Codec) marshalRatio() *callModeRatio {
+	for c.parent != nil && !c.marshalCallRatio.set.Load() {
+		c = c.parent
+	}
+	return &c.marshalCallRatio
+}
+
+// unmarshalRatio returns the unmarshal call mode ratio of c,
+// which is resolved through the ancestors of c unless set on c.
+func (c *Codec) unmarshalRatio() *callModeRatio {
+	for c.parent != nil && !c.unmarshalCallRatio.set.Load() {
+		c = c.parent
+	}
+	return &c.unmarshalCallRatio
+}
+
+// loadGoValueOmitRatio loads 1 minus the Go value capture ratio of c,
+// which is resolved through the ancestors of c unless set on c.
+func (c *Codec) loadGoValueOmitRatio() float32 {
+	for c.parent != nil && !c.goValueOmitRatioSet.Load() {
+		c = c.parent
+	}
+	return math.Float32frombits(c.goValueOmitRatio.Load())
+}
+
 // callModeRatio non-deterministically determines which call mode to use.
 type callModeRatio struct {
 	atomic.Uint64 // [0:16) is mode1, [16:32) is mode2, and [32:] is the ratio as raw float32
+
+	set atomic.Bool // whether the ratio was stored, otherwise it is inherited
 }
 
 // storeModeRatio stores a call mode ratio.
@@ -905,6 +2549,7 @@ func (p *callModeRatio) storeModeRatio(mode1, mode2 CallMode, ratio float32) {
 		uint64(mode2&0xffff)<<16 |
 		uint64(math.Float32bits(float32(ratio)))<<32
 	p.Store(u)
+	p.set.Store(true)
 }
 
 func (p *callModeRatio) loadModeRatio() (mode1, mode2 CallMode, ratio float32) {
@@ -915,8 +2560,22 @@ func (p *callModeRatio) loadModeRatio() (mode1, mode2 CallMode, ratio float32) {
 	return mode1, mode2, ratio
 }
 
+// mayCompare reports whether [callModeRatio.loadRandomMode]
+// may return a mode that calls both v1 and v2.
+func (p *callModeRatio) mayCompare() bool {
+	if forcedMode, ok := forcedCallMode(); ok {
+		return forcedMode.mayCompare()
+	}
+	mode1, mode2, ratio := p.loadModeRatio()
+	return (ratio < 1 && mode1.mayCompare()) || (ratio > 0 && mode2.mayCompare())
+}
+
 // loadRandomMode loads a random mode according to the ratio.
+// If [ForceModeEnv] is set, then it always returns the forced mode.
 func (p *callModeRatio) loadRandomMode() CallMode {
+	if forcedMode, ok := forcedCallMode(); ok {
+		return forcedMode
+	}
 	mode1, mode2, ratio := p.loadModeRatio()
 	if ratio < 1 && rand.Float32() >= ratio {
 		return mode1
@@ -925,47 +2584,417 @@ func (p *callModeRatio) loadRandomMode() CallMode {
 	}
 }
 
+// ForceModeEnv is the name of an environment variable that,
+// if set to the name of a [CallMode] (e.g., "CallBothButReturnV1"),
+// overrides the call mode ratios of every [Codec] with that fixed mode.
+// This is intended for integration tests that need to deterministically
+// exercise comparison between v1 and v2 regardless of application config.
+// The environment variable is only read once upon package initialization
+// (i.e., before any [Codec] is used). An invalid value is logged and ignored.
+const ForceModeEnv = "JSONSPLIT_FORCE_MODE"
+
+// forcedMode and forcedModeOK are the call mode specified by [ForceModeEnv].
+var forcedMode, forcedModeOK = parseForcedCallMode(os.Getenv(ForceModeEnv))
+
+// forcedCallMode reports the call mode specified by [ForceModeEnv], if any.
+func forcedCallMode() (CallMode, bool) {
+	return forcedMode, forcedModeOK
+}
+
+// parseForcedCallMode parses the value of [ForceModeEnv],
+// logging and ignoring a value that is not the name of a [CallMode].
+func parseForcedCallMode(s string) (CallMode, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for mode, name := range callModeNames {
+		if name == s {
+			return mode, true
+		}
+	}
+	log.Printf("jsonsplit: ignoring invalid %s value: %q", ForceModeEnv, s)
+	return 0, false
+}
+
 // ExpVar returns an expvar mapping of all metrics.
-// It reports variables with the snake case form of each field in [CodecMetrics].
+// It reports variables with the same names as [CodecMetrics.All].
 func (c *CodecMetrics) ExpVar() expvar.Var {
 	var m expvar.Map
-	v := reflect.ValueOf(c).Elem()
-	for i := range v.NumField() {
-		name := v.Type().Field(i).Name
-		value := v.Field(i).Addr().Interface().(expvar.Var)
-
-		// Convert PascalCase to snake_case.
-		var rs []rune
-		for i, r := range name {
-			if unicode.IsUpper(r) {
-				if i > 0 {
-					rs = append(rs, '_')
+	for name, value := range c.All() {
+		m.Set(name, value)
+	}
+	return &m
+}
+
+// All iterates over all metrics in field order, where each name is
+// the snake case form of the field name in [CodecMetrics]
+// (e.g., "num_marshal_total" for [CodecMetrics.NumMarshalTotal])
+// and each value is a pointer to the field (e.g., an [*expvar.Int]).
+// It allows exporters and tests to generically enumerate all metrics.
+func (c *CodecMetrics) All() iter.Seq2[string, expvar.Var] {
+	return func(yield func(string, expvar.Var) bool) {
+		v := reflect.ValueOf(c).Elem()
+		for i := range v.NumField() {
+			name := v.Type().Field(i).Name
+			value := v.Field(i).Addr().Interface().(expvar.Var)
+
+			// Convert PascalCase to snake_case.
+			var rs []rune
+			for i, r := range name {
+				if unicode.IsUpper(r) {
+					if i > 0 {
+						rs = append(rs, '_')
+					}
+					r = unicode.ToLower(r)
 				}
-				r = unicode.ToLower(r)
+				rs = append(rs, r)
+			}
+			name = string(rs)
+
+			if !yield(name, value) {
+				return
 			}
-			rs = append(rs, r)
 		}
-		name = string(rs)
+	}
+}
 
-		m.Set(name, value)
+// reportDifference calls [Codec.ReportDifference] with d
+// and delivers it to any subscribers of [Codec.Differences]
+// unless it is suppressed as an already known difference,
+// which occurs asynchronously for [Codec.AsyncReportQueueSize].
+// The caller must check that [Codec.reportsDifferences] is true.
+func (c *Codec) reportDifference(d Difference) {
+	c.analyzeDifference(d, func(d Difference) {
+		c.reportDifferenceTo(c.publishDifference, d)
+	})
+}
+
+// emulationRegressionReason is the [Difference.DetectionFailedReason]
+// when v2 with the v1 default options still behaves differently from v1.
+const emulationRegressionReason = "sanity check failed: v2 configured with jsonv1.DefaultOptionsV1 behaves differently from v1 (probable v1 emulation bug)"
+
+// reportEmulationRegression records that v2 with the v1 default options
+// behaves differently from v1 for the difference d.
+func (c *Codec) reportEmulationRegression(d Difference) {
+	c.add(&c.NumEmulationRegressions, 1)
+	if c.ReportEmulationRegression != nil {
+		d.Check = "EmulationRegression"
+		c.reportDifferenceTo(c.ReportEmulationRegression, d)
 	}
-	return &m
+}
+
+// reportDifferenceTo synchronously calls report with d unless it is filtered
+// by severity or suppressed as an already known or too frequently repeated difference.
+// The severity of d must already be classified by [Codec.classifyDifference].
+func (c *Codec) reportDifferenceTo(report func(Difference), d Difference) {
+	if !c.reportsSeverity(d.Severity) {
+		c.add(&c.NumDifferencesSeverityFiltered, 1)
+		return
+	}
+	// The divergence is part of the fingerprint, so it is only located
+	// before suppressing differences if suppression is enabled.
+	var located bool
+	locateDivergence := func() {
+		if !located && len(d.JSONValueV1) > 0 && len(d.JSONValueV2) > 0 {
+			d.JSONValuePointer, d.JSONValueOffset, _ = jsonValueDivergence(d.JSONValueV1, d.JSONValueV2)
+		}
+		located = true
+	}
+	if c.KnownDifferences != nil || c.MaxReportsPerDifference > 0 {
+		locateDivergence()
+	}
+	if c.KnownDifferences != nil && !c.KnownDifferences.insert(d.Fingerprint()) {
+		c.add(&c.NumDifferencesSuppressed, 1)
+		return
+	}
+	if c.MaxReportsPerDifference > 0 {
+		n, _ := c.reportCounts.LoadOrStore(d.Fingerprint(), new(atomic.Int64))
+		if n.(*atomic.Int64).Add(1) > int64(c.MaxReportsPerDifference) {
+			c.add(&c.NumDifferencesRepeatSuppressed, 1)
+			return
+		}
+	}
+	if d.FieldDifferences == nil {
+		d.FieldDifferences = fieldDifferences(d.GoValueV1, d.GoValueV2)
+	}
+	if c.DiffGoValues && (d.GoValueV1 != nil || d.GoValueV2 != nil) {
+		d.GoValueDiff = goValueDiff(d.GoValueV1, d.GoValueV2)
+	}
+	if omitRatio := c.loadGoValueOmitRatio(); omitRatio > 0 && rand.Float32() < omitRatio {
+		d.GoValue, d.GoValueV1, d.GoValueV2 = nil, nil, nil
+	}
+	if c.CopyDifferenceValues && c.AsyncReportQueueSize <= 0 {
+		d = d.clone() // already copied by analyzeDifference if asynchronous
+	}
+	d.ID = newID(time.Now())
+	locateDivergence()
+	if d.SuggestedCall == "" {
+		d.SuggestedCall = suggestedCall(d)
+	}
+	if d.TagSuggestions == nil {
+		d.TagSuggestions = tagSuggestions(d)
+	}
+	if d.OptionCategories == nil {
+		d.OptionCategories = optionCategories(d)
+	}
+	if d.OptionScopes == nil {
+		d.OptionScopes = optionScopes(d)
+	}
+	if d.OptionsV1 == nil {
+		d.OptionsV1 = jsonv2.JoinOptions(jsonv1.DefaultOptionsV1(), d.CallerOptions)
+	}
+	if d.OptionsV2 == nil {
+		d.OptionsV2 = jsonv2.JoinOptions(jsonv2.DefaultOptionsV2(), d.CallerOptions)
+	}
+	c.insertSize(&c.DifferenceSizeHistogram, d.estimatedSize())
+	report(d)
+}
+
+// classifyDifference classifies the severity of a detected difference d
+// and counts it in [CodecMetrics.SeverityHistogram].
+// It must be called for every detected difference before it is sampled
+// (see [Codec.sampleReport]) or filtered so that all severities are counted.
+func (c *Codec) classifyDifference(d *Difference) {
+	d.Severity = severityOf(*d)
+	c.addKey(&c.SeverityHistogram, d.Severity.String(), 1)
+}
+
+// sampleReport reports whether to attribute and report a detected difference
+// according to [Codec.ReportSampleRate].
+func (c *Codec) sampleReport() bool {
+	if r := c.ReportSampleRate; r <= 0 || r >= 1 || rand.Float64() < r {
+		return true
+	}
+	c.add(&c.NumDifferencesUnsampled, 1)
+	return false
+}
+
+// sampleSize reports whether to compare a JSON value of the given size
+// according to [Codec.CompareSizeProbability].
+func (c *Codec) sampleSize(size int) bool {
+	if c.CompareSizeProbability == nil {
+		return true
+	}
+	p := c.CompareSizeProbability(size)
+	return p >= 1 || rand.Float64() < p
+}
+
+// InverseSizeProbability returns a function for use with
+// [Codec.CompareSizeProbability] that always compares JSON values
+// up to the specified size, and beyond that compares with a probability
+// inversely proportional to the size (e.g., a value that is 10x larger
+// than size is compared 10% of the time).
+func InverseSizeProbability(size int) func(int) float64 {
+	return func(n int) float64 {
+		if n <= size {
+			return 1
+		}
+		return float64(size) / float64(n)
+	}
+}
+
+// withDefaultOptions returns the options o specified by the caller
+// with [Codec.DefaultOptions] and the options typeOpts registered for
+// the Go type by [RegisterTypeOptions] applied underneath them.
+func (c *Codec) withDefaultOptions(typeOpts jsonv2.Options, o []jsonv2.Options) []jsonv2.Options {
+	if c.DefaultOptions == nil && typeOpts == nil {
+		return o
+	}
+	var defaults []jsonv2.Options
+	for _, opts := range []jsonv2.Options{c.DefaultOptions, typeOpts} {
+		if opts != nil {
+			defaults = append(defaults, opts)
+		}
+	}
+	return append(defaults, o...)
+}
+
+// detectCausingOptions reports the subset of the options o explicitly
+// passed by the caller that cause a difference, where bothEqual calls
+// both v1 and v2 with the provided options and reports whether
+// the results are equal. An option is a cause if omitting it alone
+// makes v1 and v2 behave identically. It returns nil if there are none.
+func detectCausingOptions(bothEqual func(...jsonv2.Options) bool, o ...jsonv2.Options) jsonv2.Options {
+	var causes []jsonv2.Options
+	for i := range o {
+		if bothEqual(slices.Concat(o[:i], o[i+1:])...) {
+			causes = append(causes, o[i])
+		}
+	}
+	return callerOptions(causes)
+}
+
+// detectOptions calls [autoDetectOptions] and records the outcome
+// in [CodecMetrics]. The detected options are considered to resolve
+// the difference only if arshalEqual reports true with them enabled.
+//
+// If cached is non-nil, the options it holds are tried first and
+// auto-detection is skipped if they resolve the difference.
+// Otherwise, any newly detected options that resolve the difference are stored.
+// It reports whether the options resolve the difference and
+// whether v2 emulates v1 (see [autoDetectOptions]).
+// Only auto-detection consumes the detection budget for the Go type t,
+// and if it is exhausted, it reports that nothing was detected.
+//
+// If the detected options do not resolve the difference, combinations
+// of additional options are searched at most once for the operation op
+// (e.g., "Marshal") on t, where the search for pairs of options
+// consumes another detection from the budget.
+func (c *Codec) detectOptions(op string, t reflect.Type, cached *atomic.Pointer[jsonv2.Options], arshalEqual func(...jsonv2.Options) bool, o ...jsonv2.Options) (options jsonv2.Options, resolved, emulated, detected bool) {
+	if cached != nil {
+		if p := cached.Load(); p != nil && arshalEqual(append(slices.Clip(o), *p)...) {
+			c.add(&c.NumAutoDetectResolved, 1)
+			return *p, true, true, true
+		}
+	}
+	if !c.allowDetection(t) {
+		return nil, false, true, false
+	}
+	options, emulated = autoDetectOptions(c.autoDetectParallelism(), arshalEqual, o...)
+	if !emulated {
+		return options, false, false, true // counted by reportEmulationRegression
+	}
+	resolved = arshalEqual(append(slices.Clip(o), options)...)
+	if k := (opTypeKey{op, t}); !resolved {
+		if _, ok := c.unexplained.Load(k); !ok {
+			options, resolved = searchOptionCombinations(arshalEqual, func() bool { return c.allowDetection(t) }, options, o...)
+			if resolved {
+				c.add(&c.NumAutoDetectCombinations, 1)
+			} else {
+				c.unexplained.Store(k, struct{}{})
+			}
+		}
+	}
+	if resolved {
+		c.add(&c.NumAutoDetectResolved, 1)
+		if reduced, ok := minimizeOptions(arshalEqual, options, o...); ok {
+			c.add(&c.NumAutoDetectReduced, 1)
+			options = reduced
+		}
+		if cached != nil {
+			cached.Store(&options)
+		}
+	} else {
+		c.add(&c.NumAutoDetectUnexplained, 1)
+	}
+	return options, resolved, emulated, true
+}
+
+// searchOptionCombinations searches for up to two additional options
+// that resolve the difference together with the detected options,
+// for when the options that are individually significant
+// do not explain the entire difference (e.g., an option that only
+// matters in conjunction with another option that is not enabled).
+// Every single option is tried before trying every pair of options,
+// which is only done if allowPairs reports true since it takes
+// a quadratic number of calls in the number of options.
+// It reports the detected options as is if no combination is found.
+func searchOptionCombinations(arshalEqual func(...jsonv2.Options) bool, allowPairs func() bool, detected jsonv2.Options, o ...jsonv2.Options) (jsonv2.Options, bool) {
+	optsCall := jsonv2.JoinOptions(o...)
+	var candidates []jsonv2.Options
+	for _, p := range optionProbes() {
+		if _, ok := jsonv2.GetOption(optsCall, p.option); ok {
+			continue // explicitly overwritten by caller, so ignore
+		}
+		if v, _ := jsonv2.GetOption(detected, p.option); v {
+			continue // already detected
+		}
+		candidates = append(candidates, p.option(true))
+	}
+	base := slices.Clip(append(slices.Clip(o), detected))
+	for i := range candidates {
+		if arshalEqual(append(base, candidates[i])...) {
+			return jsonv2.JoinOptions(detected, candidates[i]), true
+		}
+	}
+	if !allowPairs() {
+		return detected, false
+	}
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if arshalEqual(append(base, candidates[i], candidates[j])...) {
+				return jsonv2.JoinOptions(detected, candidates[i], candidates[j]), true
+			}
+		}
+	}
+	return detected, false
+}
+
+// minimizeOptions reduces options that resolve the difference
+// to a minimal set by dropping every option that is redundant given
+// the remaining options, where dropping it still resolves the difference
+// (e.g., an option whose effect is subsumed by another option).
+// Options are dropped one at a time in the order of [optionNames],
+// such that the result is irredundant but not necessarily the smallest.
+// Options that are not probed (e.g., formatting options) are never dropped.
+// It reports whether any options were dropped.
+func minimizeOptions(arshalEqual func(...jsonv2.Options) bool, options jsonv2.Options, o ...jsonv2.Options) (jsonv2.Options, bool) {
+	names := slices.Collect(optionNames(options))
+	if len(names) < 2 {
+		return options, false
+	}
+	var dropped bool
+	for i := 0; i < len(names); {
+		if _, ok := lookupOption(names[i]); !ok {
+			i++
+			continue
+		}
+		rest := slices.Concat(names[:i], names[i+1:])
+		if arshalEqual(append(slices.Clip(o), parseOptionNames(rest))...) {
+			names, dropped = rest, true
+			continue
+		}
+		i++
+	}
+	if !dropped {
+		return options, false
+	}
+	return parseOptionNames(names), true
+}
+
+func (c *Codec) autoDetectParallelism() int {
+	if c.AutoDetectParallelism < 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return c.AutoDetectParallelism
 }
 
 func (c *Codec) jsonEqual(v1, v2 jsontext.Value) bool {
+	if len(c.Transformers) > 0 {
+		v1 = transformValue(c.Transformers, v1)
+		v2 = transformValue(c.Transformers, v2)
+	}
 	if c.EqualJSONValues != nil {
 		return c.EqualJSONValues(v1, v2)
 	}
 	return bytes.Equal(v1, v2)
 }
 
-func (c *Codec) goEqual(v1, v2 any) bool {
+// goEqual reports whether the Go values are equal,
+// where size is the size of the JSON input the values were unmarshaled from.
+func (c *Codec) goEqual(v1, v2 any, size int) bool {
+	v1 = transformGoValue(c.Transformers, v1)
+	v2 = transformGoValue(c.Transformers, v2)
+	if c.HashGoValuesAboveSize > 0 && size > c.HashGoValuesAboveSize {
+		if equal, ok := hashEqual(v1, v2); ok {
+			return equal
+		}
+	}
 	if c.EqualGoValues != nil {
 		return c.EqualGoValues(v1, v2)
 	}
 	return reflect.DeepEqual(v1, v2)
 }
 
+// unmarshalEqual reports whether the results of v1 and v2 unmarshal are equal,
+// where size is the size of the JSON input.
+func (c *Codec) unmarshalEqual(val1, val2 any, err1, err2 error, size int) bool {
+	if c.IgnoreGoValuesOnErrors && err1 != nil && err2 != nil {
+		return c.errorsEqual(err1, err2)
+	}
+	return c.goEqual(val1, val2, size) && c.errorsEqual(err1, err2)
+}
+
 func (c *Codec) errorsEqual(err1, err2 error) bool {
 	if c.EqualErrors != nil {
 		return c.EqualErrors(err1, err2)
@@ -1061,6 +3090,27 @@ func jsonv1Marshal(v any, o ...jsonv2.Options) ([]byte, error) {
 	}
 }
 
+// jsonv1MarshalIndent is like [jsonv1.MarshalIndent],
+// but allows specifying options to override default v1 behavior.
+func jsonv1MarshalIndent(v any, prefix, indent string, o ...jsonv2.Options) ([]byte, error) {
+	switch {
+	case len(o) == 0:
+		return jsonv1.MarshalIndent(v, prefix, indent)
+	case len(o) == 1 && o[0] == jsonv1.DefaultOptionsV1():
+		return jsonv1std.MarshalIndent(v, prefix, indent)
+	default:
+		b, err := jsonv1Marshal(v, o...)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := jsonv1.Indent(&buf, b, prefix, indent); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
 // jsonv1Unmarshal is like [jsonv1.Unmarshal],
 // but allows specifying options to override default v1 behavior.
 func jsonv1Unmarshal(b []byte, v any, o ...jsonv2.Options) error {
@@ -1119,23 +3169,76 @@ func (h *SizeHistogram) insertSize(n int) {
 func (h *SizeHistogram) MarshalJSON() ([]byte, error) {
 	var b []byte
 	b = append(b, '{')
-	const prefixes = "  " + "Ki" + "Mi" + "Gi" + "Ti" + "Pi" + "Ei"
-	for i := range h {
-		if n := h[i].Value(); n > 0 {
-			b = append(b, '"', '<')
-			b = strconv.AppendInt(b, 1<<(i%10), 10)
-			b = append(b, prefixes[2*(i/10):][:2]...)
-			b = bytes.TrimRight(b, " ")
-			b = append(b, 'B', '"', ':')
-			b = strconv.AppendInt(b, n, 10)
-			b = append(b, ',')
-		}
+	for name, n := range h.all() {
+		b = append(b, '"')
+		b = append(b, name...)
+		b = append(b, '"', ':')
+		b = strconv.AppendInt(b, n, 10)
+		b = append(b, ',')
 	}
 	b = bytes.TrimRight(b, ",")
 	b = append(b, '}')
 	return b, nil
 }
 
+// all iterates over all non-zero buckets in the histogram,
+// where each bucket is named according to [SizeHistogram.MarshalJSON].
+func (h *SizeHistogram) all() iter.Seq2[string, int64] {
+	return func(yield func(string, int64) bool) {
+		const prefixes = "  " + "Ki" + "Mi" + "Gi" + "Ti" + "Pi" + "Ei"
+		var b []byte
+		for i := range h {
+			if n := h[i].Value(); n > 0 {
+				b = append(b[:0], '<')
+				b = strconv.AppendInt(b, 1<<(i%10), 10)
+				b = append(b, prefixes[2*(i/10):][:2]...)
+				b = bytes.TrimRight(b, " ")
+				b = append(b, 'B')
+				if !yield(string(b), n) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Quantile returns an approximation of the p-quantile of the sizes
+// observed in the histogram, where p must be within 0 and 1
+// (e.g., a p of 0.99 returns the 99th percentile).
+// The size is linearly interpolated within the bucket containing the quantile,
+// so the result may be off by up to a factor of 2.
+// It returns 0 if the histogram is empty.
+func (h *SizeHistogram) Quantile(p float64) float64 {
+	if !(0 <= p && p <= 1) {
+		panic("quantile out of range")
+	}
+	var counts [len(h)]int64
+	var total int64
+	for i := range h {
+		counts[i] = h[i].Value()
+		total += counts[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := p * float64(total)
+	var cumulative int64
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		if float64(cumulative+n) >= rank {
+			if i == 0 {
+				return 0 // the first bucket only contains a size of zero
+			}
+			lo, hi := math.Ldexp(1, i-1), math.Ldexp(1, i)
+			return lo + (hi-lo)*(rank-float64(cumulative))/float64(n)
+		}
+		cumulative += n
+	}
+	panic("unreachable")
+}
+
 // String returns the histogram as JSON.
 // It implements both [fmt.Stringer] and [expvar.Var].
 func (h *SizeHistogram) String() string {
@@ -1143,6 +3246,94 @@ func (h *SizeHistogram) String() string {
 	return string(b)
 }
 
+// CallerDiffRates tracks the rate of differences per caller.
+// Unlike a histogram of callers that only counts differences,
+// this allows ranking callers by how often they diverge
+// rather than by raw volume, which is dominated by traffic.
+// It is only populated if [Codec.TrackCallerDiffRates] is enabled.
+//
+// To bound memory usage, it tracks at most [MaxCallerDiffRates] callers.
+// Calls from any subsequently observed callers are aggregated
+// under the [OtherCallers] key.
+type CallerDiffRates struct {
+	mu sync.Mutex
+	m  map[string]*CallerDiffRate
+}
+
+// CallerDiffRate is the number of compared calls for a given caller
+// and the number of those calls that detected a difference.
+type CallerDiffRate struct {
+	Calls int64   `json:"calls"`
+	Diffs int64   `json:"diffs"`
+	Rate  float64 `json:"rate"` // Diffs divided by Calls
+}
+
+const (
+	// MaxCallerDiffRates is the maximum number of distinct callers
+	// tracked by [CallerDiffRates] (excluding [OtherCallers]).
+	MaxCallerDiffRates = 1000
+
+	// OtherCallers is the key in [CallerDiffRates] that aggregates
+	// the calls from callers beyond the first [MaxCallerDiffRates] callers.
+	OtherCallers = "(other)"
+)
+
+func (r *CallerDiffRates) observe(caller string, hasDiff bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cr := r.m[caller]
+	if cr == nil {
+		if r.m == nil {
+			r.m = make(map[string]*CallerDiffRate)
+		}
+		if len(r.m) >= MaxCallerDiffRates {
+			caller = OtherCallers
+		}
+		if cr = r.m[caller]; cr == nil {
+			cr = new(CallerDiffRate)
+			r.m[caller] = cr
+		}
+	}
+	cr.Calls++
+	if hasDiff {
+		cr.Diffs++
+	}
+	cr.Rate = float64(cr.Diffs) / float64(cr.Calls)
+}
+
+// All iterates over the rate of differences for every caller,
+// sorted by caller name.
+func (r *CallerDiffRates) All() iter.Seq2[string, CallerDiffRate] {
+	r.mu.Lock()
+	rates := make(map[string]CallerDiffRate, len(r.m))
+	for caller, cr := range r.m {
+		rates[caller] = *cr
+	}
+	r.mu.Unlock()
+	return func(yield func(string, CallerDiffRate) bool) {
+		for _, caller := range slices.Sorted(maps.Keys(rates)) {
+			if !yield(caller, rates[caller]) {
+				return
+			}
+		}
+	}
+}
+
+// MarshalJSON marshals the rates as a JSON object where
+// each name is the caller and each value is a [CallerDiffRate].
+func (r *CallerDiffRates) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return jsonv2.Marshal(r.m, jsonv2.Deterministic(true))
+}
+
+// String returns the rates as JSON.
+// It implements both [fmt.Stringer] and [expvar.Var].
+func (r *CallerDiffRates) String() string {
+	b, _ := r.MarshalJSON()
+	return string(b)
+}
+
 // autoDetectOptions automatically detects which options
 // need to be specified to [jsonv2.Marshal] or [jsonv2.Unmarshal]
 // in order for it to preserve the same behavior as v1.
@@ -1150,65 +3341,122 @@ func (h *SizeHistogram) String() string {
 // The arshalEqual function runs [jsonv2.Marshal] or [jsonv2.Unmarshal]
 // function with the provided options and reports whether
 // the output is identical to the results from v1.
-func autoDetectOptions(arshalEqual func(...jsonv2.Options) bool, o ...jsonv2.Options) jsonv2.Options {
-	optsCall := jsonv2.JoinOptions(o...)                              // explicit options by caller
-	optsV1 := jsonv2.JoinOptions(jsonv1.DefaultOptionsV1(), optsCall) // caller options using v1 defaults
+// Up to parallelism calls of arshalEqual may run concurrently.
+// It reports false if v2 with the v1 default options is not equal to v1,
+// in which case no options can be detected (see [Codec.ReportEmulationRegression]).
+func autoDetectOptions(parallelism int, arshalEqual func(...jsonv2.Options) bool, o ...jsonv2.Options) (jsonv2.Options, bool) {
+	probes := optionProbes()
+	optsCall := jsonv2.JoinOptions(o...) // explicit options by caller
+	optsV1 := jsonv1.DefaultOptionsV1()
+	for _, p := range probes[len(builtinOptions):] {
+		optsV1 = jsonv2.JoinOptions(optsV1, p.option(true)) // registered probes using v1 semantics
+	}
+	optsV1 = jsonv2.JoinOptions(optsV1, optsCall) // caller options using v1 defaults
 
 	// As a sanity check, make sure using v1 options by default is equal to v1.
-	// If not, this suggestions that the v1 implementation in terms of v2
+	// If not, check whether the v1 output is merely formatted differently.
+	// Otherwise, this suggestions that the v1 implementation in terms of v2
 	// somehow has a regression bug and the detection logic below will fail.
+	var optsFormat jsonv2.Options
 	if !arshalEqual(optsV1) {
-		return nil
+		i := slices.IndexFunc(formatOptions, func(opts jsonv2.Options) bool {
+			return arshalEqual(optsV1, opts)
+		})
+		if i < 0 {
+			return nil, false
+		}
+		optsFormat = formatOptions[i]
+		optsV1 = jsonv2.JoinOptions(optsV1, optsFormat)
 	}
 
-	// TODO: The following algorithm runs in O(len(defaultOptionsV1)).
-	// This could be O(log₂(len(defaultOptionsV1))) with a binary search.
-
 	// TODO: The [jsonv2.Deterministic] option cannot be reliably detected
 	// without multiple runs due to it's non-deterministic nature.
 
-	// TODO: Some options are sub-options of others. A linear search may not
+	// TODO: Some options are sub-options of others. The search below may not
 	// properly detected them. For example, [jsonv1.MatchCaseSensitiveDelimiter]
 	// is only significant with [jsonv2.MatchCaseInsensitiveNames].
 
-	// Iterate through all the default options for v1 and
-	// set just a single v1 option to false and see if it affects equality.
-	// If not equal, then it means that this option is significant.
-	var opts []jsonv2.Options
-	for _, option := range defaultOptionsV1 {
-		if _, ok := jsonv2.GetOption(optsCall, option); ok {
+	// An option is significant if setting just that single v1 option
+	// to false affects equality. Rather than probing every option,
+	// use adaptive group testing: set an entire group of v1 options
+	// to false and only split the group in halves if it affects equality.
+	// Since a difference is typically explained by a few options,
+	// this runs in O(k·log₂(n)) calls for k significant options
+	// out of n probes instead of O(n) calls.
+	// A single option is always probed on its own so that the result
+	// is identical to probing each option individually,
+	// assuming that the effects of disabling options never cancel out
+	// (in which case a group may misleadingly not affect equality).
+	// If parallelism permits, both halves of a group are searched concurrently.
+	var candidates []optionProbe
+	for _, p := range probes {
+		if _, ok := jsonv2.GetOption(optsCall, p.option); ok {
 			continue // explicitly overwritten by caller, so ignore
 		}
-		if !arshalEqual(optsV1, option(false)) {
-			opts = append(opts, option(true)) // need this option enabled to maintain equality
-		}
-	}
-
-	return jsonv2.JoinOptions(opts...)
-}
-
-// defaultOptionsV1 is the set of all options in [jsonv1.DefaultOptionsV1].
-// TODO: We should support a way to iterate through all singular options.
-var defaultOptionsV1 = map[string]func(bool) jsonv2.Options{
-	"jsontext.AllowDuplicateNames":           jsontext.AllowDuplicateNames,
-	"jsontext.AllowInvalidUTF8":              jsontext.AllowInvalidUTF8,
-	"jsontext.EscapeForHTML":                 jsontext.EscapeForHTML,
-	"jsontext.EscapeForJS":                   jsontext.EscapeForJS,
-	"jsontext.PreserveRawStrings":            jsontext.PreserveRawStrings,
-	"jsonv1.CallMethodsWithLegacySemantics":  jsonv1.CallMethodsWithLegacySemantics,
-	"jsonv1.FormatByteArrayAsArray":          jsonv1.FormatByteArrayAsArray,
-	"jsonv1.FormatBytesWithLegacySemantics":  jsonv1.FormatBytesWithLegacySemantics,
-	"jsonv1.FormatDurationAsNano":            jsonv1.FormatDurationAsNano,
-	"jsonv1.MatchCaseSensitiveDelimiter":     jsonv1.MatchCaseSensitiveDelimiter,
-	"jsonv1.MergeWithLegacySemantics":        jsonv1.MergeWithLegacySemantics,
-	"jsonv1.OmitEmptyWithLegacySemantics":    jsonv1.OmitEmptyWithLegacySemantics,
-	"jsonv1.ParseBytesWithLooseRFC4648":      jsonv1.ParseBytesWithLooseRFC4648,
-	"jsonv1.ParseTimeWithLooseRFC3339":       jsonv1.ParseTimeWithLooseRFC3339,
-	"jsonv1.ReportErrorsWithLegacySemantics": jsonv1.ReportErrorsWithLegacySemantics,
-	"jsonv1.StringifyWithLegacySemantics":    jsonv1.StringifyWithLegacySemantics,
-	"jsonv1.UnmarshalArrayFromAnyLength":     jsonv1.UnmarshalArrayFromAnyLength,
-	"jsonv2.Deterministic":                   jsonv2.Deterministic,
-	"jsonv2.FormatNilMapAsNull":              jsonv2.FormatNilMapAsNull,
-	"jsonv2.FormatNilSliceAsNull":            jsonv2.FormatNilSliceAsNull,
-	"jsonv2.MatchCaseInsensitiveNames":       jsonv2.MatchCaseInsensitiveNames,
+		candidates = append(candidates, p)
+	}
+	significant := make([]bool, len(candidates)) // each element is only written by one goroutine
+	sema := make(chan struct{}, max(parallelism, 1))
+	affectsEquality := func(group []optionProbe) bool {
+		opts := []jsonv2.Options{optsV1}
+		for _, p := range group {
+			opts = append(opts, p.option(false))
+		}
+		sema <- struct{}{}
+		defer func() { <-sema }()
+		return !arshalEqual(opts...)
+	}
+	// search searches a group of candidates starting at offset,
+	// where affects reports whether the group is already known
+	// to affect equality (as inferred from its sibling).
+	// It reports whether the group affects equality.
+	var search func(offset int, group []optionProbe, affects bool) bool
+	search = func(offset int, group []optionProbe, affects bool) bool {
+		switch {
+		case len(group) == 0:
+			return false
+		case len(group) == 1:
+			significant[offset] = affectsEquality(group)
+			return significant[offset]
+		case !affects && !affectsEquality(group):
+			return false
+		}
+		n := len(group) / 2
+		if parallelism <= 1 {
+			// If the first half does not affect equality,
+			// then the second half must, without needing to probe it.
+			search(offset+n, group[n:], !search(offset, group[:n], false))
+			return true
+		}
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			search(offset, group[:n], false)
+		}()
+		search(offset+n, group[n:], false)
+		wg.Wait()
+		return true
+	}
+	search(0, candidates, false)
+
+	opts := []jsonv2.Options{optsFormat}
+	for i, p := range candidates {
+		if significant[i] {
+			opts = append(opts, p.option(true)) // need this option enabled to maintain equality
+		}
+	}
+	return jsonv2.JoinOptions(opts...), true
+}
+
+// formatOptions is the set of formatting options to try
+// when the v1 output is formatted differently from the v2 output.
+// Simpler formats are listed first.
+var formatOptions = []jsonv2.Options{
+	jsontext.SpaceAfterComma(true),
+	jsontext.SpaceAfterColon(true),
+	jsonv2.JoinOptions(jsontext.SpaceAfterColon(true), jsontext.SpaceAfterComma(true)),
+	jsontext.Multiline(true),
+	jsontext.WithIndent("  "),
+	jsontext.WithIndent("    "),
 }
@@ -7,18 +7,25 @@ package jsonsplit
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"encoding"
 	"encoding/json"
 	jsonv1std "encoding/json"
 	"expvar"
 	"fmt"
 	"io/fs"
+	"log"
+	"maps"
 	"math"
 	"math/big"
+	"os"
 	"reflect"
+	"runtime"
+	"runtime/pprof"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -26,6 +33,7 @@ import (
 	jsontext "github.com/go-json-experiment/json/jsontext"
 	jsonv1 "github.com/go-json-experiment/json/v1"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 // TestStandardOptionsV1 tests a special-case where specifying just
@@ -81,18 +89,40 @@ func newer[T any]() func() any {
 	return func() any { return new(T) }
 }
 
+// ignoreDifferenceAnalysis ignores the fields of a [Difference] that are
+// derived from its other fields when it is reported, which are
+// tested by the focused tests of each analysis
+// (e.g., TestReportDivergence, TestReportSuggestions, and TestOptionScopes).
+var ignoreDifferenceAnalysis = cmpopts.IgnoreFields(Difference{},
+	"Severity", "GoValuePath", "FieldDifferences", "JSONValuePointer", "JSONValueOffset",
+	"OptionCategories", "OptionScopes", "OptionLocations", "SuggestedCall", "TagSuggestions")
+
 func TestCodecMarshal(t *testing.T) {
 	var gotDiff Difference
 	var wantMetrics CodecMetrics
+	var mode CallMode
 	codec := Codec{
-		AutoDetectOptions: true,
+		AutoDetectOptions:    true,
+		TrackCallerDiffRates: true,
 		ReportDifference: func(d Difference) {
+			if len(d.ID) != 26 {
+				t.Errorf("Difference.ID = %q, want a ULID", d.ID)
+			}
+			wantMetrics.DifferenceSizeHistogram.insertSize(d.estimatedSize())
+			d.ID = "" // randomly generated
 			gotDiff = d
 			wantMetrics.NumMarshalDiffs.Add(1)
+			wantMetrics.CallModeCounters.Add(marshalCallModeKeys[mode].diffs, 1)
 			wantMetrics.MarshalCallerHistogram.Add(d.Caller, 1)
+			wantMetrics.SeverityHistogram.Add(d.Severity.String(), 1)
 			for name := range optionNames(d.Options) {
 				wantMetrics.MarshalOptionHistogram.Add(name, 1)
 			}
+			if len(slices.Collect(d.OptionNames())) > 0 {
+				wantMetrics.NumAutoDetectResolved.Add(1)
+			} else {
+				wantMetrics.NumAutoDetectUnexplained.Add(1)
+			}
 		},
 	}
 
@@ -177,6 +207,8 @@ func TestCodecMarshal(t *testing.T) {
 	}} {
 		t.Run("", func(t *testing.T) {
 			codec.SetMarshalCallMode(tt.mode)
+			mode = tt.mode
+			wantMetrics.CallModeCounters.Add(marshalCallModeKeys[mode].calls, 1)
 
 			// Marshal via the codec, jsonv1, and jsonv2.
 			c := callerPlus(codec.caller(), 1)
@@ -226,6 +258,9 @@ func TestCodecMarshal(t *testing.T) {
 				wantBuf, wantErr = wantBufV2, wantErrV2
 				wantMetrics.NumMarshalReturnV2.Add(1)
 			}
+			if wantMetrics.NumMarshalCallBoth.Value() > 0 {
+				wantMetrics.MarshalCallerDiffRates.observe(c, hasDiff)
+			}
 			wantMetrics.NumMarshalTotal.Add(1)
 			if gotErr != nil {
 				wantMetrics.NumMarshalErrors.Add(1)
@@ -243,14 +278,17 @@ func TestCodecMarshal(t *testing.T) {
 					GoType: reflect.TypeOf(tt.in), GoValue: tt.in,
 					JSONValueV1: wantBufV1, JSONValueV2: wantBufV2,
 					ErrorV1: wantErrV1, ErrorV2: wantErrV2,
-					Options: jsonv2.JoinOptions(tt.diffOpts),
+					CallerOptions: tt.inOpts,
+					OptionsV1:     jsonv2.JoinOptions(jsonv1.DefaultOptionsV1(), tt.inOpts),
+					OptionsV2:     jsonv2.JoinOptions(jsonv2.DefaultOptionsV2(), tt.inOpts),
+					Options:       jsonv2.JoinOptions(tt.diffOpts),
 				}
 			}
-			if d := cmp.Diff(gotDiff, wantDiff,
+			if d := cmp.Diff(gotDiff, wantDiff, ignoreDifferenceAnalysis,
 				cmp.Comparer(func(x, y reflect.Type) bool { return x == y }),
 				cmp.Comparer(func(x, y error) bool { return reflect.DeepEqual(x, y) }),
 				cmp.Transformer("OptionNames", func(opts jsonv2.Options) []string {
-					return slices.Collect(optionNames(opts))
+					return slices.Collect(optionNamesOf(opts, true))
 				}),
 				cmp.Exporter(func(t reflect.Type) bool { return true }),
 			); d != "" {
@@ -278,25 +316,43 @@ func TestCodecMarshal(t *testing.T) {
 func TestCodecUnmarshal(t *testing.T) {
 	var gotDiff Difference
 	var wantMetrics CodecMetrics
+	var mode CallMode
 	codec := Codec{
-		AutoDetectOptions: true,
+		AutoDetectOptions:    true,
+		TrackCallerDiffRates: true,
 		ReportDifference: func(d Difference) {
+			if len(d.ID) != 26 {
+				t.Errorf("Difference.ID = %q, want a ULID", d.ID)
+			}
+			wantMetrics.DifferenceSizeHistogram.insertSize(d.estimatedSize())
+			d.ID = "" // randomly generated
 			gotDiff = d
 			wantMetrics.NumUnmarshalDiffs.Add(1)
+			wantMetrics.CallModeCounters.Add(unmarshalCallModeKeys[mode].diffs, 1)
 			wantMetrics.UnmarshalCallerHistogram.Add(d.Caller, 1)
+			wantMetrics.SeverityHistogram.Add(d.Severity.String(), 1)
 			for name := range optionNames(d.Options) {
 				wantMetrics.UnmarshalOptionHistogram.Add(name, 1)
 			}
+			switch {
+			case d.ErrorV1 == ErrNotCloneable || d.ErrorV2 == ErrNotCloneable:
+				// Options are not detected if the Go value cannot be cloned.
+			case len(slices.Collect(d.OptionNames())) > 0:
+				wantMetrics.NumAutoDetectResolved.Add(1)
+			default:
+				wantMetrics.NumAutoDetectUnexplained.Add(1)
+			}
 		},
 	}
 
 	for _, tt := range []struct {
-		mode     CallMode
-		in       []byte
-		newOut   func() any
-		inOpts   jsonv2.Options
-		diffOpts jsonv2.Options
-		canClone bool
+		mode      CallMode
+		in        []byte
+		newOut    func() any
+		inOpts    jsonv2.Options
+		diffOpts  jsonv2.Options
+		causeOpts jsonv2.Options
+		canClone  bool
 	}{{
 		mode:   OnlyCallV1,
 		in:     []byte("\"\xde\xad\xbe\xef\""),
@@ -365,17 +421,19 @@ func TestCodecUnmarshal(t *testing.T) {
 		inOpts:   jsonv2.MatchCaseInsensitiveNames(true),
 		diffOpts: optsOf(jsontext.AllowDuplicateNames),
 	}, {
-		mode:     CallBothButReturnV2,
-		in:       []byte(`{"first_name":"john","FIRST_NAME":"jim"}`),
-		newOut:   newer[struct{ FirstName string }](),
-		inOpts:   jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true)),
-		diffOpts: optsOf(jsonv1.MatchCaseSensitiveDelimiter),
+		mode:      CallBothButReturnV2,
+		in:        []byte(`{"first_name":"john","FIRST_NAME":"jim"}`),
+		newOut:    newer[struct{ FirstName string }](),
+		inOpts:    jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true)),
+		diffOpts:  optsOf(jsonv1.MatchCaseSensitiveDelimiter),
+		causeOpts: jsonv2.MatchCaseInsensitiveNames(true),
 	}, {
-		mode:     CallBothButReturnV2,
-		in:       []byte(`{"first_name":"john","FIRST_NAME":"jim"}`),
-		newOut:   newer[struct{ FirstName string }](),
-		inOpts:   jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true), jsonv1.MatchCaseSensitiveDelimiter(false)),
-		diffOpts: optsOf(jsontext.AllowDuplicateNames),
+		mode:      CallBothButReturnV2,
+		in:        []byte(`{"first_name":"john","FIRST_NAME":"jim"}`),
+		newOut:    newer[struct{ FirstName string }](),
+		inOpts:    jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true), jsonv1.MatchCaseSensitiveDelimiter(false)),
+		diffOpts:  optsOf(jsontext.AllowDuplicateNames),
+		causeOpts: jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true), jsonv1.MatchCaseSensitiveDelimiter(false)),
 	}, {
 		mode:     CallV1ButUponErrorReturnV2,
 		in:       []byte(`"AAAAAAAAAAAAAAAAAAAAAA=="`),
@@ -473,6 +531,8 @@ func TestCodecUnmarshal(t *testing.T) {
 	}} {
 		t.Run("", func(t *testing.T) {
 			codec.SetUnmarshalCallMode(tt.mode)
+			mode = tt.mode
+			wantMetrics.CallModeCounters.Add(unmarshalCallModeKeys[mode].calls, 1)
 			codec.CloneGoValue = nil
 			if tt.canClone {
 				codec.CloneGoValue = func(in any) any {
@@ -557,6 +617,12 @@ func TestCodecUnmarshal(t *testing.T) {
 				wantVal, wantErr = wantValV2, wantErrV2
 				wantMetrics.NumUnmarshalReturnV2.Add(1)
 			}
+			switch {
+			case wantMetrics.NumUnmarshalCallBoth.Value() > 0:
+				wantMetrics.UnmarshalCallerDiffRates.observe(c, hasDiff)
+			case wantMetrics.NumUnmarshalCallBothSkipped.Value() > 0:
+				wantMetrics.UnmarshalCallerDiffRates.observe(c, true)
+			}
 			wantMetrics.NumUnmarshalTotal.Add(1)
 			if isMerge {
 				wantMetrics.NumUnmarshalMerge.Add(1)
@@ -577,13 +643,20 @@ func TestCodecUnmarshal(t *testing.T) {
 					GoType: reflect.TypeOf(gotVal), JSONValue: tt.in,
 					GoValueV1: wantValV1, GoValueV2: wantValV2,
 					ErrorV1: wantErrV1, ErrorV2: wantErrV2,
-					Options: jsonv2.JoinOptions(tt.diffOpts),
+					CallerOptions:  tt.inOpts,
+					OptionsV1:      jsonv2.JoinOptions(jsonv1.DefaultOptionsV1(), tt.inOpts),
+					OptionsV2:      jsonv2.JoinOptions(jsonv2.DefaultOptionsV2(), tt.inOpts),
+					Options:        jsonv2.JoinOptions(tt.diffOpts),
+					CausingOptions: tt.causeOpts,
 				}
 			}
 			if cantClone {
 				wantDiff = Difference{
 					Caller: c, Func: "Unmarshal",
 					GoType: reflect.TypeOf(gotVal), JSONValue: tt.in,
+					CallerOptions: tt.inOpts,
+					OptionsV1:     jsonv2.JoinOptions(jsonv1.DefaultOptionsV1(), tt.inOpts),
+					OptionsV2:     jsonv2.JoinOptions(jsonv2.DefaultOptionsV2(), tt.inOpts),
 				}
 				if gotDiff.GoValueV1 != nil {
 					wantDiff.GoValueV1 = gotDiff.GoValueV1
@@ -594,11 +667,11 @@ func TestCodecUnmarshal(t *testing.T) {
 					wantDiff.ErrorV1 = ErrNotCloneable
 				}
 			}
-			if d := cmp.Diff(gotDiff, wantDiff,
+			if d := cmp.Diff(gotDiff, wantDiff, ignoreDifferenceAnalysis,
 				cmp.Comparer(func(x, y reflect.Type) bool { return x == y }),
 				cmp.Comparer(func(x, y error) bool { return reflect.DeepEqual(x, y) }),
 				cmp.Transformer("OptionNames", func(opts jsonv2.Options) []string {
-					return slices.Collect(optionNames(opts))
+					return slices.Collect(optionNamesOf(opts, true))
 				}),
 				cmp.Exporter(func(t reflect.Type) bool { return true }),
 			); d != "" {
@@ -673,6 +746,54 @@ func TestCallModeRatio(t *testing.T) {
 	}
 }
 
+func TestMayCompare(t *testing.T) {
+	for _, tt := range []struct {
+		mode1 CallMode
+		mode2 CallMode
+		ratio float64
+		want  bool
+	}{
+		{OnlyCallV1, OnlyCallV1, 1.0, false},
+		{OnlyCallV1, OnlyCallV2, 0.5, false},
+		{OnlyCallV1, CallBothButReturnV1, 0, false},
+		{OnlyCallV1, CallBothButReturnV1, 0.1, true},
+		{CallV1ButUponErrorReturnV2, OnlyCallV2, 1.0, false},
+		{CallV1ButUponErrorReturnV2, OnlyCallV2, 0.9, true},
+	} {
+		var c Codec
+		c.SetMarshalCallRatio(tt.mode1, tt.mode2, tt.ratio)
+		c.SetUnmarshalCallRatio(tt.mode1, tt.mode2, tt.ratio)
+		if got := c.MayCompareMarshal(); got != tt.want {
+			t.Errorf("MayCompareMarshal(%v, %v, %v) = %v, want %v", tt.mode1, tt.mode2, tt.ratio, got, tt.want)
+		}
+		if got := c.MayCompareUnmarshal(); got != tt.want {
+			t.Errorf("MayCompareUnmarshal(%v, %v, %v) = %v, want %v", tt.mode1, tt.mode2, tt.ratio, got, tt.want)
+		}
+	}
+}
+
+func TestParseForcedCallMode(t *testing.T) {
+	if _, ok := parseForcedCallMode(""); ok {
+		t.Errorf("parseForcedCallMode(%q) reported ok, want !ok", "")
+	}
+	for mode, name := range callModeNames {
+		if got, ok := parseForcedCallMode(name); !ok || got != mode {
+			t.Errorf("parseForcedCallMode(%q) = (%v, %v), want (%v, true)", name, got, ok, mode)
+		}
+	}
+
+	// Invalid values are logged and ignored.
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	if _, ok := parseForcedCallMode("Bogus"); ok {
+		t.Errorf("parseForcedCallMode(%q) reported ok, want !ok", "Bogus")
+	}
+	if !strings.Contains(buf.String(), `invalid JSONSPLIT_FORCE_MODE value: "Bogus"`) {
+		t.Errorf("log output = %q, want a message about the invalid value", buf.String())
+	}
+}
+
 func TestSizeHistogram(t *testing.T) {
 	var h SizeHistogram
 	for _, n := range []int{0, 1, 1, 4, 4, 15, 15, 16, 1050, 1000000, 2000000, 2000000, 2000000, 1e9, 1e12} {
@@ -692,11 +813,42 @@ func TestSizeHistogram(t *testing.T) {
 	}
 }
 
+func TestSizeHistogramQuantile(t *testing.T) {
+	var h SizeHistogram
+	if got := h.Quantile(0.5); got != 0 {
+		t.Errorf("empty Quantile(0.5) = %v, want 0", got)
+	}
+	h.insertSize(0)
+	for range 49 {
+		h.insertSize(100) // within [64, 128)
+	}
+	for range 50 {
+		h.insertSize(5000) // within [4096, 8192)
+	}
+	for _, tt := range []struct {
+		p    float64
+		want float64
+	}{
+		{0, 0},
+		{0.01, 0},
+		{0.02, 64 + 64*1.0/49},
+		{0.25, 64 + 64*24.0/49},
+		{0.5, 128},
+		{0.75, 4096 + 4096*25.0/50},
+		{0.99, 4096 + 4096*49.0/50},
+		{1, 8192},
+	} {
+		if got := h.Quantile(tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
 // Test that our copy of v1 options is in sync with the jsonv1 package.
 func TestDefaultOptionsV1(t *testing.T) {
 	var opts []jsonv2.Options
-	for _, opt := range defaultOptionsV1 {
-		opts = append(opts, opt(true))
+	for _, info := range builtinOptions {
+		opts = append(opts, info.Option(true))
 	}
 	got := jsonv2.JoinOptions(opts...)
 	want := jsonv1.DefaultOptionsV1()
@@ -715,6 +867,78 @@ func TestDefaultOptionsV1(t *testing.T) {
 	}
 }
 
+func TestCodecMetricsAll(t *testing.T) {
+	var m CodecMetrics
+	m.NumMarshalTotal.Add(3)
+	var names []string
+	for name, v := range m.All() {
+		if name == "num_marshal_total" && v.String() != "3" {
+			t.Errorf("num_marshal_total = %v, want 3", v)
+		}
+		names = append(names, name)
+	}
+	if n := reflect.TypeFor[CodecMetrics]().NumField(); len(names) != n {
+		t.Errorf("len(All) = %d, want %d", len(names), n)
+	}
+	if want := []string{"num_marshal_total", "num_marshal_errors"}; !slices.Equal(names[:2], want) {
+		t.Errorf("All names = %v, want prefix %v", names, want)
+	}
+	for range m.All() {
+		break // must not panic when stopping early
+	}
+}
+
+func TestCallerDiffRates(t *testing.T) {
+	var r CallerDiffRates
+	for range 3 {
+		r.observe("pkg.Hot+1", false)
+	}
+	r.observe("pkg.Hot+1", true)
+	r.observe("pkg.Cold+2", true)
+
+	got := maps.Collect(r.All())
+	want := map[string]CallerDiffRate{
+		"pkg.Hot+1":  {Calls: 4, Diffs: 1, Rate: 0.25},
+		"pkg.Cold+2": {Calls: 1, Diffs: 1, Rate: 1},
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("All mismatch (-got +want):\n%s", d)
+	}
+	const wantJSON = `{"pkg.Cold+2":{"calls":1,"diffs":1,"rate":1},"pkg.Hot+1":{"calls":4,"diffs":1,"rate":0.25}}`
+	if got := r.String(); got != wantJSON {
+		t.Errorf("String = %s, want %s", got, wantJSON)
+	}
+
+	// Callers beyond the limit are aggregated.
+	for i := range MaxCallerDiffRates {
+		r.observe(fmt.Sprintf("pkg.Func%d", i), i%2 == 0)
+	}
+	if n := len(r.m); n != MaxCallerDiffRates+1 {
+		t.Errorf("len(CallerDiffRates) = %d, want %d", n, MaxCallerDiffRates+1)
+	}
+	if got, want := r.m[OtherCallers], (CallerDiffRate{Calls: 2, Diffs: 1, Rate: 0.5}); got == nil || *got != want {
+		t.Errorf("CallerDiffRates[%q] = %v, want %v", OtherCallers, got, want)
+	}
+	r.observe("pkg.Hot+1", true)
+	if got := r.m["pkg.Hot+1"].Calls; got != 5 {
+		t.Errorf("CallerDiffRates[%q].Calls = %d, want 5", "pkg.Hot+1", got)
+	}
+}
+
+func TestTrackCallerDiffRates(t *testing.T) {
+	codec := &Codec{}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int{})
+	if n := len(codec.MarshalCallerDiffRates.m); n != 0 {
+		t.Errorf("MarshalCallerDiffRates has %d callers, want 0", n)
+	}
+	codec.TrackCallerDiffRates = true
+	codec.Marshal([]int{})
+	if n := len(codec.MarshalCallerDiffRates.m); n != 1 {
+		t.Errorf("MarshalCallerDiffRates has %d callers, want 1", n)
+	}
+}
+
 func TestTypeString(t *testing.T) {
 	tests := []struct {
 		in   reflect.Type
@@ -736,6 +960,731 @@ func TestTypeString(t *testing.T) {
 	}
 }
 
+func TestAutoDetectFormatOptions(t *testing.T) {
+	v := struct {
+		Slice []int
+		Array [2]int
+	}{}
+	for _, tt := range []struct {
+		format func(jsontext.Value) jsontext.Value
+		want   []string
+	}{{
+		format: func(v jsontext.Value) jsontext.Value { return v },
+		want:   []string{"jsonv2.FormatNilSliceAsNull"},
+	}, {
+		format: func(v jsontext.Value) jsontext.Value { return bytes.ReplaceAll(v, []byte(","), []byte(", ")) },
+		want:   []string{"jsonv2.FormatNilSliceAsNull", "jsontext.SpaceAfterComma"},
+	}, {
+		format: func(v jsontext.Value) jsontext.Value {
+			v = bytes.ReplaceAll(v, []byte(","), []byte(", "))
+			return bytes.ReplaceAll(v, []byte(":"), []byte(": "))
+		},
+		want: []string{"jsonv2.FormatNilSliceAsNull", "jsontext.SpaceAfterColon", "jsontext.SpaceAfterComma"},
+	}, {
+		format: func(v jsontext.Value) jsontext.Value { v.Indent(jsontext.WithIndent("\t")); return v },
+		want:   []string{"jsonv2.FormatNilSliceAsNull", "jsontext.Multiline"},
+	}, {
+		format: func(v jsontext.Value) jsontext.Value { v.Indent(jsontext.WithIndent("  ")); return v },
+		want:   []string{"jsonv2.FormatNilSliceAsNull", `jsontext.WithIndent("  ")`},
+	}, {
+		format: func(v jsontext.Value) jsontext.Value { v.Indent(jsontext.WithIndent("   ")); return v },
+		want:   nil, // unsupported indentation
+	}} {
+		buf1, err := jsonv1.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf1 = tt.format(buf1)
+		opts, _ := autoDetectOptions(1, func(o ...jsonv2.Options) bool {
+			buf2, err := jsonv2.Marshal(v, o...)
+			return err == nil && bytes.Equal(buf1, buf2)
+		})
+		if d := cmp.Diff(slices.Collect(optionNames(opts)), tt.want); d != "" {
+			t.Errorf("options for %s mismatch (-got +want):\n%s", buf1, d)
+		}
+	}
+}
+
+func TestAutoDetectGroupTesting(t *testing.T) {
+	enabled := func(o []jsonv2.Options, option func(bool) jsonv2.Options) bool {
+		v, _ := jsonv2.GetOption(jsonv2.JoinOptions(o...), option)
+		return v
+	}
+	for _, parallelism := range []int{1, 4} {
+		// A single significant option is found in far fewer than O(n) calls.
+		var calls atomic.Int32
+		opts, _ := autoDetectOptions(parallelism, func(o ...jsonv2.Options) bool {
+			calls.Add(1)
+			return enabled(o, jsonv2.FormatNilSliceAsNull)
+		})
+		if got, want := slices.Collect(optionNames(opts)), []string{"jsonv2.FormatNilSliceAsNull"}; !slices.Equal(got, want) {
+			t.Errorf("parallelism %d: options = %v, want %v", parallelism, got, want)
+		}
+		if n := calls.Load(); int(n) >= len(builtinOptions) {
+			t.Errorf("parallelism %d: arshalEqual called %d times, want fewer than %d", parallelism, n, len(builtinOptions))
+		}
+
+		// Multiple significant options are all found.
+		opts, _ = autoDetectOptions(parallelism, func(o ...jsonv2.Options) bool {
+			return enabled(o, jsontext.EscapeForHTML) && enabled(o, jsonv2.FormatNilSliceAsNull)
+		})
+		if got, want := slices.Collect(optionNames(opts)), []string{"jsontext.EscapeForHTML", "jsonv2.FormatNilSliceAsNull"}; !slices.Equal(got, want) {
+			t.Errorf("parallelism %d: options = %v, want %v", parallelism, got, want)
+		}
+	}
+}
+
+func TestAutoDetectCombinations(t *testing.T) {
+	enabled := func(o []jsonv2.Options, option func(bool) jsonv2.Options) bool {
+		v, _ := jsonv2.GetOption(jsonv2.JoinOptions(o...), option)
+		return v
+	}
+
+	// Either of two options suffices, so neither is individually significant.
+	var codec Codec
+	opts, resolved, _, _ := codec.detectOptions("Marshal", nil, nil, func(o ...jsonv2.Options) bool {
+		return enabled(o, jsonv2.FormatNilSliceAsNull) || enabled(o, jsonv2.FormatNilMapAsNull)
+	})
+	if got := slices.Collect(optionNames(opts)); !resolved || len(got) != 1 {
+		t.Errorf("detectOptions = (%v, %v), want a single option that resolves the difference", got, resolved)
+	}
+	if n := codec.NumAutoDetectCombinations.Value(); n != 1 {
+		t.Errorf("NumAutoDetectCombinations = %d, want 1", n)
+	}
+
+	// A pair of options is needed in addition to those already detected.
+	pairEqual := func(o ...jsonv2.Options) bool {
+		return enabled(o, jsontext.EscapeForHTML) && enabled(o, jsonv2.FormatNilSliceAsNull) && enabled(o, jsonv2.FormatNilMapAsNull)
+	}
+	opts, resolved = searchOptionCombinations(pairEqual, func() bool { return true }, jsontext.EscapeForHTML(true))
+	if got, want := slices.Collect(optionNames(opts)), []string{"jsontext.EscapeForHTML", "jsonv2.FormatNilMapAsNull", "jsonv2.FormatNilSliceAsNull"}; !resolved || !slices.Equal(got, want) {
+		t.Errorf("searchOptionCombinations = (%v, %v), want (%v, true)", got, resolved, want)
+	}
+	if _, resolved := searchOptionCombinations(pairEqual, func() bool { return false }, jsontext.EscapeForHTML(true)); resolved {
+		t.Errorf("searchOptionCombinations unexpectedly searched pairs of options when not allowed")
+	}
+
+	// Differences that cannot be resolved retain the detected options.
+	opts, resolved = searchOptionCombinations(func(o ...jsonv2.Options) bool { return false }, func() bool { return true }, jsontext.EscapeForHTML(true))
+	if got, want := slices.Collect(optionNames(opts)), []string{"jsontext.EscapeForHTML"}; resolved || !slices.Equal(got, want) {
+		t.Errorf("searchOptionCombinations = (%v, %v), want (%v, false)", got, resolved, want)
+	}
+
+	// Unresolved differences are marked as incomplete.
+	// Only the sanity check with the v1 options (i.e., the second
+	// comparison after the initial one) reports equality.
+	var got []Difference
+	var calls int
+	codec = Codec{
+		AutoDetectOptions: true,
+		EqualJSONValues: func(x, y jsontext.Value) bool {
+			calls++
+			return calls == 2
+		},
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	if len(got) != 1 || !got[0].OptionsIncomplete {
+		t.Errorf("got differences %v, want one difference with incomplete options", got)
+	}
+	if n := codec.NumAutoDetectUnexplained.Value(); n != 1 {
+		t.Errorf("NumAutoDetectUnexplained = %d, want 1", n)
+	}
+}
+
+func TestAutoDetectUnexplainedCached(t *testing.T) {
+	// An unresolved search of option combinations is not repeated
+	// for the same operation and type.
+	// Only comparisons starting with the v1 options
+	// (as used by the sanity check and every probe) are equal,
+	// which no combination of options explains.
+	var codec Codec
+	typ := reflect.TypeFor[int]()
+	detect := func(op string) (calls int) {
+		var optsV1 jsonv2.Options
+		_, resolved, emulated, _ := codec.detectOptions(op, typ, nil, func(o ...jsonv2.Options) bool {
+			calls++
+			if optsV1 == nil {
+				optsV1 = o[0]
+			}
+			return o[0] == optsV1
+		})
+		if resolved || !emulated {
+			t.Fatalf("detectOptions = (resolved: %v, emulated: %v), want (false, true)", resolved, emulated)
+		}
+		return calls
+	}
+	first := detect("Marshal")
+	if calls := detect("Marshal"); calls >= first {
+		t.Errorf("second detection made %d comparisons, want fewer than %d", calls, first)
+	}
+	if calls := detect("Unmarshal"); calls != first {
+		t.Errorf("detection for another operation made %d comparisons, want %d", calls, first)
+	}
+
+	// Pairs of options are not searched once the budget is exhausted.
+	codec = Codec{MaxDetectionsPerType: 1}
+	if calls := detect("Marshal"); calls >= first {
+		t.Errorf("detection without budget for pairs made %d comparisons, want fewer than %d", calls, first)
+	}
+	if n := codec.NumAutoDetectBudgetExceeded.Value(); n != 1 {
+		t.Errorf("NumAutoDetectBudgetExceeded = %d, want 1", n)
+	}
+}
+
+func TestMinimizeOptions(t *testing.T) {
+	// Only [jsonv2.MatchCaseInsensitiveNames] is needed,
+	// which subsumes [jsonv1.MatchCaseSensitiveDelimiter].
+	arshalEqual := func(o ...jsonv2.Options) bool {
+		v, _ := jsonv2.GetOption(jsonv2.JoinOptions(o...), jsonv2.MatchCaseInsensitiveNames)
+		return v
+	}
+	options := jsonv2.JoinOptions(
+		jsonv1.MatchCaseSensitiveDelimiter(true),
+		jsonv2.MatchCaseInsensitiveNames(true),
+		jsontext.SpaceAfterColon(true),
+	)
+	got, ok := minimizeOptions(arshalEqual, options)
+	if !ok {
+		t.Fatalf("minimizeOptions reported no options dropped")
+	}
+	want := []string{"jsonv2.MatchCaseInsensitiveNames", "jsontext.SpaceAfterColon"}
+	if d := cmp.Diff(slices.Collect(optionNames(got)), want); d != "" {
+		t.Errorf("minimizeOptions mismatch (-got +want):\n%s", d)
+	}
+	if _, ok := minimizeOptions(arshalEqual, jsonv2.MatchCaseInsensitiveNames(true)); ok {
+		t.Errorf("minimizeOptions dropped the only option")
+	}
+
+	// With v1 defaults, disabling either option alone affects equality,
+	// but [jsonv1.MatchCaseSensitiveDelimiter] is redundant with v2 defaults
+	// where [jsonv2.FormatNilSliceAsNull] is disabled.
+	codec := &Codec{AutoDetectOptions: true}
+	options, resolved, _, _ := codec.detectOptions("Marshal", nil, nil, func(o ...jsonv2.Options) bool {
+		opts := jsonv2.JoinOptions(o...)
+		a, _ := jsonv2.GetOption(opts, jsonv2.MatchCaseInsensitiveNames)
+		b, _ := jsonv2.GetOption(opts, jsonv1.MatchCaseSensitiveDelimiter)
+		c, _ := jsonv2.GetOption(opts, jsonv2.FormatNilSliceAsNull)
+		return a && (b || !c)
+	})
+	if !resolved {
+		t.Fatalf("detectOptions did not resolve the difference")
+	}
+	want = []string{"jsonv2.MatchCaseInsensitiveNames"}
+	if d := cmp.Diff(slices.Collect(optionNames(options)), want); d != "" {
+		t.Errorf("detectOptions mismatch (-got +want):\n%s", d)
+	}
+	if n := codec.NumAutoDetectReduced.Value(); n != 1 {
+		t.Errorf("NumAutoDetectReduced = %d, want 1", n)
+	}
+}
+
+func TestCallerOptions(t *testing.T) {
+	var got []string
+	var gotV1, gotV2 []string
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) {
+		gotV1 = slices.Collect(optionNamesOf(d.OptionsV1, true))
+		gotV2 = slices.Collect(optionNamesOf(d.OptionsV2, true))
+		d.OptionsV1, d.OptionsV2 = nil, nil
+		got = append(got, d.String())
+	}}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+	codec.Marshal(map[string][]int{"k": nil}, jsonv2.Deterministic(false), jsonv1.StringifyWithLegacySemantics(true))
+
+	// The caller options override the defaults for each side.
+	if len(gotV1) != len(builtinOptions) || !slices.Contains(gotV1, "jsonv2.Deterministic(false)") || !slices.Contains(gotV1, "jsonv1.StringifyWithLegacySemantics") {
+		t.Errorf("OptionsV1 = %v, want v1 defaults with caller overrides", gotV1)
+	}
+	if len(gotV2) != len(builtinOptions) || !slices.Contains(gotV2, "jsonv2.Deterministic(false)") || !slices.Contains(gotV2, "jsonv1.StringifyWithLegacySemantics") {
+		t.Errorf("OptionsV2 = %v, want v2 defaults with caller overrides", gotV2)
+	}
+	var want []string
+	for _, tt := range []struct{ callerOptions, suggestedArgs string }{
+		{``, `jsonv2.FormatNilSliceAsNull(true)`},
+		{
+			`"CallerOptions":["jsonv1.StringifyWithLegacySemantics","jsonv2.Deterministic(false)"],`,
+			`jsonv2.FormatNilSliceAsNull(true)`, // only the options missing from the call
+		},
+	} {
+		want = append(want, `"Func":"Marshal","GoType":"map[string][]int","JSONValueV1":{"k":null},"JSONValueV2":{"k":[]},"JSONValuePointer":"/k","JSONValueOffset":5,`+
+			tt.callerOptions+`"Options":["jsonv2.FormatNilSliceAsNull"],"OptionCategories":{"jsonv2.FormatNilSliceAsNull":"Semantic"},"OptionScopes":{"jsonv2.FormatNilSliceAsNull":"Call"},"OptionLocations":{"jsonv2.FormatNilSliceAsNull":"/k"},"SuggestedCall":"jsonsplit.Marshal(v, `+tt.suggestedArgs+`)"}`)
+	}
+	for i := range got {
+		// Strip the leading ID and caller, which are not deterministic.
+		_, got[i], _ = strings.Cut(got[i], `"Caller":`)
+		_, got[i], _ = strings.Cut(got[i], `,`)
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("differences mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		DefaultOptions:   jsonv2.MatchCaseInsensitiveNames(true),
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// The default options resolve differences in case sensitivity.
+	var v struct{ Name string }
+	if err := codec.Unmarshal([]byte(`{"NAME":"x"}`), &v); err != nil || v.Name != "x" {
+		t.Errorf("Unmarshal = (%+v, %v), want ({Name:x}, nil)", v, err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("got differences %v, want none", got)
+	}
+
+	// The default options are reported as caller options.
+	codec.Marshal([]int(nil), jsonv2.Deterministic(true))
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	want := []string{"jsonv2.Deterministic", "jsonv2.MatchCaseInsensitiveNames"}
+	if diff := cmp.Diff(slices.Collect(got[0].CallerOptionNames()), want); diff != "" {
+		t.Errorf("CallerOptionNames mismatch (-got +want):\n%s", diff)
+	}
+}
+
+// stdDetector marshals as whether it is being marshaled by [jsonv1std].
+type stdDetector struct{}
+
+func (stdDetector) MarshalJSON() ([]byte, error) {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		fr, more := frames.Next()
+		if strings.HasPrefix(fr.Function, "encoding/json.") {
+			return []byte(`"std"`), nil
+		}
+		if !more {
+			return []byte(`"emulated"`), nil
+		}
+	}
+}
+
+func TestV1Implementation(t *testing.T) {
+	tests := []struct {
+		impl V1Implementation
+		opts []jsonv2.Options
+		want string
+	}{
+		{AutoSelectV1, nil, `"emulated"`},
+		{AutoSelectV1, []jsonv2.Options{jsonv1.DefaultOptionsV1()}, `"std"`},
+		{AlwaysUseStdV1, nil, `"std"`},
+		{AlwaysUseStdV1, []jsonv2.Options{jsonv2.Deterministic(true)}, `"emulated"`},
+		{AlwaysUseEmulatedV1, nil, `"emulated"`},
+		{AlwaysUseEmulatedV1, []jsonv2.Options{jsonv1.DefaultOptionsV1()}, `"emulated"`},
+	}
+	for _, tt := range tests {
+		var got []Difference
+		codec := Codec{V1Implementation: tt.impl, ReportDifference: func(d Difference) { got = append(got, d) }}
+		for _, mode := range []CallMode{OnlyCallV1, CallBothButReturnV1} {
+			codec.SetMarshalCallMode(mode)
+			b, err := codec.Marshal(stdDetector{}, tt.opts...)
+			if err != nil || string(b) != tt.want {
+				t.Errorf("%v: Marshal(%v) = (%s, %v), want (%s, nil)", tt.impl, mode, b, err, tt.want)
+			}
+		}
+		// Only the standard library differs from v2 in a comparison.
+		if std := tt.want == `"std"`; len(got) != 1 && std {
+			t.Errorf("%v: got %d differences, want 1", tt.impl, len(got))
+		} else if std && !got[0].StandardV1 {
+			t.Errorf("%v: Difference.StandardV1 = false, want true", tt.impl)
+		}
+	}
+}
+
+func TestEmulationRegression(t *testing.T) {
+	var gotDiffs, gotRegressions []Difference
+	codec := Codec{
+		AutoDetectOptions: true,
+		// Treat every output as different to simulate v2 failing to emulate v1.
+		EqualJSONValues:           func(x, y jsontext.Value) bool { return false },
+		ReportDifference:          func(d Difference) { gotDiffs = append(gotDiffs, d) },
+		ReportEmulationRegression: func(d Difference) { gotRegressions = append(gotRegressions, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+	if len(gotDiffs) != 1 || gotDiffs[0].Check != "" || gotDiffs[0].Options != nil {
+		t.Errorf("got differences %v, want one difference without options", gotDiffs)
+	}
+	if len(gotDiffs) == 1 && (!gotDiffs[0].DetectionFailed || gotDiffs[0].DetectionFailedReason == "") {
+		t.Errorf("difference DetectionFailed = %v with reason %q, want true with a reason", gotDiffs[0].DetectionFailed, gotDiffs[0].DetectionFailedReason)
+	}
+	if len(gotRegressions) != 1 || gotRegressions[0].Check != "EmulationRegression" {
+		t.Errorf("got emulation regressions %v, want one EmulationRegression difference", gotRegressions)
+	}
+	if n := codec.NumEmulationRegressions.Value(); n != 1 {
+		t.Errorf("NumEmulationRegressions = %d, want 1", n)
+	}
+
+	// Differences resolvable by options are not emulation regressions.
+	codec.EqualJSONValues = nil
+	codec.Marshal(map[string][]int{"k": nil})
+	if len(gotRegressions) != 1 {
+		t.Errorf("got %d emulation regressions, want 1", len(gotRegressions))
+	}
+	if len(gotDiffs) == 2 && gotDiffs[1].DetectionFailed {
+		t.Errorf("difference DetectionFailed = true, want false")
+	}
+	if n := codec.NumAutoDetectResolved.Value(); n != 1 {
+		t.Errorf("NumAutoDetectResolved = %d, want 1", n)
+	}
+	if n := codec.NumEmulationRegressions.Value(); n != 1 {
+		t.Errorf("NumEmulationRegressions = %d, want 1", n)
+	}
+}
+
+func TestAutoDetectParallelism(t *testing.T) {
+	in := []byte(`{"FIRSTNAME":"John","LASTNAME":"Doe","lastName":"Dupe"}`)
+	type User struct {
+		FirstName string   `json:"firstName"`
+		LastName  string   `json:"lastName"`
+		Age       int      `json:"age,omitempty"`
+		Aliases   []string `json:"tags"`
+	}
+	for _, parallelism := range []int{0, 1, 4, -1} {
+		var got []string
+		c := Codec{
+			AutoDetectOptions:     true,
+			AutoDetectParallelism: parallelism,
+			ReportDifference: func(d Difference) {
+				got = append(got, slices.Collect(d.OptionNames())...)
+			},
+		}
+		c.SetMarshalCallMode(CallBothButReturnV1)
+		c.SetUnmarshalCallMode(CallBothButReturnV1)
+		var u User
+		c.Unmarshal(in, &u)
+		c.Marshal(u)
+		want := []string{
+			"jsontext.AllowDuplicateNames", "jsonv2.MatchCaseInsensitiveNames",
+			"jsonv1.OmitEmptyWithLegacySemantics", "jsonv2.FormatNilSliceAsNull",
+		}
+		if d := cmp.Diff(got, want); d != "" {
+			t.Errorf("AutoDetectParallelism=%d: options mismatch (-got +want):\n%s", parallelism, d)
+		}
+	}
+}
+
+func TestValueMethods(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	wantCaller1 := callerPlus(codec.caller(), 1)
+	val, err := codec.MarshalToValue(map[string][]int{"k": nil})
+	if err != nil || string(val) != `{"k":null}` {
+		t.Errorf("MarshalToValue = (%s, %v), want (%s, nil)", val, err, `{"k":null}`)
+	}
+	var v struct{ K []int }
+	wantCaller2 := callerPlus(codec.caller(), 1)
+	err = codec.UnmarshalFromValue(jsontext.Value(`{"k":[1]}`), &v)
+	if err != nil || !reflect.DeepEqual(v.K, []int{1}) {
+		t.Errorf("UnmarshalFromValue = (%v, %v), want ([1], nil)", v.K, err)
+	}
+
+	if len(got) != 2 || got[0].Caller != wantCaller1 || got[1].Caller != wantCaller2 {
+		t.Errorf("got differences %v, want differences from %s and %s", got, wantCaller1, wantCaller2)
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	var got []Difference
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	in := struct {
+		A []int          `json:"a"`
+		B map[string]int `json:"b"`
+		C []int          `json:"c"`
+	}{[]int{}, map[string]int{}, []int{1, 2}}
+	b, err := codec.MarshalIndent(in, "", "\t")
+	if want := "{\n\t\"a\": [],\n\t\"b\": {},\n\t\"c\": [\n\t\t1,\n\t\t2\n\t]\n}"; err != nil || string(b) != want {
+		t.Errorf("MarshalIndent = (%q, %v), want (%q, nil)", b, err, want)
+	}
+	if len(got) > 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+
+	wantCaller := callerPlus(codec.caller(), 1)
+	b, err = codec.MarshalIndent([]string{"<html>"}, " ", "  ")
+	if want := "[\n   \"\\u003chtml\\u003e\"\n ]"; err != nil || string(b) != want {
+		t.Errorf("MarshalIndent = (%q, %v), want (%q, nil)", b, err, want)
+	}
+	if len(got) != 1 || got[0].Caller != wantCaller ||
+		!slices.Equal(slices.Collect(got[0].OptionNames()), []string{"jsontext.EscapeForHTML"}) {
+		t.Errorf("got differences %v, want an EscapeForHTML difference from %s", got, wantCaller)
+	}
+
+	// Indentation unsupported by v2 is still compared and tracked by metrics.
+	got = nil
+	calls := codec.NumMarshalTotal.Value()
+	b, err = codec.MarshalIndent([]int{1}, ">", "--")
+	if want := "[\n>--1\n>]"; err != nil || string(b) != want {
+		t.Errorf("MarshalIndent = (%q, %v), want (%q, nil)", b, err, want)
+	}
+	wantCaller = callerPlus(codec.caller(), 1)
+	b, err = codec.MarshalIndent([]string{"<html>"}, ">", "--")
+	if want := "[\n>--\"\\u003chtml\\u003e\"\n>]"; err != nil || string(b) != want {
+		t.Errorf("MarshalIndent = (%q, %v), want (%q, nil)", b, err, want)
+	}
+	if len(got) != 1 || got[0].Caller != wantCaller ||
+		!slices.Equal(slices.Collect(got[0].OptionNames()), []string{"jsontext.EscapeForHTML"}) {
+		t.Errorf("got differences %v, want an EscapeForHTML difference from %s", got, wantCaller)
+	}
+	if n := codec.NumMarshalTotal.Value() - calls; n != 2 {
+		t.Errorf("NumMarshalTotal increased by %d, want 2", n)
+	}
+}
+
+func TestStreamMethods(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	var buf bytes.Buffer
+	wantCaller1 := callerPlus(codec.caller(), 1)
+	err := codec.MarshalWrite(&buf, map[string][]int{"k": nil})
+	if err != nil || buf.String() != `{"k":null}` {
+		t.Errorf("MarshalWrite = (%s, %v), want (%s, nil)", buf.String(), err, `{"k":null}`)
+	}
+	var v struct{ K []int }
+	wantCaller2 := callerPlus(codec.caller(), 1)
+	err = codec.UnmarshalRead(strings.NewReader(`{"k":[1]}`), &v)
+	if err != nil || !reflect.DeepEqual(v.K, []int{1}) {
+		t.Errorf("UnmarshalRead = (%v, %v), want ([1], nil)", v.K, err)
+	}
+
+	if len(got) != 2 || got[0].Caller != wantCaller1 || got[1].Caller != wantCaller2 {
+		t.Errorf("got differences %v, want differences from %s and %s", got, wantCaller1, wantCaller2)
+	}
+
+	buf.Reset()
+	if err := codec.MarshalWrite(&buf, make(chan int)); err == nil || buf.Len() > 0 {
+		t.Errorf("MarshalWrite = (%s, %v), want (\"\", error)", buf.String(), err)
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	for _, mode := range []CallMode{CallBothButReturnV1, CallBothButReturnV2} {
+		t.Run(mode.String(), func(t *testing.T) {
+			var got []Difference
+			codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+			codec.SetMarshalCallMode(mode)
+
+			dst := make([]byte, 0, 64)
+			dst = append(dst, "prefix:"...)
+			want := map[CallMode]string{
+				CallBothButReturnV1: `prefix:{"k":null}`,
+				CallBothButReturnV2: `prefix:{"k":[]}`,
+			}[mode]
+			wantCaller := callerPlus(codec.caller(), 1)
+			b, err := codec.MarshalAppend(dst, map[string][]int{"k": nil})
+			if err != nil || string(b) != want {
+				t.Errorf("MarshalAppend = (%s, %v), want (%s, nil)", b, err, want)
+			}
+			if &b[0] != &dst[0] {
+				t.Errorf("MarshalAppend reallocated despite sufficient capacity")
+			}
+			if len(got) != 1 || got[0].Caller != wantCaller {
+				t.Errorf("got differences %v, want a difference from %s", got, wantCaller)
+			}
+
+			b, err = codec.MarshalAppend(dst, make(chan int))
+			if err == nil || string(b) != "prefix:" {
+				t.Errorf("MarshalAppend = (%s, %v), want (prefix:, error)", b, err)
+			}
+		})
+	}
+}
+
+func TestMarshalCustomV2(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		var calls int
+		marshalV2 := func(v any, o ...jsonv2.Options) ([]byte, error) {
+			calls++
+			return jsonv2.Marshal(v, o...)
+		}
+		codec := Codec{StreamMarshalComparison: stream}
+		codec.SetMarshalCallMode(CallBothButReturnV1)
+		codec.marshal(context.Background(), callSite{}, nil, jsonv1Marshal, marshalV2, map[string][]int{"k": nil})
+		if calls != 1 {
+			t.Errorf("StreamMarshalComparison=%v: marshalV2 called %d times, want 1", stream, calls)
+		}
+	}
+}
+
+func TestCoderMethods(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf)
+	enc.WriteToken(jsontext.BeginArray)
+	wantCaller1 := callerPlus(codec.caller(), 1)
+	if err := codec.MarshalEncode(enc, map[string][]int{"k": nil}); err != nil {
+		t.Errorf("MarshalEncode error: %v", err)
+	}
+	enc.WriteToken(jsontext.EndArray)
+	if want := "[{\"k\":null}]\n"; buf.String() != want {
+		t.Errorf("MarshalEncode output = %q, want %q", buf.String(), want)
+	}
+
+	dec := jsontext.NewDecoder(strings.NewReader(`[{"k":[1]}]`))
+	dec.ReadToken()
+	var v struct{ K []int }
+	wantCaller2 := callerPlus(codec.caller(), 1)
+	err := codec.UnmarshalDecode(dec, &v)
+	if err != nil || !reflect.DeepEqual(v.K, []int{1}) {
+		t.Errorf("UnmarshalDecode = (%v, %v), want ([1], nil)", v.K, err)
+	}
+	if tok, err := dec.ReadToken(); tok.Kind() != ']' || err != nil {
+		t.Errorf("ReadToken = (%v, %v), want (], nil)", tok, err)
+	}
+
+	if len(got) != 2 || got[0].Caller != wantCaller1 || got[1].Caller != wantCaller2 {
+		t.Errorf("got differences %v, want differences from %s and %s", got, wantCaller1, wantCaller2)
+	}
+}
+
+func TestShouldCompare(t *testing.T) {
+	var c Codec
+	c.SetMarshalCallMode(CallBothButReturnV2)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+	c.ShouldCompareMarshal = func(v any) bool { return v != "skip" }
+	c.ShouldCompareUnmarshal = func(v jsontext.Value) bool { return string(v) != `"skip"` }
+
+	c.Marshal("skip")
+	c.Marshal("compare")
+	c.Unmarshal([]byte(`"skip"`), new(string))
+	c.Unmarshal([]byte(`"compare"`), new(string))
+	for _, tt := range []struct {
+		name      string
+		got, want int64
+	}{
+		{"NumMarshalCallBoth", c.NumMarshalCallBoth.Value(), 1},
+		{"NumMarshalOnlyCallV2", c.NumMarshalOnlyCallV2.Value(), 1},
+		{"NumUnmarshalCallBoth", c.NumUnmarshalCallBoth.Value(), 1},
+		{"NumUnmarshalOnlyCallV1", c.NumUnmarshalOnlyCallV1.Value(), 1},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// The functions are not called for modes that do not call both.
+	var calls int
+	c.ShouldCompareMarshal = func(any) bool { calls++; return true }
+	c.ShouldCompareUnmarshal = func(jsontext.Value) bool { calls++; return true }
+	for _, mode := range []CallMode{OnlyCallV1, OnlyCallV2, CallV1ButUponErrorReturnV2} {
+		c.SetMarshalCallMode(mode)
+		c.SetUnmarshalCallMode(mode)
+		c.Marshal("compare")
+		c.Unmarshal([]byte(`"compare"`), new(string))
+	}
+	if calls != 0 {
+		t.Errorf("ShouldCompare functions called %d times, want 0", calls)
+	}
+}
+
+func TestCompareSizeProbability(t *testing.T) {
+	var c Codec
+	c.SetMarshalCallMode(CallBothButReturnV2)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+	c.CompareSizeProbability = InverseSizeProbability(8)
+
+	c.Marshal("small")                       // always compared
+	c.Unmarshal([]byte(`"small"`), new(any)) // always compared
+	for range 1000 {
+		c.Marshal(strings.Repeat("large", 20))                             // compared ~8% of the time
+		c.Unmarshal([]byte(`"`+strings.Repeat("large", 20)+`"`), new(any)) // compared ~8% of the time
+	}
+	for _, tt := range []struct {
+		name       string
+		both, only int64
+	}{
+		{"Marshal", c.NumMarshalCallBoth.Value(), c.NumMarshalOnlyCallV2.Value()},
+		{"Unmarshal", c.NumUnmarshalCallBoth.Value(), c.NumUnmarshalOnlyCallV1.Value()},
+	} {
+		if tt.both < 2 || tt.both > 200 || tt.both+tt.only != 1001 {
+			t.Errorf("%s: got %d compared and %d uncompared calls, want roughly 80 compared out of 1001", tt.name, tt.both, tt.only)
+		}
+	}
+
+	// The probability is not evaluated for modes that do not call both.
+	var calls int
+	c.CompareSizeProbability = func(int) float64 { calls++; return 1 }
+	for _, mode := range []CallMode{OnlyCallV1, OnlyCallV2, CallV1ButUponErrorReturnV2} {
+		c.SetMarshalCallMode(mode)
+		c.SetUnmarshalCallMode(mode)
+		c.Marshal("small")
+		c.Unmarshal([]byte(`"small"`), new(any))
+		c.Valid([]byte(`"small"`))
+		c.Compact(new(bytes.Buffer), []byte(`"small"`))
+	}
+	if calls != 0 {
+		t.Errorf("CompareSizeProbability called %d times, want 0", calls)
+	}
+}
+
+func TestIgnoreGoValuesOnErrors(t *testing.T) {
+	var diffs int
+	c := Codec{ReportDifference: func(Difference) { diffs++ }}
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// Both v1 and v2 fail, but v1 populates A before failing on B,
+	// while v2 fails on the invalid UTF-8 in A without populating it.
+	in := []byte("{\"A\":\"\xff\",\"B\":true}")
+	type T struct {
+		A string
+		B string
+	}
+	for _, ignore := range []bool{false, true} {
+		diffs = 0
+		c.IgnoreGoValuesOnErrors = ignore
+		if err := c.Unmarshal(in, new(T)); err == nil {
+			t.Fatal("Unmarshal error is nil, want non-nil")
+		}
+		if want := map[bool]int{false: 1, true: 0}[ignore]; diffs != want {
+			t.Errorf("IgnoreGoValuesOnErrors=%v: got %d differences, want %d", ignore, diffs, want)
+		}
+	}
+}
+
+func TestGoValueCaptureRatio(t *testing.T) {
+	var got []Difference
+	c := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	if r := c.GoValueCaptureRatio(); r != 1 {
+		t.Errorf("default GoValueCaptureRatio = %v, want 1", r)
+	}
+
+	for _, ratio := range []float64{0, 1} {
+		got = nil
+		c.SetGoValueCaptureRatio(ratio)
+		c.Marshal("<html>")
+		if len(got) != 1 {
+			t.Fatalf("got %d differences, want 1", len(got))
+		}
+		if captured := got[0].GoValue != nil; captured != (ratio == 1) {
+			t.Errorf("ratio %v: GoValue captured = %v, want %v", ratio, captured, ratio == 1)
+		}
+		if got[0].GoType == nil || got[0].JSONValueV1 == nil {
+			t.Errorf("ratio %v: difference metadata unexpectedly omitted", ratio)
+		}
+	}
+}
+
 func TestCallerHelper(t *testing.T) {
 	var gotCaller string
 	c := &Codec{ReportDifference: func(d Difference) {
@@ -768,6 +1717,137 @@ func helper1(c *Codec) {
 	c.Marshal([]int(nil))
 }
 
+func TestCallerSkipPrefixes(t *testing.T) {
+	var gotCaller string
+	c := &Codec{
+		ReportDifference: func(d Difference) {
+			gotCaller = d.Caller
+		},
+		CallerSkipPrefixes: []string{"github.com/go-json-experiment/jsonsplit.wrapper"},
+	}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+
+	wantCaller := callerPlus(c.caller(), 1)
+	wrapperMarshal(c)
+
+	if gotCaller != wantCaller {
+		t.Errorf("got %v, want %v", gotCaller, wantCaller)
+	}
+}
+
+func wrapperMarshal(c *Codec) {
+	c.Marshal([]int(nil))
+}
+
+func TestCallerLabel(t *testing.T) {
+	var gotCallers []string
+	c := &Codec{TrackCallerDiffRates: true, ReportDifference: func(d Difference) {
+		gotCallers = append(gotCallers, d.Caller)
+	}}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	ctx := WithCallerLabel(context.Background(), "ctx-label")
+	c.MarshalContext(ctx, []int(nil))
+	c.UnmarshalContext(ctx, []byte(`{"NAME":"x"}`), new(reverifyUser))
+	c.WithCallerLabel("codec-label").Marshal([]int(nil))
+	c.WithCallerLabel("codec-label").Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	wantCaller := callerPlus(c.caller(), 1)
+	c.MarshalContext(context.Background(), []int(nil))
+
+	want := []string{"ctx-label", "ctx-label", "codec-label", "codec-label", wantCaller}
+	if d := cmp.Diff(gotCallers, want); d != "" {
+		t.Errorf("callers mismatch (-got +want):\n%s", d)
+	}
+	want = []string{"codec-label", "ctx-label", wantCaller}
+	if d := cmp.Diff(slices.Sorted(maps.Keys(c.MarshalCallerDiffRates.m)), want); d != "" {
+		t.Errorf("MarshalCallerDiffRates callers mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestPackageContext(t *testing.T) {
+	var gotCallers []string
+	c := &Codec{ReportDifference: func(d Difference) {
+		gotCallers = append(gotCallers, d.Caller)
+	}}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	ctx := NewContext(context.Background(), c)
+	wantCaller := callerPlus(c.caller(), 1)
+	MarshalContext(ctx, []int(nil))
+	UnmarshalContext(WithCallerLabel(ctx, "ctx-label"), []byte(`{"NAME":"x"}`), new(reverifyUser))
+	MarshalContext(context.Background(), []int(nil)) // uses GlobalCodec
+
+	want := []string{wantCaller, "ctx-label"}
+	if d := cmp.Diff(gotCallers, want); d != "" {
+		t.Errorf("callers mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestContextLabels(t *testing.T) {
+	var got []map[string]string
+	c := &Codec{VerifyInterop: true, ReportDifference: func(d Difference) {
+		got = append(got, d.Labels)
+	}}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	type T struct{ A []int }
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "123", "tenant", "acme"))
+	c.MarshalContext(ctx, T{})
+	c.UnmarshalContext(ctx, []byte(`{"NAME":"x"}`), new(reverifyUser))
+	c.Marshal(T{})
+	want := map[string]string{"request": "123", "tenant": "acme"}
+	if d := cmp.Diff(got, []map[string]string{want, want, want, nil, nil}); d != "" {
+		t.Errorf("labels mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestPublishAs(t *testing.T) {
+	c1, c2 := new(Codec), new(Codec)
+	c1.NumMarshalTotal.Set(1)
+	c2.NumMarshalTotal.Set(2)
+	const name = "jsonsplit_test_publish_as"
+	get := func() string {
+		t.Helper()
+		v := expvar.Get(name)
+		if v == nil {
+			t.Fatalf("expvar.Get(%q) = nil", name)
+		}
+		if s := v.String(); s != "null" {
+			var m map[string]any
+			if err := jsonv2.Unmarshal([]byte(s), &m); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			return fmt.Sprint(m["num_marshal_total"])
+		}
+		return "null"
+	}
+
+	PublishAs(name, c1)
+	if got := get(); got != "1" {
+		t.Errorf("num_marshal_total = %s, want 1", got)
+	}
+	if !Unpublish(name) || Unpublish(name) {
+		t.Errorf("Unpublish = false, want true only once")
+	}
+	if got := get(); got != "null" {
+		t.Errorf("unpublished value = %s, want null", got)
+	}
+	PublishAs(name, c2)
+	if got := get(); got != "2" {
+		t.Errorf("num_marshal_total = %s, want 2", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("PublishAs of a published name did not panic")
+		}
+		Unpublish(name)
+	}()
+	PublishAs(name, c1)
+}
+
 func TestHelperAllocs(t *testing.T) {
 	var c Codec
 	if n := testing.AllocsPerRun(1000, func() {
@@ -913,3 +1993,37 @@ func BenchmarkAutoDetectOptions(b *testing.B) {
 		})
 	}
 }
+
+func TestEstimatedSize(t *testing.T) {
+	type user struct {
+		Name    string
+		Aliases []string
+		Groups  map[string]int
+	}
+	var got []Difference
+	codec := &Codec{
+		AutoDetectOptions: true,
+		DiffGoValues:      true,
+		VerifyRoundTrip:   true,
+		ReportDifference:  func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("route", "/users"))
+	codec.MarshalContext(ctx, []user{{Name: "x"}, {Name: "<y>"}})
+	codec.Unmarshal([]byte(`{"name":"x","aliases":[],"groups":{"a":1}}`), new(user))
+	codec.Unmarshal([]byte(`{"Name":1}`), new(user))
+	codec.Marshal(map[string]any{"k": math.NaN()})
+	if len(got) == 0 {
+		t.Fatal("no differences reported")
+	}
+	for _, d := range got {
+		b, err := d.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON error: %v", err)
+		}
+		if got, want := d.estimatedSize(), len(b); float64(got) < 0.9*float64(want) || float64(got) > 1.1*float64(want) {
+			t.Errorf("estimatedSize = %d, want about %d for %s", got, want, b)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// optionLocations reports the location at which each of the detected options
+// changes the result of v2, keyed by option name (see [Difference.OptionLocations]).
+// For each option, locate calls v2 with the options o followed by all
+// detected options (with) and again with only that option disabled (without),
+// and returns the location of the first divergence between the two results.
+// Options that cannot be disabled or that cannot be located are omitted.
+func optionLocations(options jsonv2.Options, locate func(with, without []jsonv2.Options) (string, bool), o ...jsonv2.Options) map[string]string {
+	var m map[string]string
+	with := append(slices.Clip(o), options)
+	for name := range optionNames(options) {
+		if strings.HasSuffix(name, ")") {
+			continue // e.g., "jsontext.WithIndent(\"\\t\")"
+		}
+		disabled, ok := parseOptionName(name + "(false)")
+		if !ok {
+			continue // e.g., "jsonv1.Decoder.UseNumber"
+		}
+		if loc, ok := locate(with, append(slices.Clip(with), disabled)); ok {
+			if m == nil {
+				m = make(map[string]string)
+			}
+			m[name] = loc
+		}
+	}
+	return m
+}
+
+// errorPointer returns the JSON pointer to where err occurred
+// if it is a [jsonv2.SemanticError] or [jsontext.SyntacticError].
+func errorPointer(err error) (jsontext.Pointer, bool) {
+	var semErr *jsonv2.SemanticError
+	var synErr *jsontext.SyntacticError
+	switch {
+	case errors.As(err, &semErr):
+		return semErr.JSONPointer, true
+	case errors.As(err, &synErr):
+		return synErr.JSONPointer, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOptionLocations(t *testing.T) {
+	type config struct {
+		Timeout time.Duration
+		Tags    []string
+	}
+	type service struct {
+		Name   string
+		Config config
+		Labels map[string]string
+	}
+	var got Difference
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { got = d }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	codec.Marshal(service{Config: config{Tags: []string{}}})
+	want := map[string]string{
+		"jsonv1.FormatDurationAsNano": "/Config/Timeout",
+		"jsonv2.FormatNilMapAsNull":   "/Labels",
+	}
+	if d := cmp.Diff(got.OptionLocations, want); d != "" {
+		t.Errorf("Marshal: OptionLocations mismatch (-got +want):\n%s", d)
+	}
+
+	codec.Unmarshal([]byte(`{"name":"x","Config":{"Timeout":1}}`), new(service))
+	want = map[string]string{
+		"jsonv1.FormatDurationAsNano":      "/Config/Timeout",
+		"jsonv2.MatchCaseInsensitiveNames": "service.Name",
+	}
+	if d := cmp.Diff(got.OptionLocations, want); d != "" {
+		t.Errorf("Unmarshal: OptionLocations mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+	"slices"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// LogFields returns an iterator over the non-zero fields of d
+// keyed by field name (e.g., "Caller" and "GoType")
+// in a form accepted by every common structured logging library.
+// It is the single list of fields that is used by [Difference.LogValue]
+// and by the adapters in the reporters package.
+//
+// Option names are yielded as a []string
+// (see [Difference.OptionNames]).
+// Valid JSON payloads (i.e., JSONValue, JSONValueV1, and JSONValueV2)
+// are yielded as a [jsontext.Value], which is marshaled as raw JSON,
+// while invalid JSON payloads are yielded as a string.
+// Go types and errors are yielded as strings.
+// Go values are omitted, but the location of the first divergence is included
+// (i.e., GoValuePath and JSONValuePointer), along with GoValueDiff if present.
+// The Fingerprint field is the hexadecimal [Difference.Fingerprint].
+func (d Difference) LogFields() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		ok := true
+		add := func(key string, val any) {
+			ok = ok && yield(key, val)
+		}
+		addString := func(key, val string) {
+			if val != "" {
+				add(key, val)
+			}
+		}
+		addJSON := func(key string, val jsontext.Value) {
+			switch {
+			case len(val) == 0:
+			case val.IsValid(jsontext.AllowDuplicateNames(true)):
+				add(key, val)
+			default:
+				add(key, string(val))
+			}
+		}
+		addNames := func(key string, names []string) {
+			if len(names) > 0 {
+				add(key, names)
+			}
+		}
+		addString("ID", d.ID)
+		addString("Caller", d.Caller)
+		addString("Func", d.Func)
+		addString("Severity", d.Severity.String())
+		addString("Check", d.Check)
+		if len(d.Labels) > 0 {
+			add("Labels", d.Labels)
+		}
+		if d.GoType != nil {
+			addString("GoType", d.GoType.String())
+		}
+		addJSON("JSONValue", d.JSONValue)
+		addJSON("JSONValueV1", d.JSONValueV1)
+		addJSON("JSONValueV2", d.JSONValueV2)
+		addString("GoValuePath", d.GoValuePath)
+		addString("GoValueDiff", d.GoValueDiff)
+		addString("JSONValuePointer", string(d.JSONValuePointer))
+		if d.ErrorV1 != nil {
+			addString("ErrorV1", d.ErrorV1.Error())
+		}
+		if d.ErrorV2 != nil {
+			addString("ErrorV2", d.ErrorV2.Error())
+		}
+		addNames("CallerOptions", slices.Collect(d.CallerOptionNames()))
+		if d.StandardV1 {
+			add("StandardV1", true)
+		}
+		addNames("Options", slices.Collect(d.OptionNames()))
+		addNames("CausingOptions", slices.Collect(optionNamesOf(d.CausingOptions, true)))
+		if d.DetectionFailed {
+			add("DetectionFailed", true)
+			addString("DetectionFailedReason", d.DetectionFailedReason)
+		}
+		addString("SuggestedCall", d.SuggestedCall)
+		if len(d.TagSuggestions) > 0 {
+			add("TagSuggestions", d.TagSuggestions)
+		}
+		add("Fingerprint", fmt.Sprintf("%016x", d.Fingerprint()))
+	}
+}
+
+// LogValue implements [slog.LogValuer] such that d is logged
+// as a group of structured attributes that can be filtered and indexed,
+// rather than as a single JSON string.
+//
+// The attributes are the fields of [Difference.LogFields],
+// except that large payloads (i.e., the JSON values) are omitted.
+func (d Difference) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for key, val := range d.LogFields() {
+		switch key {
+		case "JSONValue", "JSONValueV1", "JSONValueV2":
+			continue // omit large payloads
+		}
+		attrs = append(attrs, slog.Any(key, val))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// SlogReporter returns a function for use with [Codec.ReportDifference]
+// (or [Codec.ReportEmulationRegression]) that logs every difference
+// to logger at the specified level as a structured "diff" attribute
+// (see [Difference.LogValue]).
+// If logger is nil, it uses [slog.Default] at the time of each report.
+func SlogReporter(logger *slog.Logger, level slog.Level) func(Difference) {
+	return func(d Difference) {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		l.LogAttrs(context.Background(), level, "detected jsonv1-to-jsonv2 difference", slog.Any("diff", d))
+	}
+}
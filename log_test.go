@@ -0,0 +1,71 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestLogValue(t *testing.T) {
+	d := Difference{
+		ID:               "01M51Z87SJDT1BM3TQ8P6JAXMK",
+		Caller:           "example.com/pkg.Func+12",
+		Func:             "Marshal",
+		GoType:           reflect.TypeFor[map[string][]int](),
+		GoValue:          map[string][]int{"k": nil},
+		JSONValueV1:      []byte(`{"k":null}`),
+		JSONValueV2:      []byte(`{"k":[]}`),
+		JSONValuePointer: "/k",
+		GoValueDiff:      "-a\n+b\n",
+		StandardV1:       true,
+		Options:          jsonv2.FormatNilSliceAsNull(true),
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})).Warn("difference", "diff", d)
+	want := `{"level":"WARN","msg":"difference","diff":{` +
+		`"ID":"01M51Z87SJDT1BM3TQ8P6JAXMK","Caller":"example.com/pkg.Func+12","Func":"Marshal","Severity":"Semantic",` +
+		`"GoType":"map[string][]int","GoValueDiff":"-a\n+b\n","JSONValuePointer":"/k","StandardV1":true,` +
+		`"Options":["jsonv2.FormatNilSliceAsNull"],` +
+		fmt.Sprintf(`"Fingerprint":"%016x"}}`, d.Fingerprint()) + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("slog output:\n\tgot  %s\twant %s", got, want)
+	}
+}
+
+func TestSlogReporter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	codec := &Codec{ReportDifference: SlogReporter(logger, slog.LevelWarn)}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	got := buf.String()
+	for _, want := range []string{"level=WARN", `msg="detected jsonv1-to-jsonv2 difference"`, "diff.Func=Marshal", "diff.GoType=[]int"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output %q does not contain %q", got, want)
+		}
+	}
+
+	// Records below the level of the handler are discarded.
+	buf.Reset()
+	codec.ReportDifference = SlogReporter(logger, slog.LevelDebug)
+	codec.Marshal([]int(nil))
+	if buf.Len() > 0 {
+		t.Errorf("log output %q, want none", buf.String())
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import "expvar"
+
+// MetricsBackend receives every update to the metrics of a [Codec]
+// so that they can be routed directly into an existing telemetry library
+// rather than being scraped from [CodecMetrics.ExpVar] and re-exported.
+// The metrics in [CodecMetrics] are always updated regardless of the backend.
+//
+// Metrics are identified by the snake_case form of the corresponding
+// field name in [CodecMetrics] (e.g., "num_marshal_total").
+// Implementations must be safe for concurrent use.
+type MetricsBackend interface {
+	// AddCounter adds delta to the named counter.
+	// For metrics keyed by name (e.g., [CodecMetrics.MarshalCallerHistogram]),
+	// the key is the name within the map, otherwise it is empty.
+	// For [CallerDiffRates], the key is the caller suffixed with
+	// either "/calls" or "/diffs".
+	AddCounter(name, key string, delta int64)
+	// ObserveHistogram records a value (e.g., a size in bytes)
+	// in the named histogram (e.g., [CodecMetrics.MarshalSizeHistogram]).
+	ObserveHistogram(name string, value int64)
+}
+
+// add adds delta to the counter v in c and forwards it to [Codec.MetricsBackend].
+func (c *Codec) add(v *expvar.Int, delta int64) {
+	v.Add(delta)
+	if c.MetricsBackend != nil {
+		c.MetricsBackend.AddCounter(c.metricName(v), "", delta)
+	}
+	if p, pv := c.parentMetric(v); p != nil {
+		p.add(pv.(*expvar.Int), delta)
+	}
+}
+
+// addKey adds delta to the key in the map m in c
+// and forwards it to [Codec.MetricsBackend].
+func (c *Codec) addKey(m *expvar.Map, key string, delta int64) {
+	m.Add(key, delta)
+	if c.MetricsBackend != nil {
+		c.MetricsBackend.AddCounter(c.metricName(m), key, delta)
+	}
+	if p, pm := c.parentMetric(m); p != nil {
+		p.addKey(pm.(*expvar.Map), key, delta)
+	}
+}
+
+// insertSize inserts n into the histogram h in c
+// and forwards it to [Codec.MetricsBackend].
+func (c *Codec) insertSize(h *SizeHistogram, n int) {
+	h.insertSize(n)
+	if c.MetricsBackend != nil {
+		c.MetricsBackend.ObserveHistogram(c.metricName(h), int64(n))
+	}
+	if p, ph := c.parentMetric(h); p != nil {
+		p.insertSize(ph.(*SizeHistogram), n)
+	}
+}
+
+// observeCaller observes a call by the caller in the rates r in c
+// and forwards it to [Codec.MetricsBackend].
+func (c *Codec) observeCaller(r *CallerDiffRates, caller string, hasDiff bool) {
+	r.observe(caller, hasDiff)
+	if c.MetricsBackend != nil {
+		name := c.metricName(r)
+		c.MetricsBackend.AddCounter(name, caller+"/calls", 1)
+		if hasDiff {
+			c.MetricsBackend.AddCounter(name, caller+"/diffs", 1)
+		}
+	}
+	if p, pr := c.parentMetric(r); p != nil {
+		p.observeCaller(pr.(*CallerDiffRates), caller, hasDiff)
+	}
+}
+
+// metricName returns the name of the metric v within c.
+func (c *Codec) metricName(v expvar.Var) string {
+	name, ok := c.lookupMetricName(v)
+	if !ok {
+		panic("unknown metric")
+	}
+	return name
+}
+
+// lookupMetricName is like metricName, but reports false for unknown metrics.
+func (c *Codec) lookupMetricName(v expvar.Var) (string, bool) {
+	if name, ok := c.metricNames.Load(v); ok {
+		return name.(string), true
+	}
+	for name, v2 := range c.All() {
+		if v2 == v {
+			c.metricNames.Store(v, name)
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testBackend struct {
+	mu         sync.Mutex
+	counters   map[string]int64
+	histograms map[string][]int64
+}
+
+func (b *testBackend) AddCounter(name, key string, delta int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if key != "" {
+		name += "[" + key + "]"
+	}
+	b.counters[name] += delta
+}
+
+func (b *testBackend) ObserveHistogram(name string, value int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.histograms[name] = append(b.histograms[name], value)
+}
+
+func TestMetricsBackend(t *testing.T) {
+	b := &testBackend{counters: make(map[string]int64), histograms: make(map[string][]int64)}
+	c := &Codec{MetricsBackend: b}
+	c.SetMarshalCallMode(CallBothButReturnV1)
+	c.SetUnmarshalCallMode(CallBothButReturnV1)
+	c.Marshal([]int(nil))
+	c.Marshal(0)
+	c.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+
+	// Every counter must have been forwarded to the backend.
+	for name, v := range c.All() {
+		if v, ok := v.(*expvar.Int); ok && v.Value() != b.counters[name] {
+			t.Errorf("counter %s = %d, want %d", name, b.counters[name], v.Value())
+		}
+	}
+	for name, want := range map[string]int64{
+		"call_mode_counters[Marshal/CallBothButReturnV1/calls]":   2,
+		"call_mode_counters[Marshal/CallBothButReturnV1/diffs]":   1,
+		"call_mode_counters[Unmarshal/CallBothButReturnV1/diffs]": 1,
+	} {
+		if b.counters[name] != want {
+			t.Errorf("counter %s = %d, want %d", name, b.counters[name], want)
+		}
+	}
+	want := map[string][]int64{
+		"marshal_size_histogram":   {4, 1},
+		"unmarshal_size_histogram": {12},
+	}
+	if d := cmp.Diff(b.histograms, want); d != "" {
+		t.Errorf("histograms mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+// jsonValueDivergence walks the token streams of v1 and v2 in lock step
+// and returns the JSON pointer to the first divergent token and
+// the byte offset into v1 just before that token.
+// Invalid JSON diverges at the first syntactic error.
+// It reports false if no divergence was found.
+func jsonValueDivergence(v1, v2 []byte) (jsontext.Pointer, int64, bool) {
+	dec1 := jsontext.NewDecoder(bytes.NewReader(v1), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	dec2 := jsontext.NewDecoder(bytes.NewReader(v2), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	for {
+		offset := dec1.InputOffset()
+		tok1, err1 := dec1.ReadToken()
+		kind1, str1 := tok1.Kind(), tok1.String()
+		tok2, err2 := dec2.ReadToken()
+		switch {
+		case err1 != nil && err2 != nil:
+			return "", 0, false // both ended (or failed) at the same token
+		case err1 != nil || err2 != nil || kind1 != tok2.Kind() || str1 != tok2.String():
+			// Skip any whitespace and delimiters preceding the token.
+			for offset < int64(len(v1)) && strings.IndexByte(" \t\r\n:,", v1[offset]) >= 0 {
+				offset++
+			}
+			if err1 != nil {
+				return dec2.StackPointer(), offset, true
+			}
+			return dec1.StackPointer(), offset, true
+		}
+	}
+}
+
+// goValuePath returns the path to the first divergent value between v1 and v2
+// according to [reflect.DeepEqual] semantics (e.g., "User.Aliases[2]").
+// The path is rooted at the name of the (possibly pointed-at) Go type,
+// where pointers and interfaces are implicitly dereferenced.
+// It returns the empty string if no divergence was found.
+func goValuePath(v1, v2 any) string {
+	rv1, rv2 := reflect.ValueOf(v1), reflect.ValueOf(v2)
+	var root []byte
+	if rv1.IsValid() {
+		t := rv1.Type()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		root = append(root, t.Name()...)
+	}
+	w := pathWalker{visited: make(map[[2]uintptr]bool)}
+	if p, ok := w.walk(root, rv1, rv2); ok {
+		if len(p) == 0 {
+			return "."
+		}
+		return string(p)
+	}
+	return ""
+}
+
+// fieldDifferences returns every field of the struct values
+// pointed at by v1 and v2 that diverges according to [reflect.DeepEqual]
+// semantics, in field order. It returns nil if v1 and v2 are not
+// (pointers to) struct values of the same type.
+func fieldDifferences(v1, v2 any) []FieldDifference {
+	rv1, rv2 := reflect.ValueOf(v1), reflect.ValueOf(v2)
+	if !rv1.IsValid() || !rv2.IsValid() || rv1.Type() != rv2.Type() {
+		return nil
+	}
+	for rv1.Kind() == reflect.Pointer && !rv1.IsNil() && !rv2.IsNil() {
+		rv1, rv2 = rv1.Elem(), rv2.Elem()
+	}
+	if rv1.Kind() != reflect.Struct {
+		return nil
+	}
+	var fds []FieldDifference
+	for i := range rv1.NumField() {
+		f1, f2 := rv1.Field(i), rv2.Field(i)
+		w := pathWalker{visited: make(map[[2]uintptr]bool)}
+		if _, ok := w.walk(nil, f1, f2); ok {
+			fds = append(fds, FieldDifference{
+				Field:   rv1.Type().Field(i).Name,
+				ValueV1: formatFieldValue(f1),
+				ValueV2: formatFieldValue(f2),
+			})
+		}
+	}
+	return fds
+}
+
+// formatFieldValue formats v as Go syntax
+// after dereferencing any non-nil pointers.
+func formatFieldValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// goValueDiff returns a human-readable diff from v1 to v2,
+// including any unexported struct fields.
+// It returns the empty string if no divergence was found.
+func goValueDiff(v1, v2 any) string {
+	return gocmp.Diff(v1, v2, gocmp.Exporter(func(reflect.Type) bool { return true }))
+}
+
+type pathWalker struct {
+	visited map[[2]uintptr]bool // pointer pairs already being compared
+}
+
+// walk reports whether v1 and v2 diverge and
+// the path to the first divergence appended to p.
+func (w *pathWalker) walk(p []byte, v1, v2 reflect.Value) ([]byte, bool) {
+	if !v1.IsValid() || !v2.IsValid() {
+		return p, v1.IsValid() != v2.IsValid()
+	}
+	if v1.Type() != v2.Type() {
+		return p, true
+	}
+
+	switch v1.Kind() {
+	case reflect.Array:
+		for i := range v1.Len() {
+			if p, ok := w.walk(appendIndex(p, i), v1.Index(i), v2.Index(i)); ok {
+				return p, true
+			}
+		}
+		return p, false
+	case reflect.Slice:
+		if v1.IsNil() != v2.IsNil() {
+			return p, true
+		}
+		if v1.Len() == v2.Len() && v1.UnsafePointer() == v2.UnsafePointer() {
+			return p, false
+		}
+		n := min(v1.Len(), v2.Len())
+		for i := range n {
+			if p, ok := w.walk(appendIndex(p, i), v1.Index(i), v2.Index(i)); ok {
+				return p, true
+			}
+		}
+		if v1.Len() != v2.Len() {
+			return appendIndex(p, n), true // first element only present in one
+		}
+		return p, false
+	case reflect.Interface:
+		if v1.IsNil() || v2.IsNil() {
+			return p, v1.IsNil() != v2.IsNil()
+		}
+		return w.walk(p, v1.Elem(), v2.Elem())
+	case reflect.Pointer:
+		if v1.IsNil() || v2.IsNil() {
+			return p, v1.IsNil() != v2.IsNil()
+		}
+		k := [2]uintptr{v1.Pointer(), v2.Pointer()}
+		if k[0] == k[1] || w.visited[k] {
+			return p, false
+		}
+		w.visited[k] = true
+		return w.walk(p, v1.Elem(), v2.Elem())
+	case reflect.Struct:
+		for i := range v1.NumField() {
+			q := append(append(p, '.'), v1.Type().Field(i).Name...)
+			if q, ok := w.walk(q, v1.Field(i), v2.Field(i)); ok {
+				return q, true
+			}
+		}
+		return p, false
+	case reflect.Map:
+		if v1.IsNil() != v2.IsNil() {
+			return p, true
+		}
+		if v1.UnsafePointer() == v2.UnsafePointer() {
+			return p, false
+		}
+		// Sort the union of keys so that the reported path is deterministic.
+		keys := v1.MapKeys()
+		for _, k := range v2.MapKeys() {
+			if !v1.MapIndex(k).IsValid() {
+				keys = append(keys, k)
+			}
+		}
+		slices.SortFunc(keys, func(x, y reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(x), fmt.Sprint(y))
+		})
+		for _, k := range keys {
+			if p, ok := w.walk(appendKey(p, k), v1.MapIndex(k), v2.MapIndex(k)); ok {
+				return p, true
+			}
+		}
+		return p, false
+	case reflect.Func:
+		return p, !(v1.IsNil() && v2.IsNil())
+	default:
+		return p, !v1.Equal(v2)
+	}
+}
+
+func appendIndex(p []byte, i int) []byte {
+	p = append(p, '[')
+	p = strconv.AppendInt(p, int64(i), 10)
+	return append(p, ']')
+}
+
+func appendKey(p []byte, k reflect.Value) []byte {
+	p = append(p, '[')
+	if k.Kind() == reflect.String {
+		p = strconv.AppendQuote(p, k.String())
+	} else {
+		p = fmt.Append(p, k)
+	}
+	return append(p, ']')
+}
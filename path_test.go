@@ -0,0 +1,152 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoValuePath(t *testing.T) {
+	type User struct {
+		Name    string
+		Aliases []string
+		Attrs   map[string]any
+		Next    *User
+		private int
+	}
+	tests := []struct {
+		v1, v2 any
+		want   string
+	}{
+		{v1: nil, v2: nil, want: ""},
+		{v1: 1, v2: 2, want: "int"},
+		{v1: 1, v2: "1", want: "int"},
+		{v1: ptrTo([]int{1}), v2: ptrTo([]int(nil)), want: "."},
+		{v1: &User{Name: "a"}, v2: &User{Name: "a"}, want: ""},
+		{v1: &User{Name: "a"}, v2: &User{Name: "b"}, want: "User.Name"},
+		{v1: &User{Aliases: []string{"a", "b", "c"}}, v2: &User{Aliases: []string{"a", "b", "d"}}, want: "User.Aliases[2]"},
+		{v1: &User{Aliases: []string{"a"}}, v2: &User{Aliases: []string{"a", "b"}}, want: "User.Aliases[1]"},
+		{v1: &User{Aliases: []string{}}, v2: &User{}, want: "User.Aliases"},
+		{v1: &User{Attrs: map[string]any{"k": 1.0}}, v2: &User{Attrs: map[string]any{"k": 2.0}}, want: `User.Attrs["k"]`},
+		{v1: &User{Attrs: map[string]any{"a": 1.0}}, v2: &User{Attrs: map[string]any{"b": 1.0}}, want: `User.Attrs["a"]`},
+		{v1: &User{Attrs: map[string]any{"k": []any{1.0}}}, v2: &User{Attrs: map[string]any{"k": []any{"1"}}}, want: `User.Attrs["k"][0]`},
+		{v1: &User{Next: &User{Name: "a"}}, v2: &User{Next: &User{Name: "b"}}, want: "User.Next.Name"},
+		{v1: &User{private: 1}, v2: &User{private: 2}, want: "User.private"},
+		{v1: &[2]float64{0, math.NaN()}, v2: &[2]float64{0, math.NaN()}, want: "[1]"},
+		{v1: &map[int]bool{1: true}, v2: &map[int]bool{1: false}, want: "[1]"},
+	}
+	for _, tt := range tests {
+		if got := goValuePath(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("goValuePath(%v, %v) = %q, want %q", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
+
+func TestFieldDifferences(t *testing.T) {
+	type User struct {
+		Name    string
+		Aliases []string
+		Age     *int
+		private int
+	}
+	tests := []struct {
+		v1, v2 any
+		want   []FieldDifference
+	}{
+		{v1: nil, v2: nil, want: nil},
+		{v1: 1, v2: 2, want: nil},
+		{v1: &User{Name: "a"}, v2: &User{Name: "a"}, want: nil},
+		{v1: &User{Name: "a"}, v2: User{Name: "b"}, want: nil},
+		{v1: &User{Name: "a"}, v2: &User{Name: "b"}, want: []FieldDifference{{"Name", `"a"`, `"b"`}}},
+		{
+			v1:   &User{Aliases: []string{}, Age: ptrTo(1), private: 1},
+			v2:   &User{Age: ptrTo(2), private: 2},
+			want: []FieldDifference{{"Aliases", "[]string{}", "[]string(nil)"}, {"Age", "1", "2"}, {"private", "1", "2"}},
+		},
+		{v1: &User{Age: ptrTo(0)}, v2: &User{}, want: []FieldDifference{{"Age", "0", "(*int)(nil)"}}},
+	}
+	for _, tt := range tests {
+		if d := cmp.Diff(fieldDifferences(tt.v1, tt.v2), tt.want); d != "" {
+			t.Errorf("fieldDifferences(%v, %v) mismatch (-got +want):\n%s", tt.v1, tt.v2, d)
+		}
+	}
+}
+
+func TestJSONValueDivergence(t *testing.T) {
+	tests := []struct {
+		v1, v2     string
+		wantPtr    jsontext.Pointer
+		wantOffset int64
+		wantOk     bool
+	}{
+		{v1: `{"k":[1,2]}`, v2: `{"k":[1,2]}`},
+		{v1: `1`, v2: `2`, wantPtr: "", wantOffset: 0, wantOk: true},
+		{v1: `{"k":null}`, v2: `{"k":[]}`, wantPtr: "/k", wantOffset: 5, wantOk: true},
+		{v1: `{"a":1,"b":2}`, v2: `{"a":1,"c":2}`, wantPtr: "/b", wantOffset: 7, wantOk: true},
+		{v1: `{"a":[1, 2, 3]}`, v2: `{"a":[1,2,4]}`, wantPtr: "/a/2", wantOffset: 12, wantOk: true},
+		{v1: `[{"x":"a"}]`, v2: `[{"x":"b"}]`, wantPtr: "/0/x", wantOffset: 6, wantOk: true},
+		{v1: `[1]`, v2: `[1,2]`, wantPtr: "", wantOffset: 2, wantOk: true},
+		{v1: `[1,2]`, v2: `[1]`, wantPtr: "/1", wantOffset: 3, wantOk: true},
+		{v1: `{"a":1.0}`, v2: `{"a":1}`, wantPtr: "/a", wantOffset: 5, wantOk: true},
+	}
+	for _, tt := range tests {
+		gotPtr, gotOffset, gotOk := jsonValueDivergence([]byte(tt.v1), []byte(tt.v2))
+		if gotPtr != tt.wantPtr || gotOffset != tt.wantOffset || gotOk != tt.wantOk {
+			t.Errorf("jsonValueDivergence(%s, %s) = (%q, %d, %v), want (%q, %d, %v)",
+				tt.v1, tt.v2, gotPtr, gotOffset, gotOk, tt.wantPtr, tt.wantOffset, tt.wantOk)
+		}
+	}
+}
+
+func TestDiffGoValues(t *testing.T) {
+	var got []Difference
+	codec := &Codec{
+		DiffGoValues:     true,
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.SetGoValueCaptureRatio(0)
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	d := got[0]
+	if d.GoValueV1 != nil || d.GoValueV2 != nil {
+		t.Errorf("GoValueV1, GoValueV2 = %v, %v, want nil, nil", d.GoValueV1, d.GoValueV2)
+	}
+	if !strings.Contains(d.GoValueDiff, `-`) || !strings.Contains(d.GoValueDiff, `"x"`) {
+		t.Errorf("GoValueDiff = %q, want a diff mentioning the v1 name", d.GoValueDiff)
+	}
+	if b, _ := d.MarshalJSON(); !strings.Contains(string(b), `"GoValueDiff":`) {
+		t.Errorf("Difference.MarshalJSON = %s, want GoValueDiff", b)
+	}
+}
+
+func TestReportDivergence(t *testing.T) {
+	var got Difference
+	codec := &Codec{ReportDifference: func(d Difference) { got = d }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	codec.Marshal(reverifyUser{Name: "x"})
+	if got.JSONValuePointer != "/Aliases" || got.JSONValueOffset != 22 {
+		t.Errorf("Marshal: JSONValuePointer, JSONValueOffset = %q, %d, want %q, %d",
+			got.JSONValuePointer, got.JSONValueOffset, "/Aliases", 22)
+	}
+
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	if got.GoValuePath != "reverifyUser.Name" {
+		t.Errorf("Unmarshal: GoValuePath = %q, want %q", got.GoValuePath, "reverifyUser.Name")
+	}
+	want := []FieldDifference{{Field: "Name", ValueV1: `"x"`, ValueV2: `""`}}
+	if d := cmp.Diff(got.FieldDifferences, want); d != "" {
+		t.Errorf("Unmarshal: FieldDifferences mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// MigrationPlan is an ordered plan for migrating call sites from v1 to v2.
+// See [Codec.Plan]. It is intended to be marshaled as JSON
+// for consumption by tooling and review documents.
+type MigrationPlan struct {
+	// Steps are the call sites to migrate, ordered from the
+	// simplest to migrate to the most difficult to migrate.
+	Steps []PlanStep
+}
+
+// PlanStep is the plan for migrating the marshal or unmarshal calls
+// at a single call site.
+type PlanStep struct {
+	// Caller is the call site (see [Difference.Caller]).
+	Caller string
+	// Func is the operation and is either "Marshal" or "Unmarshal".
+	Func string
+
+	// Calls is the number of observed calls that compared v1 and v2.
+	Calls int64
+	// Diffs is the number of those calls that detected a difference.
+	Diffs int64
+
+	// Options are the names of the options (see [Difference.Options])
+	// that need to be passed at the call site to preserve v1 behavior.
+	Options []string `json:",omitzero"`
+	// Tags are suggested Go struct tag options (e.g., "format:emitnull")
+	// that may be used on the relevant struct fields
+	// instead of passing some of the Options at the call site
+	// (see [OptionInfo.Tag]). Tag options that do not apply to the type
+	// of the struct field at which the values diverged are omitted.
+	Tags []string `json:",omitzero"`
+	// Types are the names of the Go types reachable from the operated upon
+	// types that implement methods only called by v2 (see [AuditTypeMethods]).
+	Types []string `json:",omitzero"`
+	// Unexplained is the number of recorded differences
+	// that could not be attributed to any options.
+	Unexplained int `json:",omitzero"`
+
+	// Mode is the name of the recommended [CallMode] for the call site
+	// once the Options have been passed.
+	Mode string
+}
+
+// Plan combines the observed call rates (see [CodecMetrics.MarshalCallerDiffRates]
+// and [CodecMetrics.UnmarshalCallerDiffRates], which require
+// [Codec.TrackCallerDiffRates]), the options learned
+// from recorded differences (e.g., from [ReadCorpus]), and static analysis
+// of the recorded Go types (see [AuditTypeMethods]) into a migration plan
+// with a step for every call site that was either observed or recorded.
+//
+// The recommended mode of a step is:
+//   - [CallBothButReturnV1] if the call site has unexplained differences
+//     or has not yet been observed comparing v1 and v2,
+//   - [CallBothButReturnV2] if the call site has differences that are
+//     all resolved by the options or has types with v2-only methods, and
+//   - [OnlyCallV2] otherwise.
+//
+// Steps are ordered by recommended mode (with [OnlyCallV2] first),
+// then by the number of options and types, and then by call site.
+func (c *Codec) Plan(ds []Difference) MigrationPlan {
+	type stepKey struct{ caller, fnc string }
+	type stepData struct {
+		PlanStep
+		options map[string]bool
+		tags    map[string]bool
+		types   map[reflect.Type]bool
+	}
+	steps := make(map[stepKey]*stepData)
+	step := func(caller, fnc string) *stepData {
+		k := stepKey{caller, fnc}
+		if steps[k] == nil {
+			steps[k] = &stepData{
+				PlanStep: PlanStep{Caller: caller, Func: fnc},
+				options:  make(map[string]bool),
+				tags:     make(map[string]bool),
+				types:    make(map[reflect.Type]bool),
+			}
+		}
+		return steps[k]
+	}
+
+	for caller, r := range c.MarshalCallerDiffRates.All() {
+		if caller == OtherCallers {
+			continue // not a call site
+		}
+		s := step(caller, "Marshal")
+		s.Calls, s.Diffs = r.Calls, r.Diffs
+	}
+	for caller, r := range c.UnmarshalCallerDiffRates.All() {
+		if caller == OtherCallers {
+			continue // not a call site
+		}
+		s := step(caller, "Unmarshal")
+		s.Calls, s.Diffs = r.Calls, r.Diffs
+	}
+	for _, d := range ds {
+		s := step(d.Caller, d.Func)
+		if d.GoType != nil {
+			s.types[d.GoType] = true
+		}
+		if d.Options == nil || d.Check != "" {
+			s.Unexplained++
+			continue
+		}
+		_, sf, located := divergedField(d)
+		for name := range d.OptionNames() {
+			s.options[name] = true
+			if located {
+				if tag, ok := tagOption(name, sf.Type); ok {
+					s.tags[tag] = true
+				}
+			} else if info, ok := LookupOption(name); ok && info.Tag != "" {
+				s.tags[info.Tag] = true
+			}
+		}
+	}
+
+	var p MigrationPlan
+	for _, s := range steps {
+		s.Options = slices.Sorted(maps.Keys(s.options))
+		s.Tags = slices.Sorted(maps.Keys(s.tags))
+		for t := range s.types {
+			for _, tm := range AuditTypeMethods(t) {
+				if name := typeString(tm.Type); !slices.Contains(s.Types, name) {
+					s.Types = append(s.Types, name)
+				}
+			}
+		}
+		slices.Sort(s.Types)
+		switch {
+		case s.Unexplained > 0 || s.Calls == 0:
+			s.Mode = CallBothButReturnV1.String()
+		case s.Diffs > 0 || len(s.Options) > 0 || len(s.Types) > 0:
+			s.Mode = CallBothButReturnV2.String()
+		default:
+			s.Mode = OnlyCallV2.String()
+		}
+		p.Steps = append(p.Steps, s.PlanStep)
+	}
+
+	modeOrder := map[string]int{
+		OnlyCallV2.String():          0,
+		CallBothButReturnV2.String(): 1,
+		CallBothButReturnV1.String(): 2,
+	}
+	slices.SortFunc(p.Steps, func(x, y PlanStep) int {
+		return cmp.Or(
+			cmp.Compare(modeOrder[x.Mode], modeOrder[y.Mode]),
+			cmp.Compare(len(x.Options)+len(x.Types), len(y.Options)+len(y.Types)),
+			cmp.Compare(x.Caller, y.Caller),
+			cmp.Compare(x.Func, y.Func),
+		)
+	})
+	return p
+}
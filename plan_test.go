@@ -0,0 +1,103 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlan(t *testing.T) {
+	var c Codec
+	c.MarshalCallerDiffRates.observe("pkg.Ready", false)
+	c.MarshalCallerDiffRates.observe("pkg.Options", false)
+	c.MarshalCallerDiffRates.observe("pkg.Options", true)
+	c.UnmarshalCallerDiffRates.observe("pkg.Unexplained", true)
+	ds := []Difference{{
+		Caller:  "pkg.Options",
+		Func:    "Marshal",
+		GoType:  reflect.TypeFor[*auditRoot](),
+		Options: jsonv2.JoinOptions(jsonv2.FormatNilSliceAsNull(true), jsonv2.FormatNilMapAsNull(true)),
+	}, {
+		Caller: "pkg.Unexplained",
+		Func:   "Unmarshal",
+	}, {
+		Caller:  "pkg.Unobserved",
+		Func:    "Marshal",
+		Options: jsonv2.JoinOptions(),
+	}}
+
+	got := c.Plan(ds)
+	want := MigrationPlan{Steps: []PlanStep{{
+		Caller: "pkg.Ready",
+		Func:   "Marshal",
+		Calls:  1,
+		Mode:   "OnlyCallV2",
+	}, {
+		Caller:  "pkg.Options",
+		Func:    "Marshal",
+		Calls:   2,
+		Diffs:   1,
+		Options: []string{"jsonv2.FormatNilMapAsNull", "jsonv2.FormatNilSliceAsNull"},
+		Tags:    []string{"format:emitnull"},
+		Types:   []string{typeString(reflect.TypeFor[auditBoth]()), typeString(reflect.TypeFor[auditV2Only]())},
+		Mode:    "CallBothButReturnV2",
+	}, {
+		Caller:      "pkg.Unexplained",
+		Func:        "Unmarshal",
+		Calls:       1,
+		Diffs:       1,
+		Unexplained: 1,
+		Mode:        "CallBothButReturnV1",
+	}, {
+		Caller: "pkg.Unobserved",
+		Func:   "Marshal",
+		Mode:   "CallBothButReturnV1",
+	}}}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("Plan mismatch (-got +want):\n%s", d)
+	}
+}
+
+func TestPlanTags(t *testing.T) {
+	type T struct {
+		M map[string]int
+		S []int
+	}
+	ds := []Difference{{
+		Caller:  "pkg.Legacy",
+		Func:    "Marshal",
+		Options: jsonv1.OmitEmptyWithLegacySemantics(true),
+	}, {
+		Caller:           "pkg.Map",
+		Func:             "Marshal",
+		GoType:           reflect.TypeFor[T](),
+		JSONValuePointer: "/M",
+		Options:          jsonv2.FormatNilSliceAsNull(true),
+	}, {
+		Caller:           "pkg.Slice",
+		Func:             "Marshal",
+		GoType:           reflect.TypeFor[T](),
+		JSONValuePointer: "/S",
+		Options:          jsonv2.FormatNilSliceAsNull(true),
+	}}
+	var c Codec
+	got := make(map[string][]string)
+	for _, s := range c.Plan(ds).Steps {
+		got[s.Caller] = s.Tags
+	}
+	want := map[string][]string{
+		"pkg.Legacy": nil,
+		"pkg.Map":    nil,
+		"pkg.Slice":  {"format:emitnull"},
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("Plan tags mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// optionProbe is a named boolean option probed by [autoDetectOptions].
+type optionProbe struct {
+	name   string
+	option func(bool) jsonv2.Options
+}
+
+// RegisterOptionProbe is equivalent to [RegisterOption]
+// with only the Name and Option of the [OptionInfo] specified.
+func RegisterOptionProbe(name string, option func(bool) jsonv2.Options) {
+	RegisterOption(OptionInfo{Name: name, Option: option})
+}
+
+// optionProbes returns all options probed by [autoDetectOptions],
+// which are the options in [AllOptions] in the same order.
+// The first len(builtinOptions) probes are in [jsonv1.DefaultOptionsV1].
+func optionProbes() []optionProbe {
+	probes := make([]optionProbe, 0, len(builtinOptions))
+	for info := range AllOptions() {
+		probes = append(probes, optionProbe{info.Name, info.Option})
+	}
+	return probes
+}
+
+// lookupOption looks up an option probed by [autoDetectOptions] by name.
+func lookupOption(name string) (func(bool) jsonv2.Options, bool) {
+	info, ok := LookupOption(name)
+	return info.Option, ok
+}
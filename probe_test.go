@@ -0,0 +1,54 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegisterOptionProbe(t *testing.T) {
+	old := registeredOptions.Load()
+	t.Cleanup(func() { registeredOptions.Store(old) })
+	RegisterOptionProbe("jsonv2.StringifyNumbers", jsonv2.StringifyNumbers)
+
+	for _, tt := range []struct {
+		name   string
+		option func(bool) jsonv2.Options
+	}{
+		{"", jsonv2.StringifyNumbers},
+		{"jsonv2.StringifyNumbers", jsonv2.StringifyNumbers},
+		{"jsonv2.Deterministic", jsonv2.Deterministic},
+		{"custom.Option", func(bool) jsonv2.Options { return jsonv2.WithMarshalers(nil) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterOptionProbe(%q) did not panic", tt.name)
+				}
+			}()
+			RegisterOptionProbe(tt.name, tt.option)
+		}()
+	}
+
+	// Only v2 with the registered option enabled is considered equal to v1.
+	opts, ok := autoDetectOptions(1, func(o ...jsonv2.Options) bool {
+		v, _ := jsonv2.GetOption(jsonv2.JoinOptions(o...), jsonv2.StringifyNumbers)
+		return v
+	})
+	if !ok {
+		t.Fatalf("autoDetectOptions reported an emulation regression")
+	}
+	want := []string{"jsonv2.StringifyNumbers"}
+	if d := cmp.Diff(slices.Collect(optionNames(opts)), want); d != "" {
+		t.Errorf("detected options mismatch (-got +want):\n%s", d)
+	}
+	if d := cmp.Diff(slices.Collect(optionNames(parseOptionNames(want))), want); d != "" {
+		t.Errorf("optionNames(parseOptionNames) mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"maps"
+	"slices"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// OptionRecommendation is the aggregate of all options detected
+// by [Codec.AutoDetectOptions] (see [Codec.RecommendedOptions]).
+type OptionRecommendation struct {
+	// Options is the union of all detected options,
+	// which can be specified to every call to preserve v1 behavior.
+	Options jsonv2.Options
+	// Names are the names of the options in Options in sorted order
+	// (e.g., "jsonv2.FormatNilSliceAsNull").
+	Names []string
+	// Counts is the number of detected differences that needed
+	// each option across both marshal and unmarshal, keyed by name.
+	Counts map[string]int64
+}
+
+// RecommendedOptions returns the union of all options detected so far
+// to resolve differences in either marshal or unmarshal
+// (as counted in [CodecMetrics.MarshalOptionHistogram] and
+// [CodecMetrics.UnmarshalOptionHistogram]), such that at the end of
+// a migration period a single call summarizes the options that
+// need to be applied to remain compatible with v1.
+// It is only populated if [Codec.AutoDetectOptions] is enabled.
+func (c *Codec) RecommendedOptions() OptionRecommendation {
+	counts := make(map[string]int64)
+	for _, m := range []*expvar.Map{&c.MarshalOptionHistogram, &c.UnmarshalOptionHistogram} {
+		m.Do(func(kv expvar.KeyValue) {
+			if n, ok := kv.Value.(*expvar.Int); ok && n.Value() > 0 {
+				counts[kv.Key] += n.Value()
+			}
+		})
+	}
+	names := slices.Sorted(maps.Keys(counts))
+	return OptionRecommendation{Options: parseOptionNames(names), Names: names, Counts: counts}
+}
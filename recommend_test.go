@@ -0,0 +1,42 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestRecommendedOptions(t *testing.T) {
+	codec := &Codec{AutoDetectOptions: true}
+	if got := codec.RecommendedOptions(); got.Options != nil || len(got.Names) > 0 || len(got.Counts) > 0 {
+		t.Errorf("RecommendedOptions = %+v, want zero", got)
+	}
+
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	codec.Marshal(map[string][]int{"k": nil})
+	codec.Marshal(map[string]int(nil))
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(struct{ Name string }))
+
+	got := codec.RecommendedOptions()
+	wantNames := []string{"jsonv2.FormatNilMapAsNull", "jsonv2.FormatNilSliceAsNull", "jsonv2.MatchCaseInsensitiveNames"}
+	if !slices.Equal(got.Names, wantNames) {
+		t.Errorf("Names = %v, want %v", got.Names, wantNames)
+	}
+	if gotNames := slices.Collect(optionNames(got.Options)); !slices.Equal(gotNames, wantNames) {
+		t.Errorf("optionNames(Options) = %v, want %v", gotNames, wantNames)
+	}
+	wantCounts := map[string]int64{
+		"jsonv2.FormatNilMapAsNull":        1,
+		"jsonv2.FormatNilSliceAsNull":      2,
+		"jsonv2.MatchCaseInsensitiveNames": 1,
+	}
+	if !maps.Equal(got.Counts, wantCounts) {
+		t.Errorf("Counts = %v, want %v", got.Counts, wantCounts)
+	}
+}
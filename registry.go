@@ -0,0 +1,235 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"         // TODO: Use "encoding/json"
+)
+
+// OptionInfo describes a boolean option that controls a behavior difference
+// between v1 and v2, where enabling the option preserves the v1 behavior.
+// These are the options that [Codec.AutoDetectOptions] probes and
+// reports in [Difference.Options] (see [AllOptions]).
+type OptionInfo struct {
+	// Name is the Go expression for the option
+	// (e.g., "jsonv2.FormatNilSliceAsNull"), which is the name
+	// reported by [Difference.OptionNames] and the option histograms.
+	Name string
+	// Option constructs the option (e.g., [jsonv2.FormatNilSliceAsNull]).
+	Option func(bool) jsonv2.Options
+	// Category classifies the effect of the option.
+	Category OptionCategory
+	// Tag is the equivalent struct tag option (e.g., "format:emitnull"),
+	// or empty if the behavior can only be specified at the call site.
+	Tag string
+	// Description summarizes how v1 and v2 behave differently.
+	Description string
+}
+
+// builtinOptions are the options in [jsonv1.DefaultOptionsV1] sorted by name.
+var builtinOptions = []OptionInfo{{
+	Name:        "jsontext.AllowDuplicateNames",
+	Option:      jsontext.AllowDuplicateNames,
+	Description: "v1 permits duplicate names within a JSON object, while v2 rejects them.",
+}, {
+	Name:        "jsontext.AllowInvalidUTF8",
+	Option:      jsontext.AllowInvalidUTF8,
+	Description: "v1 permits invalid UTF-8 within JSON strings, while v2 rejects it.",
+}, {
+	Name:        "jsontext.EscapeForHTML",
+	Option:      jsontext.EscapeForHTML,
+	Category:    OptionCategoryFormatting,
+	Description: "v1 escapes '<', '>', and '&' within JSON strings, while v2 does not.",
+}, {
+	Name:        "jsontext.EscapeForJS",
+	Option:      jsontext.EscapeForJS,
+	Category:    OptionCategoryFormatting,
+	Description: "v1 escapes U+2028 and U+2029 within JSON strings, while v2 does not.",
+}, {
+	Name:        "jsontext.PreserveRawStrings",
+	Option:      jsontext.PreserveRawStrings,
+	Category:    OptionCategoryFormatting,
+	Description: "v1 preserves the escaping of JSON strings in raw JSON values (e.g., from MarshalJSON methods), while v2 reformats them.",
+}, {
+	Name:        "jsonv1.CallMethodsWithLegacySemantics",
+	Option:      jsonv1.CallMethodsWithLegacySemantics,
+	Description: "v1 calls marshal methods declared on pointer receivers only for addressable values, while v2 always calls them.",
+}, {
+	Name:        "jsonv1.FormatByteArrayAsArray",
+	Option:      jsonv1.FormatByteArrayAsArray,
+	Tag:         "format:array",
+	Description: "v1 formats Go byte arrays as JSON arrays of numbers, while v2 formats them as base64-encoded JSON strings.",
+}, {
+	Name:        "jsonv1.FormatBytesWithLegacySemantics",
+	Option:      jsonv1.FormatBytesWithLegacySemantics,
+	Description: "v1 formats Go byte slices with named element types as base64-encoded JSON strings, while v2 formats them as JSON arrays.",
+}, {
+	Name:        "jsonv1.FormatDurationAsNano",
+	Option:      jsonv1.FormatDurationAsNano,
+	Tag:         "format:nano",
+	Description: "v1 formats time.Duration values as JSON numbers of nanoseconds, while v2 requires an explicit format.",
+}, {
+	Name:        "jsonv1.MatchCaseSensitiveDelimiter",
+	Option:      jsonv1.MatchCaseSensitiveDelimiter,
+	Description: "v1 matches '-' and '_' exactly when matching JSON object names case-insensitively, while v2 ignores them.",
+}, {
+	Name:        "jsonv1.MergeWithLegacySemantics",
+	Option:      jsonv1.MergeWithLegacySemantics,
+	Description: "v1 merges JSON values into existing Go values with legacy semantics (e.g., a JSON null leaves a Go string unchanged), while v2 zeroes them.",
+}, {
+	Name:        "jsonv1.OmitEmptyWithLegacySemantics",
+	Option:      jsonv1.OmitEmptyWithLegacySemantics,
+	Description: "v1 omits fields with the `omitempty` tag option if the Go value is empty, while v2 omits them if the JSON value is empty.",
+}, {
+	Name:        "jsonv1.ParseBytesWithLooseRFC4648",
+	Option:      jsonv1.ParseBytesWithLooseRFC4648,
+	Description: "v1 permits newlines within base64-encoded JSON strings, while v2 rejects them.",
+}, {
+	Name:        "jsonv1.ParseTimeWithLooseRFC3339",
+	Option:      jsonv1.ParseTimeWithLooseRFC3339,
+	Description: "v1 permits time.Time values that do not strictly conform to RFC 3339, while v2 rejects them.",
+}, {
+	Name:        "jsonv1.ReportErrorsWithLegacySemantics",
+	Option:      jsonv1.ReportErrorsWithLegacySemantics,
+	Description: "v1 reports errors with legacy types and messages, while v2 reports structured errors.",
+}, {
+	Name:        "jsonv1.StringifyWithLegacySemantics",
+	Option:      jsonv1.StringifyWithLegacySemantics,
+	Description: "v1 applies the `string` tag option with legacy semantics (e.g., only to top-level numbers and bools), while v2 applies it to all nested numbers.",
+}, {
+	Name:        "jsonv1.UnmarshalArrayFromAnyLength",
+	Option:      jsonv1.UnmarshalArrayFromAnyLength,
+	Description: "v1 unmarshals JSON arrays of any length into Go arrays, while v2 requires an exact length.",
+}, {
+	Name:        "jsonv2.Deterministic",
+	Option:      jsonv2.Deterministic,
+	Description: "v1 marshals Go maps with sorted keys, while v2 marshals them in an unspecified order.",
+}, {
+	Name:        "jsonv2.FormatNilMapAsNull",
+	Option:      jsonv2.FormatNilMapAsNull,
+	Tag:         "format:emitnull",
+	Description: "v1 marshals nil Go maps as JSON null, while v2 marshals them as empty JSON objects.",
+}, {
+	Name:        "jsonv2.FormatNilSliceAsNull",
+	Option:      jsonv2.FormatNilSliceAsNull,
+	Tag:         "format:emitnull",
+	Description: "v1 marshals nil Go slices as JSON null, while v2 marshals them as empty JSON arrays.",
+}, {
+	Name:        "jsonv2.MatchCaseInsensitiveNames",
+	Option:      jsonv2.MatchCaseInsensitiveNames,
+	Tag:         "case:ignore",
+	Description: "v1 matches JSON object names to Go struct fields case-insensitively, while v2 matches them exactly.",
+}}
+
+var (
+	registeredOptionsMu sync.Mutex                   // serializes registrations
+	registeredOptions   atomic.Pointer[[]OptionInfo] // sorted by name
+)
+
+// AllOptions iterates over all options that control behavior differences
+// between v1 and v2, which are the options in [jsonv1.DefaultOptionsV1]
+// in sorted order followed by those registered by [RegisterOption]
+// in sorted order. It allows documentation and dashboards
+// to be generated from the options that detection may report.
+func AllOptions() iter.Seq[OptionInfo] {
+	return func(yield func(OptionInfo) bool) {
+		for _, info := range builtinOptions {
+			if !yield(info) {
+				return
+			}
+		}
+		if p := registeredOptions.Load(); p != nil {
+			for _, info := range *p {
+				if !yield(info) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// LookupOption looks up an option in [AllOptions] by name
+// (e.g., "jsonv2.FormatNilSliceAsNull").
+func LookupOption(name string) (OptionInfo, bool) {
+	if info, ok := builtinOption(name); ok {
+		return info, true
+	}
+	if p := registeredOptions.Load(); p != nil {
+		if i, ok := slices.BinarySearchFunc(*p, name, compareOptionName); ok {
+			return (*p)[i], true
+		}
+	}
+	return OptionInfo{}, false
+}
+
+// RegisterOption registers an additional boolean option that
+// [Codec.AutoDetectOptions] probes when attributing a difference,
+// such that differences caused by options not in [jsonv1.DefaultOptionsV1]
+// (e.g., options newly added upstream) are attributed
+// rather than remaining unexplained.
+//
+// The name is reported in [Difference.Options] and the option histograms
+// and should be the Go expression for the option (e.g., "jsonv1.SomeOption").
+// The option must be a boolean option declared by the JSON packages
+// such that it can be queried with [jsonv2.GetOption].
+// Similar to the options in [jsonv1.DefaultOptionsV1], the option is
+// assumed to be enabled in order to emulate v1 and is reported as needed
+// if disabling it causes v2 to behave differently from v1.
+//
+// It panics if the name is already in use or the option is invalid.
+// It is intended to be called during program initialization.
+func RegisterOption(info OptionInfo) {
+	if info.Name == "" || info.Option == nil {
+		panic("invalid option")
+	}
+	if !isBoolOption(info.Option) {
+		panic("option is not a boolean option: " + info.Name)
+	}
+
+	registeredOptionsMu.Lock()
+	defer registeredOptionsMu.Unlock()
+	if _, ok := LookupOption(info.Name); ok {
+		panic("duplicate option name: " + info.Name)
+	}
+	var infos []OptionInfo
+	if p := registeredOptions.Load(); p != nil {
+		infos = slices.Clone(*p)
+	}
+	infos = append(infos, info)
+	slices.SortFunc(infos, func(x, y OptionInfo) int { return cmp.Compare(x.Name, y.Name) })
+	registeredOptions.Store(&infos)
+}
+
+// builtinOption looks up an option in [builtinOptions] by name.
+func builtinOption(name string) (OptionInfo, bool) {
+	if i, ok := slices.BinarySearchFunc(builtinOptions, name, compareOptionName); ok {
+		return builtinOptions[i], true
+	}
+	return OptionInfo{}, false
+}
+
+func compareOptionName(info OptionInfo, name string) int {
+	return cmp.Compare(info.Name, name)
+}
+
+// isBoolOption reports whether option is a boolean option
+// that can be queried with [jsonv2.GetOption].
+func isBoolOption(option func(bool) jsonv2.Options) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false // unknown options panic
+		}
+	}()
+	v, ok := jsonv2.GetOption(option(true), option)
+	return v && ok
+}
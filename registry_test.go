@@ -0,0 +1,76 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAllOptions(t *testing.T) {
+	var names []string
+	for info := range AllOptions() {
+		names = append(names, info.Name)
+		if info.Option == nil || info.Description == "" {
+			t.Errorf("option %s is missing metadata", info.Name)
+		}
+		if got := optionCategory(info.Name); got != info.Category {
+			t.Errorf("optionCategory(%q) = %v, want %v", info.Name, got, info.Category)
+		}
+		if got, ok := LookupOption(info.Name); !ok || got.Name != info.Name {
+			t.Errorf("LookupOption(%q) = (%v, %v), want (%v, true)", info.Name, got.Name, ok, info.Name)
+		}
+		if name := slices.Collect(optionNames(info.Option(true))); !slices.Equal(name, []string{info.Name}) {
+			t.Errorf("optionNames(%s(true)) = %v, want [%v]", info.Name, name, info.Name)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("AllOptions is not sorted: %v", names)
+	}
+	if _, ok := LookupOption("bogus.Option"); ok {
+		t.Errorf("LookupOption(%q) unexpectedly succeeded", "bogus.Option")
+	}
+}
+
+func TestRegisterOption(t *testing.T) {
+	old := registeredOptions.Load()
+	t.Cleanup(func() { registeredOptions.Store(old) })
+	RegisterOption(OptionInfo{
+		Name:        "jsonv2.StringifyNumbers",
+		Option:      jsonv2.StringifyNumbers,
+		Tag:         "string",
+		Description: "Numbers are formatted as JSON strings.",
+	})
+
+	var names []string
+	for info := range AllOptions() {
+		names = append(names, info.Name)
+	}
+	if got, want := names[len(names)-1], "jsonv2.StringifyNumbers"; got != want {
+		t.Errorf("last option in AllOptions = %v, want %v", got, want)
+	}
+	info, ok := LookupOption("jsonv2.StringifyNumbers")
+	if !ok || info.Description != "Numbers are formatted as JSON strings." {
+		t.Errorf("LookupOption(%q) = (%+v, %v), want registered option", "jsonv2.StringifyNumbers", info, ok)
+	}
+
+	// The tag of a registered option is suggested for all field types.
+	type S struct {
+		N int `json:"n"`
+	}
+	got := tagSuggestions(Difference{
+		GoType:      reflect.TypeFor[S](),
+		GoValuePath: ".N",
+		Options:     jsonv2.StringifyNumbers(true),
+	})
+	want := []TagSuggestion{{Type: "github.com/go-json-experiment/jsonsplit.S", Field: "N", Tag: `json:"n,string"`}}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("tagSuggestions mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/go-json-experiment/jsonsplit/reporters/logrus
+
+go 1.24.2
+
+require (
+	github.com/go-json-experiment/json v0.0.0-20250714165856-be8212f5270d
+	github.com/go-json-experiment/jsonsplit v0.0.0-00010101000000-000000000000
+	github.com/sirupsen/logrus v1.9.3
+)
+
+replace github.com/go-json-experiment/jsonsplit => ../..
@@ -0,0 +1,50 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package logrus reports a [jsonsplit.Difference] to a [logrus.Logger].
+//
+// It is a separate module such that [jsonsplit] does not depend on [logrus].
+//
+// For example (with this package imported as jsonsplitlogrus):
+//
+//	codec.ReportDifference = jsonsplitlogrus.Reporter(logrus.StandardLogger(), logrus.WarnLevel)
+//
+// [logrus]: https://pkg.go.dev/github.com/sirupsen/logrus
+package logrus
+
+import (
+	"encoding/json"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	"github.com/go-json-experiment/jsonsplit"
+	"github.com/go-json-experiment/jsonsplit/reporters"
+	"github.com/sirupsen/logrus"
+)
+
+// Reporter returns a function for use with [jsonsplit.Codec.ReportDifference]
+// (or [jsonsplit.Codec.ReportEmulationRegression]) that logs every difference
+// to logger at the specified level with [reporters.Message]
+// and the [Fields] of the difference.
+func Reporter(logger *logrus.Logger, level logrus.Level) func(jsonsplit.Difference) {
+	return func(d jsonsplit.Difference) {
+		if logger.IsLevelEnabled(level) {
+			logger.WithFields(Fields(d)).Log(level, reporters.Message)
+		}
+	}
+}
+
+// Fields returns the fields of [jsonsplit.Difference.LogFields]
+// as [logrus.Fields], where JSON payloads are a [json.RawMessage]
+// such that they are embedded as raw JSON by [logrus.JSONFormatter]
+// rather than as strings.
+func Fields(d jsonsplit.Difference) logrus.Fields {
+	fields := make(logrus.Fields)
+	for key, val := range d.LogFields() {
+		if v, ok := val.(jsontext.Value); ok {
+			val = json.RawMessage(v)
+		}
+		fields[key] = val
+	}
+	return fields
+}
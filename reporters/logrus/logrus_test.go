@@ -0,0 +1,39 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-json-experiment/jsonsplit"
+	"github.com/go-json-experiment/jsonsplit/reporters"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReporter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = new(logrus.JSONFormatter)
+	codec := &jsonsplit.Codec{AutoDetectOptions: true, ReportDifference: Reporter(logger, logrus.WarnLevel)}
+	codec.SetMarshalCallMode(jsonsplit.CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v\n%s", err, buf.Bytes())
+	}
+	if got["msg"] != reporters.Message || got["level"] != "warning" {
+		t.Errorf("logged (%v, %v), want (%v, warning)", got["msg"], got["level"], reporters.Message)
+	}
+	if _, ok := got["JSONValueV1"].(map[string]any); !ok {
+		t.Errorf("JSONValueV1 = %#v, want raw JSON object", got["JSONValueV1"])
+	}
+	if opts, ok := got["Options"].([]any); !ok || len(opts) != 1 || opts[0] != "jsonv2.FormatNilSliceAsNull" {
+		t.Errorf("Options = %#v, want [jsonv2.FormatNilSliceAsNull]", got["Options"])
+	}
+}
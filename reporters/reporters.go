@@ -0,0 +1,51 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reporters provides adapters for reporting a [jsonsplit.Difference]
+// to structured logging libraries other than [log/slog]
+// (for which [jsonsplit.SlogReporter] may be used).
+//
+// Adapters for specific logging libraries are provided as separate modules
+// such that neither this package nor [jsonsplit] depends on any of them:
+//   - [github.com/go-json-experiment/jsonsplit/reporters/zap] for [zap]
+//   - [github.com/go-json-experiment/jsonsplit/reporters/zerolog] for [zerolog]
+//   - [github.com/go-json-experiment/jsonsplit/reporters/logrus] for [logrus]
+//
+// For other libraries, [Fields] converts a difference into a map of
+// structured fields that every common logging library accepts,
+// where option names are arrays of strings and JSON payloads are
+// embedded as raw JSON rather than as strings, and [Reporter] calls
+// an arbitrary logging function with those fields.
+//
+// [zap]: https://pkg.go.dev/go.uber.org/zap
+// [zerolog]: https://pkg.go.dev/github.com/rs/zerolog
+// [logrus]: https://pkg.go.dev/github.com/sirupsen/logrus
+package reporters
+
+import (
+	"maps"
+
+	"github.com/go-json-experiment/jsonsplit"
+)
+
+// Message is the log message used by [Reporter].
+const Message = "detected jsonv1-to-jsonv2 difference"
+
+// Reporter returns a function for use with [jsonsplit.Codec.ReportDifference]
+// (or [jsonsplit.Codec.ReportEmulationRegression]) that calls log
+// with [Message] and the [Fields] of every reported difference.
+func Reporter(log func(msg string, fields map[string]any)) func(jsonsplit.Difference) {
+	return func(d jsonsplit.Difference) {
+		log(Message, Fields(d))
+	}
+}
+
+// Fields returns the non-zero fields of d as a map keyed by field name
+// (e.g., "Caller" and "GoType") for use with structured loggers.
+// The fields are those of [jsonsplit.Difference.LogFields],
+// which are also logged by [jsonsplit.Difference.LogValue]
+// (except for the JSON payloads).
+func Fields(d jsonsplit.Difference) map[string]any {
+	return maps.Collect(d.LogFields())
+}
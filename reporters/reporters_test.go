@@ -0,0 +1,63 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/jsonsplit"
+)
+
+func TestReporter(t *testing.T) {
+	var gotMsg string
+	var gotFields []map[string]any
+	codec := &jsonsplit.Codec{
+		AutoDetectOptions: true,
+		ReportDifference: Reporter(func(msg string, fields map[string]any) {
+			gotMsg = msg
+			gotFields = append(gotFields, fields)
+		}),
+	}
+	codec.SetMarshalCallMode(jsonsplit.CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+	if len(gotFields) != 1 || gotMsg != Message {
+		t.Fatalf("got %d reports with message %q, want 1 with %q", len(gotFields), gotMsg, Message)
+	}
+	for _, key := range []string{"ID", "Caller", "Func", "GoType", "JSONValueV1", "JSONValueV2", "JSONValuePointer", "Options", "Fingerprint"} {
+		if _, ok := gotFields[0][key]; !ok {
+			t.Errorf("Fields is missing %q: %v", key, gotFields[0])
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	d := jsonsplit.Difference{
+		Func:           "Marshal",
+		GoType:         reflect.TypeFor[[]int](),
+		JSONValueV1:    []byte(`{"k":null}`),
+		JSONValueV2:    []byte("\"\xff\""),
+		GoValueDiff:    "-a\n+b\n",
+		ErrorV2:        fmt.Errorf("invalid"),
+		StandardV1:     true,
+		Options:        jsonv2.FormatNilSliceAsNull(true),
+		CausingOptions: jsonv2.FormatNilSliceAsNull(true),
+		TagSuggestions: []jsonsplit.TagSuggestion{{Type: "pkg.T", Field: "S", Tag: `json:",format:emitnull"`}},
+	}
+	b, err := json.Marshal(Fields(d))
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	want := fmt.Sprintf(`{"CausingOptions":["jsonv2.FormatNilSliceAsNull"],"ErrorV2":"invalid","Fingerprint":"%016x","Func":"Marshal",`+
+		`"GoType":"[]int","GoValueDiff":"-a\n+b\n","JSONValueV1":{"k":null},"JSONValueV2":"\"�\"",`+
+		`"Options":["jsonv2.FormatNilSliceAsNull"],"Severity":"Semantic","StandardV1":true,`+
+		`"TagSuggestions":[{"Type":"pkg.T","Field":"S","Tag":"json:\",format:emitnull\""}]}`, d.Fingerprint())
+	if string(b) != want {
+		t.Errorf("json.Marshal(Fields):\n\tgot  %s\n\twant %s", b, want)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package zap reports a [jsonsplit.Difference] to a [zap.Logger].
+//
+// It is a separate module such that [jsonsplit] does not depend on [zap].
+//
+// For example (with this package imported as jsonsplitzap):
+//
+//	logger := zap.Must(zap.NewProduction())
+//	codec.ReportDifference = jsonsplitzap.Reporter(logger, zapcore.WarnLevel)
+//
+// [zap]: https://pkg.go.dev/go.uber.org/zap
+package zap
+
+import (
+	"encoding/json"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	"github.com/go-json-experiment/jsonsplit"
+	"github.com/go-json-experiment/jsonsplit/reporters"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reporter returns a function for use with [jsonsplit.Codec.ReportDifference]
+// (or [jsonsplit.Codec.ReportEmulationRegression]) that logs every difference
+// to logger at the specified level with [reporters.Message]
+// and the [Fields] of the difference.
+func Reporter(logger *zap.Logger, level zapcore.Level) func(jsonsplit.Difference) {
+	return func(d jsonsplit.Difference) {
+		if ce := logger.Check(level, reporters.Message); ce != nil {
+			ce.Write(Fields(d)...)
+		}
+	}
+}
+
+// Fields returns the fields of [jsonsplit.Difference.LogFields]
+// as typed zap fields, where option names are logged with [zap.Strings]
+// and JSON payloads are embedded as raw JSON rather than as strings.
+func Fields(d jsonsplit.Difference) []zap.Field {
+	var fields []zap.Field
+	for key, val := range d.LogFields() {
+		switch val := val.(type) {
+		case string:
+			fields = append(fields, zap.String(key, val))
+		case []string:
+			fields = append(fields, zap.Strings(key, val))
+		case bool:
+			fields = append(fields, zap.Bool(key, val))
+		case jsontext.Value:
+			fields = append(fields, zap.Any(key, json.RawMessage(val)))
+		default:
+			fields = append(fields, zap.Any(key, val))
+		}
+	}
+	return fields
+}
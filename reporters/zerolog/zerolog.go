@@ -0,0 +1,55 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package zerolog reports a [jsonsplit.Difference] to a [zerolog.Logger].
+//
+// It is a separate module such that [jsonsplit] does not depend on [zerolog].
+//
+// For example (with this package imported as jsonsplitzerolog):
+//
+//	codec.ReportDifference = jsonsplitzerolog.Reporter(log.Logger, zerolog.WarnLevel)
+//
+// [zerolog]: https://pkg.go.dev/github.com/rs/zerolog
+package zerolog
+
+import (
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	"github.com/go-json-experiment/jsonsplit"
+	"github.com/go-json-experiment/jsonsplit/reporters"
+	"github.com/rs/zerolog"
+)
+
+// Reporter returns a function for use with [jsonsplit.Codec.ReportDifference]
+// (or [jsonsplit.Codec.ReportEmulationRegression]) that logs every difference
+// to logger at the specified level with [reporters.Message]
+// and the fields of the difference (see [AppendFields]).
+func Reporter(logger zerolog.Logger, level zerolog.Level) func(jsonsplit.Difference) {
+	return func(d jsonsplit.Difference) {
+		if e := logger.WithLevel(level); e.Enabled() {
+			AppendFields(e, d).Msg(reporters.Message)
+		}
+	}
+}
+
+// AppendFields appends the fields of [jsonsplit.Difference.LogFields]
+// to e as typed zerolog fields, where option names are logged with
+// [zerolog.Event.Strs] and JSON payloads are embedded with
+// [zerolog.Event.RawJSON] rather than as strings.
+func AppendFields(e *zerolog.Event, d jsonsplit.Difference) *zerolog.Event {
+	for key, val := range d.LogFields() {
+		switch val := val.(type) {
+		case string:
+			e = e.Str(key, val)
+		case []string:
+			e = e.Strs(key, val)
+		case bool:
+			e = e.Bool(key, val)
+		case jsontext.Value:
+			e = e.RawJSON(key, val)
+		default:
+			e = e.Interface(key, val)
+		}
+	}
+	return e
+}
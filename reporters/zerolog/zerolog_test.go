@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-json-experiment/jsonsplit"
+	"github.com/go-json-experiment/jsonsplit/reporters"
+	"github.com/rs/zerolog"
+)
+
+func TestReporter(t *testing.T) {
+	var buf bytes.Buffer
+	codec := &jsonsplit.Codec{AutoDetectOptions: true, ReportDifference: Reporter(zerolog.New(&buf), zerolog.WarnLevel)}
+	codec.SetMarshalCallMode(jsonsplit.CallBothButReturnV1)
+	codec.Marshal(map[string][]int{"k": nil})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v\n%s", err, buf.Bytes())
+	}
+	if got["message"] != reporters.Message || got["level"] != "warn" {
+		t.Errorf("logged (%v, %v), want (%v, warn)", got["message"], got["level"], reporters.Message)
+	}
+	if _, ok := got["JSONValueV1"].(map[string]any); !ok {
+		t.Errorf("JSONValueV1 = %#v, want raw JSON object", got["JSONValueV1"])
+	}
+	if opts, ok := got["Options"].([]any); !ok || len(opts) != 1 || opts[0] != "jsonv2.FormatNilSliceAsNull" {
+		t.Errorf("Options = %#v, want [jsonv2.FormatNilSliceAsNull]", got["Options"])
+	}
+
+	// Nothing is logged below the level of the logger.
+	buf.Reset()
+	Reporter(zerolog.New(&buf).Level(zerolog.ErrorLevel), zerolog.WarnLevel)(jsonsplit.Difference{Func: "Marshal"})
+	if buf.Len() > 0 {
+		t.Errorf("logged %s, want nothing", buf.Bytes())
+	}
+}
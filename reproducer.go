@@ -0,0 +1,358 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"maps"
+	"math"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WriteReproducer writes a self-contained Go test function that
+// reproduces the difference by calling both v1 and v2 and
+// failing if they still disagree, such that a difference
+// observed in production can be turned into a regression test.
+// The recorded v1 and v2 results are included as comments.
+//
+// For a marshal difference, the input is written as a Go composite literal
+// if [Difference.GoValue] is available and can be represented as such
+// (e.g., it has no unexported fields, channels, or functions).
+// Otherwise, the input is reconstructed by unmarshaling
+// [Difference.JSONValueV1] with v1, which may not reproduce the difference.
+// For an unmarshal difference, the input is [Difference.JSONValue]
+// and is unmarshaled into a zero value of the Go type.
+//
+// The necessary imports are listed in the doc comment of the test function.
+// Only the options that [jsonv1] and [jsonv2] use by default are specified,
+// so a difference that depends on caller-provided options
+// (see [Difference.CallerOptions], which are listed in the doc comment)
+// may need the test to be manually adjusted.
+func (d Difference) WriteReproducer(w io.Writer) error {
+	if d.GoType == nil {
+		return errors.New("difference has no Go type to reproduce")
+	}
+	if d.Check != "" {
+		return fmt.Errorf("difference from %s check cannot be reproduced", d.Check)
+	}
+	r := reproducer{imports: map[string]string{
+		"testing":                               "",
+		"github.com/go-json-experiment/json":    "jsonv2",
+		"github.com/go-json-experiment/json/v1": "jsonv1",
+	}}
+
+	var body bytes.Buffer
+	switch d.Func {
+	case "Marshal":
+		r.imports["bytes"] = ""
+		imports := maps.Clone(r.imports)
+		lit, err := r.literal(reflect.ValueOf(d.GoValue), false)
+		if err != nil {
+			r.imports = imports // avoid imports only needed by the literal
+		}
+		switch {
+		case d.GoValue != nil && err == nil:
+			fmt.Fprintf(&body, "in := %s\n", lit)
+		case d.JSONValueV1 != nil:
+			typ, err := r.typeExpr(d.GoType)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&body, "// The input is reconstructed from the v1 output and may not be exact.\n")
+			fmt.Fprintf(&body, "var in %s\n", typ)
+			fmt.Fprintf(&body, "if err := jsonv1.Unmarshal([]byte(%s), &in); err != nil {\n", quoteGo(string(d.JSONValueV1)))
+			fmt.Fprintf(&body, "t.Fatalf(\"jsonv1.Unmarshal error: %%v\", err)\n}\n")
+		default:
+			return errors.New("difference has no Go value to reproduce")
+		}
+		fmt.Fprintf(&body, "gotV1, errV1 := jsonv1.Marshal(in)\n")
+		fmt.Fprintf(&body, "gotV2, errV2 := jsonv2.Marshal(in)\n")
+		fmt.Fprintf(&body, "if !bytes.Equal(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {\n")
+		fmt.Fprintf(&body, "t.Errorf(\"Marshal mismatch:\\n\\tv1: %%s, %%v\\n\\tv2: %%s, %%v\", gotV1, errV1, gotV2, errV2)\n}\n")
+	case "Unmarshal":
+		if d.JSONValue == nil {
+			return errors.New("difference has no JSON value to reproduce")
+		}
+		if d.GoType.Kind() != reflect.Pointer {
+			return errors.New("difference has a non-pointer Go type")
+		}
+		typ, err := r.typeExpr(d.GoType.Elem())
+		if err != nil {
+			return err
+		}
+		r.imports["reflect"] = ""
+		fmt.Fprintf(&body, "in := []byte(%s)\n", quoteGo(string(d.JSONValue)))
+		fmt.Fprintf(&body, "gotV1, gotV2 := new(%s), new(%s)\n", typ, typ)
+		fmt.Fprintf(&body, "errV1 := jsonv1.Unmarshal(in, gotV1)\n")
+		fmt.Fprintf(&body, "errV2 := jsonv2.Unmarshal(in, gotV2)\n")
+		fmt.Fprintf(&body, "if !reflect.DeepEqual(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {\n")
+		fmt.Fprintf(&body, "t.Errorf(\"Unmarshal mismatch:\\n\\tv1: %%+v, %%v\\n\\tv2: %%+v, %%v\", gotV1, errV1, gotV2, errV2)\n}\n")
+	default:
+		return fmt.Errorf("unknown difference function %q", d.Func)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// TestReproduce%016x reproduces a difference between v1 and v2", d.Fingerprint())
+	if d.Caller != "" {
+		fmt.Fprintf(&b, "\n// detected at %s", d.Caller)
+	}
+	fmt.Fprintf(&b, ".\n")
+	if names := slices.Collect(d.CallerOptionNames()); len(names) > 0 {
+		fmt.Fprintf(&b, "// It was originally called with: %s.\n", strings.Join(names, ", "))
+	}
+	if names := slices.Collect(d.OptionNames()); len(names) > 0 {
+		fmt.Fprintf(&b, "// It is resolved by specifying: %s.\n", strings.Join(names, ", "))
+	}
+	fmt.Fprintf(&b, "//\n// Recorded results:\n//\n")
+	commentf := func(format string, args ...any) {
+		s := fmt.Sprintf(format, args...)
+		b.WriteString("//\t" + strings.ReplaceAll(s, "\n", "\n//\t") + "\n")
+	}
+	switch d.Func {
+	case "Marshal":
+		if d.JSONValuePointer != "" {
+			commentf("pointer: %s", d.JSONValuePointer)
+		}
+		commentf("v1: %s, %v", d.JSONValueV1, d.ErrorV1)
+		commentf("v2: %s, %v", d.JSONValueV2, d.ErrorV2)
+	case "Unmarshal":
+		if d.GoValuePath != "" {
+			commentf("path: %s", d.GoValuePath)
+		}
+		commentf("v1: %s, %v", r.comment(d.GoValueV1), d.ErrorV1)
+		commentf("v2: %s, %v", r.comment(d.GoValueV2), d.ErrorV2)
+	}
+	fmt.Fprintf(&b, "//\n// It requires the following imports:\n//\n")
+	for _, p := range slices.SortedFunc(maps.Keys(r.imports), func(x, y string) int {
+		// Sort standard library packages before all others.
+		isStd := func(p string) int {
+			if strings.Contains(p, ".") {
+				return 1
+			}
+			return 0
+		}
+		return cmp.Or(cmp.Compare(isStd(x), isStd(y)), cmp.Compare(x, y))
+	}) {
+		if name := r.imports[p]; name != "" {
+			fmt.Fprintf(&b, "//\t%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(&b, "//\t%q\n", p)
+		}
+	}
+	fmt.Fprintf(&b, "func TestReproduce%016x(t *testing.T) {\n%s}\n", d.Fingerprint(), body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// reproducer formats Go types and values as Go source code.
+type reproducer struct {
+	imports map[string]string // package path to explicit package name
+	visited map[uintptr]bool  // pointers currently being formatted
+}
+
+// comment formats v as a Go literal if possible,
+// otherwise it falls back on the fmt representation.
+func (r *reproducer) comment(v any) string {
+	imports := maps.Clone(r.imports)
+	if s, err := r.literal(reflect.ValueOf(v), false); err == nil {
+		return s
+	}
+	r.imports = imports // avoid imports only needed by the comment
+	return fmt.Sprintf("%+v", v)
+}
+
+// typeExpr formats t as a Go type expression,
+// recording any packages that need to be imported.
+func (r *reproducer) typeExpr(t reflect.Type) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil // predeclared type
+		}
+		if strings.ContainsAny(t.Name(), "[]") {
+			return "", fmt.Errorf("generic type %v cannot be reproduced", t)
+		}
+		name, _, _ := strings.Cut(t.String(), ".")
+		if name == path.Base(t.PkgPath()) {
+			name = ""
+		}
+		r.imports[t.PkgPath()] = name
+		return t.String(), nil
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s, err := r.typeExpr(t.Elem())
+		return "*" + s, err
+	case reflect.Slice:
+		s, err := r.typeExpr(t.Elem())
+		return "[]" + s, err
+	case reflect.Array:
+		s, err := r.typeExpr(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + s, err
+	case reflect.Map:
+		k, err := r.typeExpr(t.Key())
+		if err != nil {
+			return "", err
+		}
+		v, err := r.typeExpr(t.Elem())
+		return "map[" + k + "]" + v, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("unnamed type %v cannot be reproduced", t)
+}
+
+// literal formats v as a Go expression.
+// If typed is specified, then the expression is used in a context
+// where the type is already known (e.g., a composite literal element),
+// allowing constants and nil values to be written without a conversion.
+func (r *reproducer) literal(v reflect.Value, typed bool) (string, error) {
+	if !v.IsValid() {
+		return "nil", nil
+	}
+	t := v.Type()
+	typ, err := r.typeExpr(t)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	switch t.Kind() {
+	case reflect.Bool:
+		s = strconv.FormatBool(v.Bool())
+		typed = typed || typ == "bool"
+	case reflect.String:
+		s = strconv.Quote(v.String())
+		typed = typed || typ == "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+		typed = typed || typ == "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != f || f < -math.MaxFloat64 || f > math.MaxFloat64 {
+			return "", fmt.Errorf("non-finite float %v cannot be reproduced", f)
+		}
+		s = strconv.FormatFloat(f, 'g', -1, t.Bits())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return r.literal(v.Elem(), false)
+	case reflect.Pointer:
+		if v.IsNil() {
+			break
+		}
+		if r.visited[v.Pointer()] {
+			return "", errors.New("cyclic value cannot be reproduced")
+		}
+		if r.visited == nil {
+			r.visited = make(map[uintptr]bool)
+		}
+		r.visited[v.Pointer()] = true
+		defer delete(r.visited, v.Pointer())
+		elem, err := r.literal(v.Elem(), true)
+		if err != nil {
+			return "", err
+		}
+		switch t.Elem().Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			if v.Elem().Kind() != reflect.Slice && v.Elem().Kind() != reflect.Map || !v.Elem().IsNil() {
+				return "&" + elem, nil
+			}
+		}
+		elemTyp, _ := r.typeExpr(t.Elem())
+		return fmt.Sprintf("func() %s { v := %s(%s); return &v }()", typ, elemTyp, elem), nil
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t.Elem().PkgPath() == "" {
+			return fmt.Sprintf("%s(%s)", typ, strconv.Quote(string(v.Bytes()))), nil
+		}
+		fallthrough
+	case reflect.Array, reflect.Struct:
+		var elems []string
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				elem, err := r.literal(v.Index(i), t.Elem().Kind() != reflect.Interface)
+				if err != nil {
+					return "", err
+				}
+				elems = append(elems, elem)
+			}
+		case reflect.Map:
+			for iter := v.MapRange(); iter.Next(); {
+				key, err := r.literal(iter.Key(), t.Key().Kind() != reflect.Interface)
+				if err != nil {
+					return "", err
+				}
+				val, err := r.literal(iter.Value(), t.Elem().Kind() != reflect.Interface)
+				if err != nil {
+					return "", err
+				}
+				elems = append(elems, key+": "+val)
+			}
+			slices.Sort(elems) // for deterministic output
+		case reflect.Struct:
+			for i := range v.NumField() {
+				if v.Field(i).IsZero() {
+					continue
+				}
+				f := t.Field(i)
+				if !f.IsExported() {
+					return "", fmt.Errorf("unexported field %v.%s cannot be reproduced", t, f.Name)
+				}
+				val, err := r.literal(v.Field(i), f.Type.Kind() != reflect.Interface)
+				if err != nil {
+					return "", err
+				}
+				elems = append(elems, f.Name+": "+val)
+			}
+		}
+		return typ + "{" + strings.Join(elems, ", ") + "}", nil
+	default:
+		if v.IsZero() && (t.Kind() == reflect.Chan || t.Kind() == reflect.Func) {
+			break
+		}
+		return "", fmt.Errorf("%v value cannot be reproduced", t.Kind())
+	}
+	switch {
+	case s == "": // nil pointer, slice, map, channel, or function
+		if typed {
+			return "nil", nil
+		}
+		return "(" + typ + ")(nil)", nil
+	case typed:
+		return s, nil
+	default:
+		return typ + "(" + s + ")", nil
+	}
+}
+
+// quoteGo formats s as a Go string literal,
+// preferring a raw string literal when possible.
+func quoteGo(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type reproducerStruct struct {
+	Name    string
+	Aliases []string
+	Extra   map[string]any
+	Next    *reproducerStruct
+	Age     *int
+}
+
+func TestReproducerLiteral(t *testing.T) {
+	for _, tt := range []struct {
+		in      any
+		want    string
+		wantErr bool
+	}{
+		{in: nil, want: "nil"},
+		{in: true, want: "true"},
+		{in: "hello", want: `"hello"`},
+		{in: 5, want: "5"},
+		{in: int64(5), want: "int64(5)"},
+		{in: 1.5, want: "float64(1.5)"},
+		{in: CallBothButReturnV2, want: "jsonsplit.CallMode(3)"},
+		{in: []byte("abc"), want: `[]uint8("abc")`},
+		{in: []int(nil), want: "([]int)(nil)"},
+		{in: []any{1, "x", nil, int8(-1)}, want: `[]any{1, "x", nil, int8(-1)}`},
+		{in: map[string]int{"b": 2, "a": 1}, want: `map[string]int{"a": 1, "b": 2}`},
+		{in: [2]bool{true}, want: `[2]bool{true, false}`},
+		{in: ptrTo(3), want: "func() *int { v := int(3); return &v }()"},
+		{
+			in: &reproducerStruct{
+				Name:    "x",
+				Aliases: []string{},
+				Extra:   map[string]any{"k": 1.0},
+				Next:    &reproducerStruct{Age: ptrTo(1)},
+			},
+			want: `&jsonsplit.reproducerStruct{Name: "x", Aliases: []string{}, Extra: map[string]any{"k": float64(1)}, Next: &jsonsplit.reproducerStruct{Age: func() *int { v := int(1); return &v }()}}`,
+		},
+		{in: struct{ A int }{}, wantErr: true},
+		{in: make(chan int), wantErr: true},
+		{in: reflect.TypeFor[int](), wantErr: true},
+		{in: func() any { v := new(reproducerStruct); v.Next = v; return v }(), wantErr: true},
+	} {
+		r := reproducer{imports: make(map[string]string)}
+		got, err := r.literal(reflect.ValueOf(tt.in), false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("literal(%#v) error = %v, want error %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("literal(%#v):\n\tgot  %s\n\twant %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteReproducer(t *testing.T) {
+	var d Difference
+	var codec Codec
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.ReportDifference = func(d2 Difference) { d = d2 }
+
+	for _, tt := range []struct {
+		call func()
+		want string
+	}{{
+		call: func() { codec.Marshal(reproducerStruct{Name: "x", Aliases: []string{}}) },
+		want: `// TestReproduce$FINGERPRINT reproduces a difference between v1 and v2
+// detected at $CALLER.
+//
+// Recorded results:
+//
+//	pointer: /Extra
+//	v1: {"Name":"x","Aliases":[],"Extra":null,"Next":null,"Age":null}, <nil>
+//	v2: {"Name":"x","Aliases":[],"Extra":{},"Next":null,"Age":null}, <nil>
+//
+// It requires the following imports:
+//
+//	"bytes"
+//	"testing"
+//	jsonv2 "github.com/go-json-experiment/json"
+//	jsonv1 "github.com/go-json-experiment/json/v1"
+//	"github.com/go-json-experiment/jsonsplit"
+func TestReproduce$FINGERPRINT(t *testing.T) {
+	in := jsonsplit.reproducerStruct{Name: "x", Aliases: []string{}}
+	gotV1, errV1 := jsonv1.Marshal(in)
+	gotV2, errV2 := jsonv2.Marshal(in)
+	if !bytes.Equal(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {
+		t.Errorf("Marshal mismatch:\n\tv1: %s, %v\n\tv2: %s, %v", gotV1, errV1, gotV2, errV2)
+	}
+}
+`,
+	}, {
+		call: func() { codec.Unmarshal([]byte(`{"name":"x","Age":1}`), new(reproducerStruct)) },
+		want: `// TestReproduce$FINGERPRINT reproduces a difference between v1 and v2
+// detected at $CALLER.
+//
+// Recorded results:
+//
+//	path: reproducerStruct.Name
+//	v1: &jsonsplit.reproducerStruct{Name: "x", Age: func() *int { v := int(1); return &v }()}, <nil>
+//	v2: &jsonsplit.reproducerStruct{Age: func() *int { v := int(1); return &v }()}, <nil>
+//
+// It requires the following imports:
+//
+//	"reflect"
+//	"testing"
+//	jsonv2 "github.com/go-json-experiment/json"
+//	jsonv1 "github.com/go-json-experiment/json/v1"
+//	"github.com/go-json-experiment/jsonsplit"
+func TestReproduce$FINGERPRINT(t *testing.T) {
+	in := []byte(` + "`" + `{"name":"x","Age":1}` + "`" + `)
+	gotV1, gotV2 := new(jsonsplit.reproducerStruct), new(jsonsplit.reproducerStruct)
+	errV1 := jsonv1.Unmarshal(in, gotV1)
+	errV2 := jsonv2.Unmarshal(in, gotV2)
+	if !reflect.DeepEqual(gotV1, gotV2) || (errV1 == nil) != (errV2 == nil) {
+		t.Errorf("Unmarshal mismatch:\n\tv1: %+v, %v\n\tv2: %+v, %v", gotV1, errV1, gotV2, errV2)
+	}
+}
+`,
+	}} {
+		d = Difference{}
+		tt.call()
+		var got strings.Builder
+		if err := d.WriteReproducer(&got); err != nil {
+			t.Fatalf("WriteReproducer error: %v", err)
+		}
+		want := strings.NewReplacer("$FINGERPRINT", fmt.Sprintf("%016x", d.Fingerprint()), "$CALLER", d.Caller).Replace(tt.want)
+		if diff := cmp.Diff(got.String(), want); diff != "" {
+			t.Errorf("%s reproducer mismatch (-got +want):\n%s", d.Func, diff)
+		}
+	}
+
+	if err := (Difference{Func: "Marshal"}).WriteReproducer(io.Discard); err == nil {
+		t.Errorf("WriteReproducer error = nil, want non-nil")
+	}
+}
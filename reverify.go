@@ -0,0 +1,136 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// ReverifyStatus is the outcome of replaying a recorded [Difference].
+type ReverifyStatus int
+
+const (
+	// ReverifyUnavailable reports that the difference could not be replayed
+	// since its Go type or input values were not recorded,
+	// or since it was detected by an additional check (see [Difference.Check]).
+	ReverifyUnavailable ReverifyStatus = iota
+	// ReverifyFixed reports that v1 and v2 now behave identically.
+	ReverifyFixed
+	// ReverifyReproduced reports that v1 and v2 still behave differently
+	// and that the difference is resolved by the same options as recorded.
+	ReverifyReproduced
+	// ReverifyReattributed reports that v1 and v2 still behave differently,
+	// but that the difference is resolved by different options than recorded.
+	ReverifyReattributed
+)
+
+var reverifyStatusNames = map[ReverifyStatus]string{
+	ReverifyUnavailable:  "ReverifyUnavailable",
+	ReverifyFixed:        "ReverifyFixed",
+	ReverifyReproduced:   "ReverifyReproduced",
+	ReverifyReattributed: "ReverifyReattributed",
+}
+
+func (s ReverifyStatus) String() string {
+	if name, ok := reverifyStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("ReverifyStatus(%d)", s)
+}
+
+// Reverification is the result of replaying a recorded [Difference].
+type Reverification struct {
+	// Difference is the recorded difference.
+	Difference Difference
+	// Status is the outcome of replaying the difference.
+	Status ReverifyStatus
+	// Options is the set of options that currently need to be enabled
+	// in order to resolve the difference between v1 and v2.
+	// It is only populated for [ReverifyReproduced] and [ReverifyReattributed].
+	Options jsonv2.Options
+}
+
+// Reverify replays recorded differences (e.g., from [ReadCorpus])
+// against the currently linked v1 and v2 implementations.
+// This is useful after upgrading the JSON module to determine which
+// differences have since been fixed upstream, which still reproduce,
+// and which are now attributed to different options.
+//
+// For a marshal difference, the input is [Difference.GoValue] if available,
+// otherwise it is reconstructed by unmarshaling [Difference.JSONValueV1]
+// into a new value of [Difference.GoType] with v1.
+// For an unmarshal difference, the input is [Difference.JSONValue],
+// which is unmarshaled into a new value of [Difference.GoType].
+// Calls are replayed with [Difference.CallerOptions] if recorded,
+// otherwise with the default options for v1 and v2.
+//
+// Results are compared according to the equality functions in c
+// (e.g., [Codec.EqualJSONValues]) and options are always auto-detected.
+// It does not update any metrics or report any differences.
+func (c *Codec) Reverify(ds []Difference) []Reverification {
+	rs := make([]Reverification, len(ds))
+	for i, d := range ds {
+		rs[i] = Reverification{Difference: d}
+		o, arshalEqual := c.replay(d)
+		if arshalEqual == nil {
+			continue
+		}
+		if arshalEqual(o...) {
+			rs[i].Status = ReverifyFixed
+			continue
+		}
+		options, _ := autoDetectOptions(c.autoDetectParallelism(), arshalEqual, o...)
+		if slices.Equal(slices.Collect(optionNames(options)), slices.Collect(d.OptionNames())) {
+			rs[i].Status = ReverifyReproduced
+		} else {
+			rs[i].Status = ReverifyReattributed
+		}
+		rs[i].Options = options
+	}
+	return rs
+}
+
+// replay replays the v1 call of a recorded difference and returns
+// the options originally passed by the caller along with a function that
+// calls v2 with the provided options and reports whether the
+// result is equal to the v1 result.
+// It returns a nil function if the difference cannot be replayed.
+func (c *Codec) replay(d Difference) (o []jsonv2.Options, arshalEqual func(...jsonv2.Options) bool) {
+	if d.CallerOptions != nil {
+		o = append(o, d.CallerOptions)
+	}
+	switch {
+	case d.Check != "":
+		return nil, nil // only direct comparisons between v1 and v2 are replayed
+	case d.Func == "Marshal" && (d.GoValue != nil || d.GoType != nil && d.JSONValueV1 != nil):
+		v := d.GoValue
+		if v == nil {
+			p := reflect.New(d.GoType)
+			if jsonv1Unmarshal(d.JSONValueV1, p.Interface(), o...) != nil {
+				return nil, nil
+			}
+			v = p.Elem().Interface()
+		}
+		buf1, err1 := jsonv1Marshal(v, o...)
+		return o, func(o ...jsonv2.Options) bool {
+			buf2, err2 := jsonv2.Marshal(v, o...)
+			return c.jsonEqual(buf1, buf2) && c.errorsEqual(err1, err2)
+		}
+	case d.Func == "Unmarshal" && d.GoType != nil && d.GoType.Kind() == reflect.Pointer && d.JSONValue != nil:
+		val1 := reflect.New(d.GoType.Elem()).Interface()
+		err1 := jsonv1Unmarshal(d.JSONValue, val1, o...)
+		return o, func(o ...jsonv2.Options) bool {
+			val2 := reflect.New(d.GoType.Elem()).Interface()
+			err2 := jsonv2.Unmarshal(d.JSONValue, val2, o...)
+			return c.unmarshalEqual(val1, val2, err1, err2, len(d.JSONValue))
+		}
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+type reverifyUser struct {
+	Name    string
+	Aliases []string
+}
+
+func TestReverify(t *testing.T) {
+	var corpus strings.Builder
+	var live []Difference
+	codec := Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) {
+		corpus.WriteString(d.String() + "\n")
+		live = append(live, d)
+	}}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(reverifyUser{Name: "x"})
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "diffs.jsonl"), []byte(corpus.String()), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := ReadCorpus(dir, reflect.TypeFor[reverifyUser]())
+	if err != nil {
+		t.Fatalf("ReadCorpus error: %v", err)
+	}
+	if len(recorded) != 2 || recorded[0].GoType != reflect.TypeFor[reverifyUser]() || recorded[1].GoType != reflect.TypeFor[*reverifyUser]() {
+		t.Fatalf("ReadCorpus = %v, want two differences with resolved Go types", recorded)
+	}
+
+	ds := []Difference{
+		recorded[0], // marshal reconstructed from JSONValueV1
+		recorded[1],
+		live[0], // marshal with GoValue
+		{Func: "Marshal", GoValue: reverifyUser{}, Options: jsonv1OmitEmpty()},                 // reattributed
+		{Func: "Unmarshal", GoType: reflect.TypeFor[*reverifyUser](), JSONValue: []byte(`{}`)}, // fixed
+		{Func: "Unmarshal", JSONValue: []byte(`{}`)},                                           // unavailable
+	}
+	var got []string
+	for _, r := range (&Codec{}).Reverify(ds) {
+		got = append(got, r.Status.String()+": "+strings.Join(slices.Collect(optionNames(r.Options)), ","))
+	}
+	want := []string{
+		"ReverifyReproduced: jsonv2.FormatNilSliceAsNull",
+		"ReverifyReproduced: jsonv2.MatchCaseInsensitiveNames",
+		"ReverifyReproduced: jsonv2.FormatNilSliceAsNull",
+		"ReverifyReattributed: jsonv2.FormatNilSliceAsNull",
+		"ReverifyFixed: ",
+		"ReverifyUnavailable: ",
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("Reverify mismatch (-got +want):\n%s", d)
+	}
+}
+
+func jsonv1OmitEmpty() jsonv2.Options {
+	return jsonv1.OmitEmptyWithLegacySemantics(true)
+}
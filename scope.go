@@ -0,0 +1,97 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptionScope classifies where an option reported in [Difference.Options]
+// can be specified to resolve the difference (see [Difference.OptionScopes]).
+// It allows fixes to be routed to the owners of types or of services.
+type OptionScope int
+
+const (
+	// OptionScopeCall is an option that can only be specified
+	// as an option to the call (or to every call),
+	// which is a fix for the owner of the service making the call
+	// (e.g., [jsontext.AllowDuplicateNames]).
+	OptionScopeCall OptionScope = iota
+	// OptionScopeTag is an option that can be specified as a struct tag option
+	// on the struct field at which the values diverged (see [Difference.TagSuggestions]),
+	// which is a fix for the owner of the type
+	// (e.g., `json:",case:ignore"` for [jsonv2.MatchCaseInsensitiveNames]).
+	OptionScopeTag
+
+	numOptionScopes = iota
+)
+
+var optionScopeNames = [numOptionScopes]string{
+	OptionScopeCall: "Call",
+	OptionScopeTag:  "Tag",
+}
+
+// String returns the name of the scope (e.g., "Tag").
+func (s OptionScope) String() string {
+	if 0 <= s && s < numOptionScopes {
+		return optionScopeNames[s]
+	}
+	return fmt.Sprintf("OptionScope(%d)", int(s))
+}
+
+// MarshalText returns the name of the scope.
+func (s OptionScope) MarshalText() ([]byte, error) {
+	if 0 <= s && s < numOptionScopes {
+		return []byte(optionScopeNames[s]), nil
+	}
+	return nil, fmt.Errorf("invalid option scope: %d", int(s))
+}
+
+// UnmarshalText parses the name of a scope.
+func (s *OptionScope) UnmarshalText(b []byte) error {
+	for i, name := range optionScopeNames {
+		if string(b) == name {
+			*s = OptionScope(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid option scope: %q", b)
+}
+
+// optionScopes classifies every option in [Difference.Options]
+// by whether it is expressible as a struct tag option on the struct field
+// at which the values diverged. It returns nil if there are no options.
+func optionScopes(d Difference) map[string]OptionScope {
+	var m map[string]OptionScope
+	_, sf, ok := divergedField(d)
+	for name := range d.OptionNames() {
+		if m == nil {
+			m = make(map[string]OptionScope)
+		}
+		m[name] = OptionScopeCall
+		if ok {
+			if _, isTag := tagOption(name, sf.Type); isTag {
+				m[name] = OptionScopeTag
+			}
+		}
+	}
+	return m
+}
+
+// divergedField locates the struct field at which the values diverged
+// (as located by GoValuePath or JSONValuePointer), returning
+// the struct type that declares the field and the field itself.
+func divergedField(d Difference) (reflect.Type, reflect.StructField, bool) {
+	switch {
+	case d.GoType == nil:
+		return nil, reflect.StructField{}, false
+	case d.GoValuePath != "":
+		return fieldAtGoPath(d.GoType, d.GoValuePath)
+	case d.JSONValuePointer != "":
+		return fieldAtJSONPointer(d.GoType, d.JSONValuePointer)
+	}
+	return nil, reflect.StructField{}, false
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"maps"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestOptionScopes(t *testing.T) {
+	for s := range OptionScope(numOptionScopes) {
+		b, err := jsonv2.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var got OptionScope
+		if err := jsonv2.Unmarshal(b, &got); err != nil || got != s {
+			t.Errorf("Unmarshal(%s) = (%v, %v), want %v", b, got, err, s)
+		}
+	}
+
+	var got map[string]OptionScope
+	codec := &Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { got = d.OptionScopes }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	codec.Marshal(struct {
+		S []int
+		H string
+	}{H: "<>"})
+	want := map[string]OptionScope{
+		"jsontext.EscapeForHTML":      OptionScopeCall,
+		"jsonv2.FormatNilSliceAsNull": OptionScopeTag,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("OptionScopes = %v, want %v", got, want)
+	}
+
+	codec.Marshal(map[string][]int{"k": nil})
+	want = map[string]OptionScope{"jsonv2.FormatNilSliceAsNull": OptionScopeCall}
+	if !maps.Equal(got, want) {
+		t.Errorf("OptionScopes = %v, want %v", got, want)
+	}
+
+	codec.Unmarshal([]byte(`{"name":"x"}`), new(struct{ Name string }))
+	want = map[string]OptionScope{"jsonv2.MatchCaseInsensitiveNames": OptionScopeTag}
+	if !maps.Equal(got, want) {
+		t.Errorf("OptionScopes = %v, want %v", got, want)
+	}
+}
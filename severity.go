@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+)
+
+// Severity classifies how consequential a [Difference] is.
+// See [Codec.ReportSeverities].
+type Severity int
+
+const (
+	// SeveritySemantic is a difference in the meaning of the results
+	// (e.g., different JSON values or Go values, or only one call failing).
+	SeveritySemantic Severity = iota
+	// SeverityFormattingOnly is a difference between JSON outputs that
+	// represent the same JSON value and only differ in formatting
+	// (e.g., string escaping, number representation, whitespace,
+	// or the order of object members) such that they are identical
+	// after canonicalization according to RFC 8785.
+	SeverityFormattingOnly
+	// SeverityErrorOnly is a difference where both v1 and v2 failed,
+	// but with different errors.
+	SeverityErrorOnly
+
+	numSeverities = iota
+)
+
+var severityNames = [numSeverities]string{
+	SeveritySemantic:       "Semantic",
+	SeverityFormattingOnly: "FormattingOnly",
+	SeverityErrorOnly:      "ErrorOnly",
+}
+
+// String returns the name of the severity (e.g., "FormattingOnly").
+func (s Severity) String() string {
+	if 0 <= s && s < numSeverities {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText returns the name of the severity.
+func (s Severity) MarshalText() ([]byte, error) {
+	if 0 <= s && s < numSeverities {
+		return []byte(severityNames[s]), nil
+	}
+	return nil, fmt.Errorf("invalid severity: %d", int(s))
+}
+
+// UnmarshalText parses the name of a severity.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for i, name := range severityNames {
+		if string(b) == name {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid severity: %q", b)
+}
+
+// severityOf classifies the difference d.
+func severityOf(d Difference) Severity {
+	switch {
+	case d.ErrorV1 != nil && d.ErrorV2 != nil:
+		return SeverityErrorOnly
+	case d.ErrorV1 == nil && d.ErrorV2 == nil && len(d.JSONValueV1) > 0 && len(d.JSONValueV2) > 0:
+		v1, v2 := d.JSONValueV1.Clone(), d.JSONValueV2.Clone()
+		if !bytes.Equal(v1, v2) && v1.Canonicalize() == nil && v2.Canonicalize() == nil && bytes.Equal(v1, v2) {
+			return SeverityFormattingOnly
+		}
+	}
+	return SeveritySemantic
+}
+
+// reportsSeverity reports whether differences of severity s
+// are passed to [Codec.ReportDifference] according to [Codec.ReportSeverities].
+func (c *Codec) reportsSeverity(s Severity) bool {
+	return len(c.ReportSeverities) == 0 || slices.Contains(c.ReportSeverities, s)
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestSeverity(t *testing.T) {
+	errFail := errors.New("fail")
+	tests := []struct {
+		d    Difference
+		want Severity
+	}{
+		{Difference{JSONValueV1: []byte(`null`), JSONValueV2: []byte(`[]`)}, SeveritySemantic},
+		{Difference{JSONValueV1: []byte(`"\u003c"`), JSONValueV2: []byte(`"<"`)}, SeverityFormattingOnly},
+		{Difference{JSONValueV1: []byte(`{"a":1,"b":1.0}`), JSONValueV2: []byte(`{"b":1,"a":1}`)}, SeverityFormattingOnly},
+		{Difference{JSONValueV1: []byte(`null`), JSONValueV2: []byte(`null`)}, SeveritySemantic},
+		{Difference{JSONValueV1: []byte(`"\u003c"`), JSONValueV2: []byte(`"<"`), ErrorV2: errFail}, SeveritySemantic},
+		{Difference{GoValueV1: 1, GoValueV2: 2}, SeveritySemantic},
+		{Difference{ErrorV1: errFail, ErrorV2: errors.New("failure")}, SeverityErrorOnly},
+	}
+	for _, tt := range tests {
+		if got := severityOf(tt.d); got != tt.want {
+			t.Errorf("severityOf(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+
+	for s := range Severity(numSeverities) {
+		b, err := jsonv2.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var got Severity
+		if err := jsonv2.Unmarshal(b, &got); err != nil || got != s {
+			t.Errorf("Unmarshal(%s) = (%v, %v), want %v", b, got, err, s)
+		}
+	}
+}
+
+func TestReportSeverities(t *testing.T) {
+	var got []Difference
+	codec := &Codec{
+		ReportDifference: func(d Difference) { got = append(got, d) },
+		ReportSeverities: []Severity{SeveritySemantic, SeverityErrorOnly},
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal("<>")       // formatting only
+	codec.Marshal([]int(nil)) // semantic
+	if len(got) != 1 || got[0].Severity != SeveritySemantic {
+		t.Fatalf("reported differences = %v, want one semantic difference", got)
+	}
+	if n := codec.NumDifferencesSeverityFiltered.Value(); n != 1 {
+		t.Errorf("NumDifferencesSeverityFiltered = %d, want 1", n)
+	}
+	for _, name := range []string{"Semantic", "FormattingOnly"} {
+		if n := codec.SeverityHistogram.Get(name); n == nil || n.String() != "1" {
+			t.Errorf("SeverityHistogram[%q] = %v, want 1", name, n)
+		}
+	}
+}
+
+func TestSeverityHistogramUnsampled(t *testing.T) {
+	// Severities are counted before sampling and suppression.
+	var got []Difference
+	codec := &Codec{
+		ReportDifference:        func(d Difference) { got = append(got, d) },
+		ReportSampleRate:        1e-9,
+		MaxReportsPerDifference: 1,
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	for range 3 {
+		codec.Marshal("<>")
+	}
+	if n := codec.SeverityHistogram.Get("FormattingOnly"); n == nil || n.String() != "3" {
+		t.Errorf("SeverityHistogram[FormattingOnly] = %v, want 3", n)
+	}
+	if n := int64(len(got)) + codec.NumDifferencesUnsampled.Value(); n != 3 {
+		t.Errorf("reported and unsampled differences = %d, want 3", n)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSink records differences by appending them as JSON Lines
+// (encoded by [Difference.MarshalJSONLossless]) to files within a directory,
+// which can later be read back with [ReadCorpus].
+// Its [FileSink.Report] method is intended for use with
+// [Codec.ReportDifference] (or [Codec.ReportEmulationRegression]),
+// or the sink may be added with [Codec.AddSink] to be closed by [Codec.Close].
+//
+// Each file is named after its creation time and a sequence number
+// (e.g., "diffs-20250102T150405.123456789Z-1.jsonl")
+// such that files are lexically sorted by creation time.
+// The current file is rotated when it grows beyond MaxSize
+// or becomes older than MaxAge. Old files are never deleted.
+//
+// The exported fields must be set before the first report.
+// The methods are safe for concurrent use.
+//
+// For example:
+//
+//	sink := &jsonsplit.FileSink{Dir: "/var/log/jsonsplit", MaxSize: 64 << 20}
+//	defer sink.Close()
+//	jsonsplit.GlobalCodec.ReportDifference = sink.Report
+type FileSink struct {
+	// Dir is the directory in which files are created.
+	// It is created if it does not exist.
+	Dir string
+
+	// MaxSize is the size in bytes after which the current file is rotated.
+	// A single difference larger than MaxSize is still written to its own file.
+	// If zero or negative, files are not rotated by size.
+	MaxSize int64
+
+	// MaxAge is the duration after which the current file is rotated.
+	// If zero or negative, files are not rotated by age.
+	MaxAge time.Duration
+
+	// SyncInterval controls how often written differences are
+	// committed to stable storage (i.e., by calling [os.File.Sync]).
+	// If zero, a file is only synced when it is rotated or closed.
+	// If negative, every difference is synced immediately.
+	// Otherwise, a file is synced upon a write if at least
+	// SyncInterval has elapsed since the last sync.
+	SyncInterval time.Duration
+
+	mu       sync.Mutex
+	now      func() time.Time // for testing; if nil, uses time.Now
+	file     *os.File
+	seq      int
+	size     int64
+	created  time.Time
+	lastSync time.Time
+	err      error
+}
+
+// Report appends d to the current file, rotating it if necessary.
+// Any error is retained and reported by [FileSink.Err] and [FileSink.Close],
+// and the difference is dropped.
+func (s *FileSink) Report(d Difference) {
+	b, err := d.MarshalJSONLossless()
+	if err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.err = err
+		return
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+	if s.file != nil && ((s.MaxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.MaxSize) ||
+		(s.MaxAge > 0 && now.Sub(s.created) >= s.MaxAge)) {
+		if err := s.closeFile(); err != nil {
+			s.err = err
+		}
+	}
+	if s.file == nil {
+		if err := os.MkdirAll(s.Dir, 0o777); err != nil {
+			s.err = err
+			return
+		}
+		s.seq++
+		name := fmt.Sprintf("diffs-%s-%d.jsonl", now.UTC().Format("20060102T150405.000000000Z"), s.seq)
+		f, err := os.OpenFile(filepath.Join(s.Dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o666)
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.file, s.size, s.created, s.lastSync = f, 0, now, now
+	}
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	if err != nil {
+		s.err = err
+		return
+	}
+	if s.SyncInterval < 0 || (s.SyncInterval > 0 && now.Sub(s.lastSync) >= s.SyncInterval) {
+		if err := s.file.Sync(); err != nil {
+			s.err = err
+		}
+		s.lastSync = now
+	}
+}
+
+// Err reports the most recent error encountered while recording differences.
+func (s *FileSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close syncs and closes the current file and reports
+// the most recent error encountered while recording differences.
+// A subsequent report creates a new file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.err, s.closeFile())
+}
+
+// closeFile syncs and closes the current file (if any).
+func (s *FileSink) closeFile() error {
+	if s.file == nil {
+		return nil
+	}
+	errSync := s.file.Sync()
+	errClose := s.file.Close()
+	s.file = nil
+	return errors.Join(errSync, errClose)
+}
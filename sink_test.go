@@ -0,0 +1,73 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileSink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "diffs")
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	sink := &FileSink{
+		Dir:          dir,
+		MaxSize:      300,
+		MaxAge:       time.Hour,
+		SyncInterval: -1,
+		now:          func() time.Time { return now },
+	}
+	codec := &Codec{ReportDifference: sink.Report}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil))
+	codec.Marshal([]int(nil)) // rotated by size
+	now = now.Add(time.Minute)
+	codec.Marshal(map[string]int(nil)) // rotated by size
+	now = now.Add(2 * time.Hour)
+	codec.Marshal([]int(nil)) // rotated by age
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	wantNames := []string{
+		"diffs-20250102T150405.000000000Z-1.jsonl",
+		"diffs-20250102T150405.000000000Z-2.jsonl",
+		"diffs-20250102T150505.000000000Z-3.jsonl",
+		"diffs-20250102T170505.000000000Z-4.jsonl",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("file names = %q, want %q", names, wantNames)
+	}
+
+	ds, err := ReadCorpus(dir)
+	if err != nil {
+		t.Fatalf("ReadCorpus error: %v", err)
+	}
+	var gotJSON []string
+	for _, d := range ds {
+		gotJSON = append(gotJSON, string(d.JSONValueV1))
+	}
+	if want := []string{"null", "null", "null", "null"}; !reflect.DeepEqual(gotJSON, want) {
+		t.Errorf("recorded JSONValueV1 = %q, want %q", gotJSON, want)
+	}
+
+	// Errors are retained and reported.
+	bad := &FileSink{Dir: filepath.Join(dir, wantNames[0], "sub")}
+	bad.Report(Difference{Func: "Marshal"})
+	if bad.Err() == nil || bad.Close() == nil {
+		t.Errorf("Err and Close unexpectedly succeeded")
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of all metrics in [CodecMetrics].
+// Metrics are keyed by the snake case form of each field in [CodecMetrics]
+// (see [CodecMetrics.ExpVar]).
+//
+// A snapshot can be losslessly serialized as JSON using either v1 or v2,
+// so that snapshots from many processes can be sent to a collector
+// and combined into a single view using [Snapshot.Merge].
+type Snapshot struct {
+	// Time is when the snapshot was taken.
+	Time time.Time
+
+	// Counters contains the value of every integer metric.
+	Counters map[string]int64
+
+	// Histograms contains the non-zero buckets of every histogram metric.
+	// For [SizeHistogram] metrics, buckets are named as described in
+	// [SizeHistogram.MarshalJSON].
+	Histograms map[string]map[string]int64
+
+	// Delta is the change in metrics since the previous snapshot.
+	// It is only populated for snapshots provided by [Codec.StartPusher].
+	Delta *Snapshot `json:",omitzero"`
+}
+
+// Snapshot returns a point-in-time copy of all metrics.
+// Since each metric is loaded independently,
+// the snapshot is not an atomic view across all metrics.
+func (c *CodecMetrics) Snapshot() Snapshot {
+	s := Snapshot{
+		Time:       time.Now(),
+		Counters:   make(map[string]int64),
+		Histograms: make(map[string]map[string]int64),
+	}
+	for name, value := range c.All() {
+		switch value := value.(type) {
+		case *expvar.Int:
+			s.Counters[name] = value.Value()
+		case *SizeHistogram:
+			h := make(map[string]int64)
+			for key, n := range value.all() {
+				h[key] = n
+			}
+			s.Histograms[name] = h
+		case *expvar.Map:
+			h := make(map[string]int64)
+			value.Do(func(kv expvar.KeyValue) {
+				if n, ok := kv.Value.(*expvar.Int); ok {
+					h[kv.Key] = n.Value()
+				}
+			})
+			s.Histograms[name] = h
+		}
+	}
+	return s
+}
+
+// Sub returns the change in metrics from prev to s.
+// Histogram buckets without any change are omitted.
+// The [Snapshot.Delta] field is not populated.
+func (s Snapshot) Sub(prev Snapshot) Snapshot {
+	d := Snapshot{
+		Time:       s.Time,
+		Counters:   make(map[string]int64, len(s.Counters)),
+		Histograms: make(map[string]map[string]int64, len(s.Histograms)),
+	}
+	for name, n := range s.Counters {
+		d.Counters[name] = n - prev.Counters[name]
+	}
+	for name, h := range s.Histograms {
+		dh := make(map[string]int64)
+		for key, n := range h {
+			if n -= prev.Histograms[name][key]; n != 0 {
+				dh[key] = n
+			}
+		}
+		d.Histograms[name] = dh
+	}
+	return d
+}
+
+// Merge returns the combination of metrics in s and other,
+// where counters and histogram buckets are summed together.
+// The time of the result is the later of the two.
+// The [Snapshot.Delta] fields are merged only if both are present.
+func (s Snapshot) Merge(other Snapshot) Snapshot {
+	m := Snapshot{
+		Time:       s.Time,
+		Counters:   make(map[string]int64, len(s.Counters)),
+		Histograms: make(map[string]map[string]int64, len(s.Histograms)),
+	}
+	if other.Time.After(m.Time) {
+		m.Time = other.Time
+	}
+	for _, s := range []Snapshot{s, other} {
+		for name, n := range s.Counters {
+			m.Counters[name] += n
+		}
+		for name, h := range s.Histograms {
+			mh := m.Histograms[name]
+			if mh == nil {
+				mh = make(map[string]int64, len(h))
+				m.Histograms[name] = mh
+			}
+			for key, n := range h {
+				mh[key] += n
+			}
+		}
+	}
+	if s.Delta != nil && other.Delta != nil {
+		delta := s.Delta.Merge(*other.Delta)
+		m.Delta = &delta
+	}
+	return m
+}
+
+// StartPusher starts a background goroutine that calls push
+// with a snapshot of the metrics every interval.
+// Each snapshot has [Snapshot.Delta] populated with the change in metrics
+// since the previous push (or since the pusher started for the first push).
+// Calls to push are never concurrent with each other.
+//
+// [Codec.Flush] causes an immediate push and [Codec.Close]
+// performs a final push before stopping the pusher.
+//
+// The returned stop function stops the pusher (without a final push)
+// and waits for any in-flight call to push to complete.
+func (c *Codec) StartPusher(interval time.Duration, push func(Snapshot)) (stop func()) {
+	done := make(chan struct{})
+	flushes := make(chan chan struct{})
+	prev := c.CodecMetrics.Snapshot()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		pushNow := func() {
+			curr := c.CodecMetrics.Snapshot()
+			delta := curr.Sub(prev)
+			curr.Delta = &delta
+			push(curr)
+			prev = curr
+		}
+		for {
+			select {
+			case <-t.C:
+				pushNow()
+			case flushed := <-flushes:
+				pushNow()
+				close(flushed)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stopPusher := func() {
+		once.Do(func() { close(done) })
+		wg.Wait()
+	}
+	h := &flushHook{
+		flush: func() error {
+			flushed := make(chan struct{})
+			select {
+			case flushes <- flushed:
+				<-flushed
+			case <-done:
+			}
+			return nil
+		},
+		close: func() error {
+			stopPusher()
+			return nil
+		},
+	}
+	c.addFlushHook(h)
+	return func() {
+		c.removeFlushHook(h)
+		stopPusher()
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"testing"
+	"time"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshot(t *testing.T) {
+	var m CodecMetrics
+	m.NumMarshalTotal.Add(2)
+	m.MarshalSizeHistogram.insertSize(5)
+	m.MarshalCallerHistogram.Add("pkg.Func+1", 1)
+	prev := m.Snapshot()
+
+	m.NumMarshalTotal.Add(3)
+	m.MarshalSizeHistogram.insertSize(5)
+	m.MarshalSizeHistogram.insertSize(100)
+	m.MarshalCallerHistogram.Add("pkg.Func+2", 4)
+	curr := m.Snapshot()
+
+	if got := curr.Counters["num_marshal_total"]; got != 5 {
+		t.Errorf("Counters[num_marshal_total] = %d, want 5", got)
+	}
+	got := curr.Sub(prev)
+	if n := got.Counters["num_marshal_total"]; n != 3 {
+		t.Errorf("Sub.Counters[num_marshal_total] = %d, want 3", n)
+	}
+	for name, want := range map[string]map[string]int64{
+		"marshal_size_histogram":   {"<8B": 1, "<128B": 1},
+		"marshal_caller_histogram": {"pkg.Func+2": 4},
+		"unmarshal_size_histogram": {},
+	} {
+		if d := cmp.Diff(got.Histograms[name], want); d != "" {
+			t.Errorf("Sub.Histograms[%s] mismatch (-got +want):\n%s", name, d)
+		}
+	}
+}
+
+func TestSnapshotMerge(t *testing.T) {
+	var m1, m2 CodecMetrics
+	m1.NumMarshalTotal.Add(2)
+	m1.MarshalCallerHistogram.Add("pkg.Func+1", 1)
+	m2.NumMarshalTotal.Add(3)
+	m2.MarshalCallerHistogram.Add("pkg.Func+1", 2)
+	m2.MarshalCallerHistogram.Add("pkg.Func+2", 4)
+	s1, s2 := m1.Snapshot(), m2.Snapshot()
+
+	// Simulate sending the snapshot from another process.
+	b, err := jsonv2.Marshal(s2)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var s2Copy Snapshot
+	if err := jsonv2.Unmarshal(b, &s2Copy); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if d := cmp.Diff(s2Copy, s2); d != "" {
+		t.Fatalf("JSON round-trip mismatch (-got +want):\n%s", d)
+	}
+
+	got := s1.Merge(s2Copy)
+	if n := got.Counters["num_marshal_total"]; n != 5 {
+		t.Errorf("Counters[num_marshal_total] = %d, want 5", n)
+	}
+	want := map[string]int64{"pkg.Func+1": 3, "pkg.Func+2": 4}
+	if d := cmp.Diff(got.Histograms["marshal_caller_histogram"], want); d != "" {
+		t.Errorf("Histograms[marshal_caller_histogram] mismatch (-got +want):\n%s", d)
+	}
+	if !got.Time.Equal(s2.Time) {
+		t.Errorf("Time = %v, want %v", got.Time, s2.Time)
+	}
+}
+
+func TestStartPusher(t *testing.T) {
+	var c Codec
+	pushes := make(chan Snapshot)
+	stop := c.StartPusher(time.Millisecond, func(s Snapshot) {
+		select {
+		case pushes <- s:
+		case <-time.After(time.Second):
+		}
+	})
+	defer stop()
+
+	c.Marshal(nil)
+	var total int64
+	for total < 1 {
+		s := <-pushes
+		if s.Delta == nil {
+			t.Fatal("Snapshot.Delta is nil")
+		}
+		total += s.Delta.Counters["num_marshal_total"]
+	}
+	if total != 1 {
+		t.Errorf("total num_marshal_total delta = %d, want 1", total)
+	}
+}
+
+func TestCodecClose(t *testing.T) {
+	var c Codec
+	var pushed, summarized []Snapshot
+	c.ReportSummary = func(s Snapshot) { summarized = append(summarized, s) }
+	c.StartPusher(time.Hour, func(s Snapshot) { pushed = append(pushed, s) })
+
+	c.Marshal(nil)
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	if len(pushed) != 1 || pushed[0].Delta.Counters["num_marshal_total"] != 1 {
+		t.Fatalf("after Flush: got %d pushes, want 1 push with a delta of 1", len(pushed))
+	}
+
+	c.Marshal(nil)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if len(pushed) != 2 || pushed[1].Delta.Counters["num_marshal_total"] != 1 {
+		t.Fatalf("after Close: got %d pushes, want 2 pushes with a delta of 1", len(pushed))
+	}
+	if len(summarized) != 1 || summarized[0].Counters["num_marshal_total"] != 2 {
+		t.Fatalf("after Close: got %d summaries, want 1 summary with a total of 2", len(summarized))
+	}
+
+	// Pushers are no longer running after Close.
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+	if len(pushed) != 2 {
+		t.Fatalf("after Close and Flush: got %d pushes, want 2", len(pushed))
+	}
+}
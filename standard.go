@@ -0,0 +1,130 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"reflect"
+
+	jsonv1std "encoding/json"
+
+	jsonv2 "github.com/go-json-experiment/json"    // TODO: Use "encoding/json/v2"
+	jsonv1 "github.com/go-json-experiment/json/v1" // TODO: Use "encoding/json"
+)
+
+// marshalStandardV1 implements [Codec.Marshal] for [CallBothV1StdAndV1Emulated],
+// where t is the Go type of v and marshalV1 is the v1 call to use
+// if [jsonv1std] cannot be called.
+func (c *Codec) marshalStandardV1(ctx context.Context, site callSite, t reflect.Type, marshalV1 func(any, ...jsonv2.Options) ([]byte, error), v any, o ...jsonv2.Options) ([]byte, error) {
+	c.add(&c.NumMarshalReturnV1, 1)
+	if len(o) > 0 {
+		c.add(&c.NumMarshalOnlyCallV1, 1)
+		return marshalV1(v, c.v1Options(o)...)
+	}
+	bufStd, errStd := jsonv1std.Marshal(v)
+	bufEmu, errEmu := jsonv1.Marshal(v)
+	if !(c.jsonEqual(bufStd, bufEmu) && c.errorsEqual(errStd, errEmu)) {
+		c.add(&c.NumMarshalStandardV1Diffs, 1)
+		c.addKey(&c.CallModeCounters, marshalCallModeKeys[CallBothV1StdAndV1Emulated].diffs, 1)
+
+		d := Difference{
+			Caller:      c.labelOrCaller(site),
+			Labels:      contextLabels(ctx),
+			Func:        "Marshal",
+			Check:       "StandardV1",
+			GoType:      t,
+			GoValue:     v,
+			JSONValueV1: bufStd,
+			JSONValueV2: bufEmu,
+			ErrorV1:     errStd,
+			ErrorV2:     errEmu,
+			OptionsV1:   jsonv1.DefaultOptionsV1(),
+			OptionsV2:   jsonv1.DefaultOptionsV1(),
+			StandardV1:  true,
+		}
+		c.classifyDifference(&d)
+		if c.sampleReport() && (c.AutoDetectOptions || c.reportsDifferences()) {
+			c.analyzeDifference(d, func(d Difference) {
+				// Detection reports an emulation regression
+				// unless the difference is merely in formatting.
+				v, bufStd, errStd := d.GoValue, d.JSONValueV1, d.ErrorV1
+				emulated := true
+				if c.AutoDetectOptions {
+					var resolved, detected bool
+					d.Options, resolved, emulated, detected = c.detectOptions("Marshal/StandardV1", t, nil, func(o ...jsonv2.Options) bool {
+						bufEmu, errEmu := jsonv2.Marshal(v, withV1Defaults(o)...)
+						return c.jsonEqual(bufStd, bufEmu) && c.errorsEqual(errStd, errEmu)
+					})
+					d.OptionsIncomplete = detected && emulated && !resolved
+				}
+				c.reportAnalyzedDifference(d, emulated)
+			})
+		}
+	}
+	return bufStd, errStd
+}
+
+// unmarshalStandardV1 implements [Codec.Unmarshal] for [CallBothV1StdAndV1Emulated],
+// where t is the Go type of v.
+func (c *Codec) unmarshalStandardV1(ctx context.Context, site callSite, ts *typeState, t reflect.Type, b []byte, v any, o ...jsonv2.Options) error {
+	c.add(&c.NumUnmarshalReturnV1, 1)
+	if len(o) > 0 {
+		c.add(&c.NumUnmarshalOnlyCallV1, 1)
+		return jsonv1Unmarshal(b, v, c.v1Options(o)...)
+	}
+	valOrig := c.cloneGoValueFor(ts, v)
+	if valOrig == nil {
+		c.add(&c.NumUnmarshalCallBothSkipped, 1)
+		c.add(&c.NumUnmarshalOnlyCallV1, 1)
+		return jsonv1std.Unmarshal(b, v)
+	}
+	valStd, valEmu := v, c.cloneGoValueFor(ts, valOrig)
+	errStd := jsonv1std.Unmarshal(b, valStd)
+	errEmu := jsonv1.Unmarshal(b, valEmu)
+	if !c.unmarshalEqual(valStd, valEmu, errStd, errEmu, len(b)) {
+		c.add(&c.NumUnmarshalStandardV1Diffs, 1)
+		c.addKey(&c.CallModeCounters, unmarshalCallModeKeys[CallBothV1StdAndV1Emulated].diffs, 1)
+		d := Difference{
+			Caller:      c.labelOrCaller(site),
+			Labels:      contextLabels(ctx),
+			Func:        "Unmarshal",
+			Check:       "StandardV1",
+			GoType:      t,
+			JSONValue:   b,
+			GoValueV1:   valStd,
+			GoValueV2:   valEmu,
+			GoValuePath: goValuePath(valStd, valEmu),
+			ErrorV1:     errStd,
+			ErrorV2:     errEmu,
+			OptionsV1:   jsonv1.DefaultOptionsV1(),
+			OptionsV2:   jsonv1.DefaultOptionsV1(),
+			StandardV1:  true,
+		}
+		c.classifyDifference(&d)
+		if c.sampleReport() && (c.AutoDetectOptions || c.reportsDifferences()) {
+			c.analyzeDifference(d, func(d Difference) {
+				b, valStd, errStd := d.JSONValue, d.GoValueV1, d.ErrorV1
+				emulated := true
+				if c.AutoDetectOptions {
+					var resolved, detected bool
+					d.Options, resolved, emulated, detected = c.detectOptions("Unmarshal/StandardV1", t, nil, func(o ...jsonv2.Options) bool {
+						valEmu := c.cloneGoValueFor(ts, valOrig)
+						errEmu := jsonv2.Unmarshal(b, valEmu, withV1Defaults(o)...)
+						return c.unmarshalEqual(valStd, valEmu, errStd, errEmu, len(b))
+					})
+					d.OptionsIncomplete = detected && emulated && !resolved
+				}
+				c.reportAnalyzedDifference(d, emulated)
+			})
+		}
+	}
+	return errStd
+}
+
+// withV1Defaults returns the options o on top of [jsonv1.DefaultOptionsV1],
+// such that probing v2 with options starts from the emulation of v1.
+func withV1Defaults(o []jsonv2.Options) []jsonv2.Options {
+	return append([]jsonv2.Options{jsonv1.DefaultOptionsV1()}, o...)
+}
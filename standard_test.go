@@ -0,0 +1,119 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+)
+
+func TestCallBothV1StdAndV1Emulated(t *testing.T) {
+	var gotDiffs, gotRegressions []Difference
+	codec := Codec{
+		ReportDifference:          func(d Difference) { gotDiffs = append(gotDiffs, d) },
+		ReportEmulationRegression: func(d Difference) { gotRegressions = append(gotRegressions, d) },
+	}
+	codec.SetMarshalCallMode(CallBothV1StdAndV1Emulated)
+	codec.SetUnmarshalCallMode(CallBothV1StdAndV1Emulated)
+
+	// Differences between v1 and v2 are irrelevant.
+	if b, err := codec.Marshal([]int(nil)); err != nil || string(b) != "null" {
+		t.Errorf("Marshal = (%s, %v), want (null, nil)", b, err)
+	}
+	var v reverifyUser
+	if err := codec.Unmarshal([]byte(`{"NAME":"x"}`), &v); err != nil || v.Name != "x" {
+		t.Errorf("Unmarshal = (%+v, %v), want ({Name:x}, nil)", v, err)
+	}
+	if len(gotDiffs) != 0 {
+		t.Fatalf("got differences %v, want none", gotDiffs)
+	}
+
+	// Treat every output as different to simulate a bug in the emulation.
+	codec.AutoDetectOptions = true
+	codec.EqualJSONValues = func(x, y jsontext.Value) bool { return false }
+	codec.Marshal([]int(nil))
+	codec.Marshal([]int(nil), jsonv2.Deterministic(true)) // only calls v1
+	if len(gotDiffs) != 1 || len(gotRegressions) != 1 {
+		t.Fatalf("got %d differences and %d regressions, want 1 and 1", len(gotDiffs), len(gotRegressions))
+	}
+	want := Difference{
+		Caller: gotDiffs[0].Caller, Func: "Marshal", Check: "StandardV1",
+		GoType: reflect.TypeFor[[]int](), GoValue: []int(nil),
+		JSONValueV1: []byte("null"), JSONValueV2: []byte("null"),
+		ID: gotDiffs[0].ID, OptionsV1: jsonv1.DefaultOptionsV1(), OptionsV2: jsonv1.DefaultOptionsV1(), StandardV1: true,
+		DetectionFailed: true, DetectionFailedReason: emulationRegressionReason,
+	}
+	if !reflect.DeepEqual(gotDiffs[0], want) {
+		t.Errorf("got difference:\n\t%v\nwant:\n\t%v", gotDiffs[0], want)
+	}
+	if got := gotRegressions[0].Check; got != "EmulationRegression" {
+		t.Errorf("regression Check = %q, want EmulationRegression", got)
+	}
+
+	for _, tt := range []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"NumMarshalStandardV1Diffs", codec.NumMarshalStandardV1Diffs.Value(), 1},
+		{"NumMarshalDiffs", codec.NumMarshalDiffs.Value(), 0},
+		{"NumMarshalOnlyCallV1", codec.NumMarshalOnlyCallV1.Value(), 1},
+		{"NumMarshalReturnV1", codec.NumMarshalReturnV1.Value(), 3},
+		{"NumUnmarshalReturnV1", codec.NumUnmarshalReturnV1.Value(), 1},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// nilFormatDetector marshals as "v1" if either nil slices or nil maps
+// are formatted as null (as for v1), such that neither option
+// alone is significant when detecting options.
+type nilFormatDetector struct{}
+
+func (nilFormatDetector) MarshalJSON() ([]byte, error) { return []byte(`"v1"`), nil }
+
+func (nilFormatDetector) MarshalJSONTo(enc *jsontext.Encoder) error {
+	nilSlices, _ := jsonv2.GetOption(enc.Options(), jsonv2.FormatNilSliceAsNull)
+	nilMaps, _ := jsonv2.GetOption(enc.Options(), jsonv2.FormatNilMapAsNull)
+	if nilSlices || nilMaps {
+		return enc.WriteToken(jsontext.String("v1"))
+	}
+	return enc.WriteToken(jsontext.String("v2"))
+}
+
+func TestStandardV1DetectionBaseline(t *testing.T) {
+	var got []Difference
+	var calls int
+	codec := Codec{
+		AutoDetectOptions: true,
+		ReportDifference:  func(d Difference) { got = append(got, d) },
+		// Treat the first comparison as different to simulate a difference
+		// between the standard library and the emulation of v1.
+		EqualJSONValues: func(x, y jsontext.Value) bool {
+			calls++
+			return calls > 1 && bytes.Equal(x, y)
+		},
+	}
+	codec.SetMarshalCallMode(CallBothV1StdAndV1Emulated)
+	codec.Marshal(nilFormatDetector{})
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	// Probing starts from the v1 options, so no options are needed.
+	if names := slices.Collect(got[0].OptionNames()); len(names) > 0 || got[0].OptionsIncomplete || got[0].DetectionFailed {
+		t.Errorf("got difference %v, want a difference resolved without options", got[0])
+	}
+	if n := codec.NumAutoDetectCombinations.Value(); n != 0 {
+		t.Errorf("NumAutoDetectCombinations = %d, want 0", n)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsD emits [CodecMetrics] to a StatsD or DogStatsD agent.
+//
+// Every metric is emitted as a counter of the change since the previous flush,
+// except for the ExecTime metrics, which are emitted as timers
+// reporting the total number of milliseconds spent since the previous flush.
+// Histogram buckets are emitted as separate counters, either as a name suffix
+// or as a "key" tag if [StatsD.DogStatsD] is enabled.
+//
+// The exported fields must be set before concurrent use.
+type StatsD struct {
+	// Metrics is the set of metrics to emit.
+	Metrics *CodecMetrics
+
+	// Writer is where StatsD lines are written to.
+	// Typically, this is a UDP connection to a local agent
+	// (e.g., the result of net.Dial("udp", "localhost:8125")).
+	// Each call to Write contains one or more newline-delimited lines
+	// and is no larger than [StatsD.MaxPacketSize] unless
+	// a single line is larger than that.
+	Writer io.Writer
+
+	// Prefix is prepended to every metric name (e.g., "jsonsplit.").
+	Prefix string
+
+	// DogStatsD specifies whether to use the DogStatsD extension of tags
+	// to represent keys in histograms, rather than appending them to the name.
+	DogStatsD bool
+
+	// MaxPacketSize is the maximum number of bytes written per call to Write.
+	// If zero, it uses 1432, which is a safe size for UDP over Ethernet.
+	MaxPacketSize int
+
+	mu   sync.Mutex
+	last map[string]int64 // last observed values keyed by metric line prefix
+	buf  []byte
+}
+
+// Flush writes the change in all metrics since the last flush.
+// Metrics that are unchanged are not written.
+func (s *StatsD) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		s.last = make(map[string]int64)
+	}
+	maxSize := s.MaxPacketSize
+	if maxSize <= 0 {
+		maxSize = 1432
+	}
+
+	var firstErr error
+	s.buf = s.buf[:0]
+	emit := func(name, key string, value int64, isTimer bool) {
+		id := name + "\x00" + key
+		delta := value - s.last[id]
+		s.last[id] = value
+		if delta == 0 {
+			return
+		}
+
+		n := len(s.buf)
+		if n > 0 {
+			s.buf = append(s.buf, '\n')
+		}
+		s.buf = append(s.buf, s.Prefix...)
+		s.buf = append(s.buf, name...)
+		if key != "" && !s.DogStatsD {
+			s.buf = append(s.buf, '.')
+			s.buf = append(s.buf, sanitizeStatsD(key, true)...)
+		}
+		s.buf = append(s.buf, ':')
+		if isTimer {
+			s.buf = strconv.AppendFloat(s.buf, float64(delta)/float64(time.Millisecond), 'f', -1, 64)
+			s.buf = append(s.buf, "|ms"...)
+		} else {
+			s.buf = strconv.AppendInt(s.buf, delta, 10)
+			s.buf = append(s.buf, "|c"...)
+		}
+		if key != "" && s.DogStatsD {
+			s.buf = append(s.buf, "|#key:"...)
+			s.buf = append(s.buf, sanitizeStatsD(key, false)...)
+		}
+
+		// Flush the previous lines if this line exceeds the packet size.
+		if n > 0 && len(s.buf) > maxSize {
+			if _, err := s.Writer.Write(s.buf[:n]); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			s.buf = append(s.buf[:0], s.buf[n+len("\n"):]...)
+		}
+	}
+
+	for name, value := range s.Metrics.All() {
+		switch value := value.(type) {
+		case *expvar.Int:
+			emit(name, "", value.Value(), strings.HasPrefix(name, "exec_time_"))
+		case *SizeHistogram:
+			for key, n := range value.all() {
+				emit(name, key, n, false)
+			}
+		case *expvar.Map:
+			value.Do(func(kv expvar.KeyValue) {
+				if n, ok := kv.Value.(*expvar.Int); ok {
+					emit(name, kv.Key, n.Value(), false)
+				}
+			})
+		}
+	}
+	if len(s.buf) > 0 {
+		if _, err := s.Writer.Write(s.buf); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Run calls [StatsD.Flush] every interval until the context is canceled,
+// upon which it performs a final flush and returns the context error.
+// Errors from flushing are ignored since StatsD delivery is best-effort.
+func (s *StatsD) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			s.Flush()
+		case <-ctx.Done():
+			s.Flush()
+			return ctx.Err()
+		}
+	}
+}
+
+// sanitizeStatsD replaces characters that are reserved by the StatsD protocol.
+// If isName, then it also replaces characters that delimit name segments.
+func sanitizeStatsD(s string, isName bool) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', '\n':
+			if r == ':' && !isName {
+				return r // DogStatsD tag values may contain colons
+			}
+			return '_'
+		case '.', ' ', '/':
+			if isName {
+				return '_'
+			}
+		}
+		return r
+	}, s)
+}
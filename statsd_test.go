@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStatsD(t *testing.T) {
+	var m CodecMetrics
+	var packets []string
+	s := StatsD{
+		Metrics: &m,
+		Writer:  writerFunc(func(b []byte) (int, error) { packets = append(packets, string(b)); return len(b), nil }),
+		Prefix:  "jsonsplit.",
+	}
+	flush := func() []string {
+		packets = nil
+		if err := s.Flush(); err != nil {
+			t.Fatalf("Flush error: %v", err)
+		}
+		var lines []string
+		for _, p := range packets {
+			lines = append(lines, strings.Split(p, "\n")...)
+		}
+		return lines
+	}
+
+	m.NumMarshalTotal.Add(3)
+	m.ExecTimeMarshalV1Nanos.Add(int64(1500 * time.Microsecond))
+	m.MarshalSizeHistogram.insertSize(5)
+	m.MarshalCallerHistogram.Add("pkg.Func+12", 2)
+	got := flush()
+	want := []string{
+		"jsonsplit.num_marshal_total:3|c",
+		"jsonsplit.exec_time_marshal_v1_nanos:1.5|ms",
+		"jsonsplit.marshal_size_histogram.<8B:1|c",
+		"jsonsplit.marshal_caller_histogram.pkg_Func+12:2|c",
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("Flush mismatch (-got +want):\n%s", d)
+	}
+
+	// Only deltas are emitted on subsequent flushes.
+	m.NumMarshalTotal.Add(1)
+	m.MarshalCallerHistogram.Add("pkg.Func+12", 1)
+	s.DogStatsD = true
+	got = flush()
+	want = []string{
+		"jsonsplit.num_marshal_total:1|c",
+		"jsonsplit.marshal_caller_histogram:1|c|#key:pkg.Func+12",
+	}
+	if d := cmp.Diff(got, want); d != "" {
+		t.Errorf("Flush mismatch (-got +want):\n%s", d)
+	}
+
+	// Packets are split according to MaxPacketSize.
+	s.MaxPacketSize = 40
+	m.NumMarshalTotal.Add(1)
+	m.NumMarshalErrors.Add(1)
+	flush()
+	if len(packets) != 2 {
+		t.Errorf("len(packets) = %d, want 2", len(packets))
+	}
+	for _, p := range packets {
+		if strings.Contains(p, "\n") {
+			t.Errorf("packet %q unexpectedly contains multiple lines", p)
+		}
+	}
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) { return f(b) }
@@ -0,0 +1,69 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"reflect"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// prefixComparer is an [io.Writer] that verifies that
+// all written bytes are a prefix of the expected output.
+// Upon the first divergence, it copies the matched prefix
+// and buffers all subsequently written bytes,
+// such that the complete output is available without writing it again.
+type prefixComparer struct {
+	want     []byte
+	n        int    // number of bytes already matched
+	diverged []byte // the complete output if non-nil
+}
+
+func (w *prefixComparer) Write(b []byte) (int, error) {
+	if w.diverged == nil {
+		if bytes.HasPrefix(w.want[w.n:], b) {
+			w.n += len(b)
+			return len(b), nil
+		}
+		w.diverged = append(make([]byte, 0, w.n+len(b)), w.want[:w.n]...)
+	}
+	w.diverged = append(w.diverged, b...)
+	return len(b), nil
+}
+
+// output returns the complete written output,
+// which is the expected output itself if they are identical.
+func (w *prefixComparer) output() []byte {
+	switch {
+	case w.diverged != nil:
+		return w.diverged
+	case w.n == len(w.want):
+		return w.want
+	default:
+		return bytes.Clone(w.want[:w.n])
+	}
+}
+
+// jsonv2MarshalPC identifies [jsonv2.Marshal] as the v2 marshal function.
+var jsonv2MarshalPC = reflect.ValueOf(jsonv2.Marshal).Pointer()
+
+// streamMarshalV2 calls marshalV2 (e.g., [jsonv2.Marshal]),
+// but if [Codec.StreamMarshalComparison] is applicable,
+// the output is streamed through a comparison against
+// the v1 output buf1 and buf1 itself is returned if they are identical.
+// Otherwise, the v2 output is buffered from the first divergence onwards
+// such that v2 is only called once.
+// Streaming only replaces [jsonv2.Marshal], such that
+// any other marshalV2 (e.g., of [Codec.MarshalAppend]) is always called.
+func (c *Codec) streamMarshalV2(buf1 []byte, marshalV2 func(any, ...jsonv2.Options) ([]byte, error), v any, o ...jsonv2.Options) ([]byte, error) {
+	if c.StreamMarshalComparison && buf1 != nil && c.EqualJSONValues == nil && len(c.Transformers) == 0 &&
+		reflect.ValueOf(marshalV2).Pointer() == jsonv2MarshalPC {
+		w := prefixComparer{want: buf1}
+		err := jsonv2.MarshalWrite(&w, v, o...)
+		return w.output(), err
+	}
+	return marshalV2(v, o...)
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import "testing"
+
+func TestPrefixComparer(t *testing.T) {
+	w := prefixComparer{want: []byte(`{"k":1}`)}
+	w.Write([]byte(`{"k"`))
+	w.Write([]byte(`:1}`))
+	if got := w.output(); string(got) != `{"k":1}` || &got[0] != &w.want[0] {
+		t.Errorf("output = %s, want the expected output itself", got)
+	}
+
+	w = prefixComparer{want: []byte(`{"k":1}`)}
+	w.Write([]byte(`{"k"`))
+	if got := w.output(); string(got) != `{"k"` {
+		t.Errorf("output = %s, want %s", got, `{"k"`)
+	}
+	w.Write([]byte(`:2`))
+	w.Write([]byte(`}`))
+	if got := w.output(); string(got) != `{"k":2}` {
+		t.Errorf("output = %s, want %s", got, `{"k":2}`)
+	}
+	if w.n != len(`{"k"`) {
+		t.Errorf("matched %d bytes, want %d", w.n, len(`{"k"`))
+	}
+}
+
+// countingMarshaler counts the calls of its MarshalJSON method.
+type countingMarshaler struct{ calls *int }
+
+func (m countingMarshaler) MarshalJSON() ([]byte, error) {
+	*m.calls++
+	return []byte(`"x"`), nil
+}
+
+func TestStreamMarshalComparison(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		StreamMarshalComparison: true,
+		ReportDifference:        func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	if b, err := codec.Marshal(map[string][]int{"k": {1}}); err != nil || string(b) != `{"k":[1]}` {
+		t.Errorf("Marshal = (%s, %v), want (%s, nil)", b, err, `{"k":[1]}`)
+	}
+	if len(got) != 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+	if b, err := codec.Marshal(map[string][]int{"k": nil}); err != nil || string(b) != `{"k":null}` {
+		t.Errorf("Marshal = (%s, %v), want (%s, nil)", b, err, `{"k":null}`)
+	}
+	if len(got) != 1 || string(got[0].JSONValueV1) != `{"k":null}` || string(got[0].JSONValueV2) != `{"k":[]}` {
+		t.Errorf("got differences %v, want a difference with the complete v2 output", got)
+	}
+
+	// Methods are called only once by each of v1 and v2
+	// even if the outputs diverge before the method is called.
+	got = nil
+	var calls int
+	in := struct {
+		A []int
+		M countingMarshaler
+	}{M: countingMarshaler{&calls}}
+	want1, want2 := `{"A":null,"M":"x"}`, `{"A":[],"M":"x"}`
+	if b, err := codec.Marshal(in); err != nil || string(b) != want1 {
+		t.Errorf("Marshal = (%s, %v), want (%s, nil)", b, err, want1)
+	}
+	if calls != 2 {
+		t.Errorf("MarshalJSON called %d times, want 2", calls)
+	}
+	if len(got) != 1 || string(got[0].JSONValueV2) != want2 {
+		t.Errorf("got differences %v, want a difference with v2 output %s", got, want2)
+	}
+}
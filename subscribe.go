@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"iter"
+)
+
+// subscriberBufferSize is the number of differences buffered
+// for each subscriber of [Codec.Differences].
+const subscriberBufferSize = 64
+
+// Differences returns an iterator over the differences detected by c
+// from the start of iteration until ctx is done or iteration is stopped.
+// It yields the same differences that are passed to [Codec.ReportDifference]
+// (which need not be set) so that consumers may perform custom aggregation.
+// Multiple iterators may be active concurrently and each observes
+// every difference. The differences are copied as if
+// [Codec.CopyDifferenceValues] were enabled.
+//
+// Differences are buffered for each iterator, but are never waited upon.
+// Differences that arrive while the buffer is full are not delivered
+// and are counted in [CodecMetrics.NumDifferencesUndelivered].
+func (c *Codec) Differences(ctx context.Context) iter.Seq[Difference] {
+	return func(yield func(Difference) bool) {
+		ch := make(chan Difference, subscriberBufferSize)
+		c.subscribers.Store(ch, true)
+		c.numSubscribers.Add(1)
+		defer func() {
+			c.subscribers.Delete(ch)
+			c.numSubscribers.Add(-1)
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case d := <-ch:
+				if !yield(d) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// reportsDifferences reports whether detected differences
+// are passed to [Codec.ReportDifference], to any handlers
+// added by [Codec.AddDifferenceHandler], or to [Codec.Differences].
+func (c *Codec) reportsDifferences() bool {
+	return c.ReportDifference != nil || len(c.differenceHandlers()) > 0 || c.numSubscribers.Load() > 0
+}
+
+// publishDifference passes d to [Codec.ReportDifference] (if set),
+// to every handler added by [Codec.AddDifferenceHandler],
+// and to every subscriber of [Codec.Differences].
+func (c *Codec) publishDifference(d Difference) {
+	if c.ReportDifference != nil {
+		c.ReportDifference(d)
+	}
+	for _, h := range c.differenceHandlers() {
+		h.f(d)
+	}
+	if c.numSubscribers.Load() == 0 {
+		return
+	}
+	if !c.CopyDifferenceValues && c.AsyncReportQueueSize <= 0 {
+		d = d.clone()
+	}
+	c.subscribers.Range(func(ch, _ any) bool {
+		select {
+		case ch.(chan Difference) <- d:
+		default:
+			c.add(&c.NumDifferencesUndelivered, 1)
+		}
+		return true
+	})
+}
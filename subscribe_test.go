@@ -0,0 +1,73 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestDifferences(t *testing.T) {
+	codec := new(Codec)
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal([]int(nil)) // no subscribers yet
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []Difference)
+	go func() {
+		var got []Difference
+		for d := range codec.Differences(ctx) {
+			got = append(got, d)
+			if len(got) == 2 {
+				break
+			}
+		}
+		done <- got
+	}()
+	waitForSubscribers(codec)
+	codec.Marshal([]int(nil))
+	codec.Marshal(map[string]int(nil))
+	got := <-done
+	if len(got) != 2 || got[0].GoType.String() != "[]int" || got[1].GoType.String() != "map[string]int" {
+		t.Errorf("got differences %v, want []int and map[string]int differences", got)
+	}
+	if n := codec.numSubscribers.Load(); n != 0 {
+		t.Errorf("numSubscribers = %d, want 0", n)
+	}
+
+	// Cancellation stops iteration.
+	go cancel()
+	for range codec.Differences(ctx) {
+		t.Errorf("unexpected difference")
+	}
+
+	// Slow subscribers do not block reporting.
+	var reported int
+	codec.ReportDifference = func(Difference) { reported++ }
+	block := make(chan struct{})
+	defer close(block)
+	go func() {
+		for range codec.Differences(context.Background()) {
+			<-block
+		}
+	}()
+	waitForSubscribers(codec)
+	for range subscriberBufferSize + 2 {
+		codec.Marshal([]int(nil))
+	}
+	if reported != subscriberBufferSize+2 {
+		t.Errorf("reported = %d, want %d", reported, subscriberBufferSize+2)
+	}
+	if n := codec.NumDifferencesUndelivered.Value(); n < 1 {
+		t.Errorf("NumDifferencesUndelivered = %d, want at least 1", n)
+	}
+}
+
+func waitForSubscribers(c *Codec) {
+	for c.numSubscribers.Load() == 0 {
+		runtime.Gosched()
+	}
+}
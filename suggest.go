@@ -0,0 +1,304 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// suggestedCall formats a call of [Marshal] or [Unmarshal] that specifies
+// the detected options of d that are not already among the caller options
+// as Go source (e.g., "jsonsplit.Unmarshal(b, v, jsonv2.MatchCaseInsensitiveNames(true))").
+// Since [Decoder.UseNumber] is not an option, it is suggested as a note
+// (e.g., "jsonsplit.Unmarshal(b, v) // and Decoder.UseNumber").
+// It returns the empty string if no additional options were detected or
+// if d is not for a marshal or unmarshal call.
+func suggestedCall(d Difference) string {
+	var call string
+	switch d.Func {
+	case "Marshal":
+		call = "jsonsplit.Marshal(v"
+	case "Unmarshal":
+		call = "jsonsplit.Unmarshal(b, v"
+	default:
+		return ""
+	}
+	var have, args []string
+	for name := range d.CallerOptionNames() {
+		if expr, ok := optionExpr(name); ok {
+			have = append(have, expr)
+		}
+	}
+	var useNumber bool
+	for name := range d.OptionNames() {
+		expr, ok := optionExpr(name)
+		switch {
+		case !ok:
+			useNumber = useNumber || name == "jsonv1.Decoder.UseNumber"
+		case !slices.Contains(have, expr) && !slices.Contains(args, expr):
+			args = append(args, expr)
+		}
+	}
+	if len(args) == 0 && !useNumber {
+		return ""
+	}
+	for _, arg := range args {
+		call += ", " + arg
+	}
+	call += ")"
+	if useNumber {
+		call += " // and Decoder.UseNumber"
+	}
+	return call
+}
+
+// optionExpr formats an option name as reported by [Difference.OptionNames]
+// as a Go expression (e.g., "jsonv2.Deterministic(true)").
+// It reports false for names that are not expressible as an option
+// (i.e., "jsonv1.Decoder.UseNumber").
+func optionExpr(name string) (string, bool) {
+	switch {
+	case name == "jsonv1.Decoder.UseNumber":
+		return "", false
+	case strings.HasSuffix(name, ")"):
+		return name, true // e.g., "jsonv2.Deterministic(false)"
+	default:
+		return name + "(true)", true
+	}
+}
+
+// TagSuggestion is a suggested struct tag for a single struct field
+// that resolves a difference at the declaration of the Go type
+// instead of at every call site (see [Difference.TagSuggestions]).
+type TagSuggestion struct {
+	// Type is the fully qualified name of the struct type
+	// (e.g., "example.com/pkg.User").
+	Type string
+	// Field is the Go name of the struct field (e.g., "Aliases").
+	Field string
+	// Tag is the entire suggested struct tag of the field
+	// (e.g., `json:"aliases,format:emitnull"`).
+	Tag string
+}
+
+// tagOptionAppliesTo maps the names of options that are expressible
+// as struct tag options (see [OptionInfo.Tag]) to a report of
+// whether the tag option applies to a field type.
+// Tag options not in the map apply to all field types.
+var tagOptionAppliesTo = map[string]func(reflect.Type) bool{
+	"jsonv2.FormatNilSliceAsNull": func(t reflect.Type) bool {
+		return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+	},
+	"jsonv2.FormatNilMapAsNull": func(t reflect.Type) bool {
+		return t.Kind() == reflect.Map
+	},
+	"jsonv1.FormatByteArrayAsArray": func(t reflect.Type) bool {
+		return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8
+	},
+	"jsonv1.FormatDurationAsNano": func(t reflect.Type) bool {
+		return t == reflect.TypeFor[time.Duration]()
+	},
+}
+
+// tagOption returns the struct tag option equivalent to the named option
+// if it applies to a field of type t.
+func tagOption(name string, t reflect.Type) (string, bool) {
+	info, ok := LookupOption(name)
+	if !ok || info.Tag == "" {
+		return "", false
+	}
+	if appliesTo, ok := tagOptionAppliesTo[name]; ok && !appliesTo(t) {
+		return "", false
+	}
+	return info.Tag, true
+}
+
+// tagSuggestions suggests struct tags for the struct field at which
+// the values diverged (as located by GoValuePath or JSONValuePointer)
+// for every detected option in d that is expressible as a tag option.
+// It returns nil if the field cannot be located.
+func tagSuggestions(d Difference) []TagSuggestion {
+	if d.Options == nil {
+		return nil
+	}
+	st, sf, ok := divergedField(d)
+	if !ok {
+		return nil
+	}
+	jsonTag, _ := sf.Tag.Lookup("json")
+	opts := strings.Split(jsonTag, ",")[1:]
+	have := len(opts)
+	for name := range d.OptionNames() {
+		if tag, ok := tagOption(name, sf.Type); ok && !slices.Contains(opts, tag) {
+			opts = append(opts, tag)
+		}
+	}
+	if len(opts) == have {
+		return nil // already specified by the tag
+	}
+	return []TagSuggestion{{Type: typeString(st), Field: sf.Name, Tag: withTagOptions(sf.Tag, opts[have:])}}
+}
+
+// withTagOptions returns the struct tag with the json tag options appended,
+// omitting any options that are already present.
+func withTagOptions(tag reflect.StructTag, opts []string) string {
+	jsonTag, _ := tag.Lookup("json")
+	have := strings.Split(jsonTag, ",")[1:]
+	for _, opt := range opts {
+		if !slices.Contains(have, opt) {
+			jsonTag += "," + opt
+		}
+	}
+
+	// Parse the tag in the conventional format (see [reflect.StructTag])
+	// and replace (or append) the json key while preserving all others.
+	var pairs []string
+	var replaced bool
+	for s := strings.TrimLeft(string(tag), " "); s != ""; s = strings.TrimLeft(s, " ") {
+		key, rest, ok := strings.Cut(s, ":")
+		q, err := strconv.QuotedPrefix(rest)
+		if !ok || err != nil || strings.ContainsAny(key, " \"") {
+			pairs = append(pairs, s) // preserve any malformed remainder as is
+			break
+		}
+		s = rest[len(q):]
+		if key == "json" && !replaced {
+			q, replaced = strconv.Quote(jsonTag), true
+		}
+		pairs = append(pairs, key+":"+q)
+	}
+	if !replaced {
+		pairs = append(pairs, "json:"+strconv.Quote(jsonTag))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// fieldAtGoPath returns the last struct field along a path
+// as formatted by [goValuePath] (e.g., "User.Aliases[2]"),
+// along with the struct type that declares it.
+func fieldAtGoPath(t reflect.Type, path string) (reflect.Type, reflect.StructField, bool) {
+	// Skip the root type name.
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		path = path[i:]
+	} else {
+		path = ""
+	}
+	var st reflect.Type
+	var sf reflect.StructField
+	var found bool
+	for path != "" {
+		t = derefType(t)
+		switch path[0] {
+		case '.':
+			name := path[1:]
+			if i := strings.IndexAny(name, ".["); i >= 0 {
+				name = name[:i]
+			}
+			path = path[1+len(name):]
+			if t.Kind() != reflect.Struct {
+				return nil, sf, false
+			}
+			f, ok := t.FieldByName(name)
+			if !ok {
+				return nil, sf, false
+			}
+			st, sf, found = declaringType(t, f), f, true
+			t = f.Type
+		case '[':
+			if q, err := strconv.QuotedPrefix(path[1:]); err == nil {
+				path = path[1+len(q):]
+			}
+			i := strings.IndexByte(path, ']')
+			if i < 0 {
+				return nil, sf, false
+			}
+			path = path[i+1:]
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				return st, sf, found // e.g., the dynamic value of an interface
+			}
+		default:
+			return nil, sf, false
+		}
+	}
+	return st, sf, found
+}
+
+// fieldAtJSONPointer returns the last struct field along a JSON pointer
+// into the JSON representation of t (e.g., "/users/2/aliases"),
+// along with the struct type that declares it.
+func fieldAtJSONPointer(t reflect.Type, ptr jsontext.Pointer) (reflect.Type, reflect.StructField, bool) {
+	var st reflect.Type
+	var sf reflect.StructField
+	var found bool
+	for tok := range ptr.Tokens() {
+		t = derefType(t)
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := fieldByJSONName(t, tok)
+			if !ok {
+				return nil, sf, false
+			}
+			st, sf, found = declaringType(t, f), f, true
+			t = f.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return st, sf, found // e.g., the dynamic value of an interface
+		}
+	}
+	return st, sf, found
+}
+
+// fieldByJSONName returns the field of the struct type t
+// that is serialized with the JSON object name,
+// preferring an exact match over a case-insensitive match.
+func fieldByJSONName(t reflect.Type, name string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	var folded bool
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || (f.Anonymous && f.Tag.Get("json") == "") {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		switch {
+		case jsonName == name:
+			return f, true
+		case !folded && strings.EqualFold(jsonName, name):
+			fold, folded = f, true
+		}
+	}
+	return fold, folded
+}
+
+// declaringType returns the struct type that declares the field f of t,
+// which differs from t for fields promoted from embedded structs.
+func declaringType(t reflect.Type, f reflect.StructField) reflect.Type {
+	for _, i := range f.Index[:len(f.Index)-1] {
+		t = derefType(t.Field(i).Type)
+	}
+	return t
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
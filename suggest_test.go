@@ -0,0 +1,150 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	jsontext "github.com/go-json-experiment/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+)
+
+func TestSuggestedCall(t *testing.T) {
+	tests := []struct {
+		d    Difference
+		want string
+	}{
+		{d: Difference{Func: "Marshal"}, want: ""},
+		{d: Difference{Func: "Compact", Options: jsontext.AllowInvalidUTF8(true)}, want: ""},
+		{
+			d:    Difference{Func: "Marshal", Options: jsonv2.FormatNilSliceAsNull(true)},
+			want: "jsonsplit.Marshal(v, jsonv2.FormatNilSliceAsNull(true))",
+		}, {
+			d: Difference{
+				Func:          "Unmarshal",
+				CallerOptions: jsonv2.JoinOptions(jsonv2.RejectUnknownMembers(true), jsontext.AllowDuplicateNames(false)),
+				Options:       jsonv2.JoinOptions(jsonv2.MatchCaseInsensitiveNames(true), jsontext.AllowDuplicateNames(true)),
+			},
+			want: "jsonsplit.Unmarshal(b, v, jsontext.AllowDuplicateNames(true), jsonv2.MatchCaseInsensitiveNames(true))",
+		}, {
+			d: Difference{
+				Func:          "Marshal",
+				CallerOptions: jsonv2.JoinOptions(jsonv2.Deterministic(true), jsonv2.FormatNilSliceAsNull(true)),
+				Options:       jsonv2.JoinOptions(jsonv2.FormatNilSliceAsNull(true), jsonv2.FormatNilMapAsNull(true)),
+			},
+			want: "jsonsplit.Marshal(v, jsonv2.FormatNilMapAsNull(true))",
+		}, {
+			d: Difference{
+				Func:          "Marshal",
+				CallerOptions: jsonv2.FormatNilSliceAsNull(true),
+				Options:       jsonv2.FormatNilSliceAsNull(true),
+			},
+			want: "", // already specified by the caller
+		}, {
+			d:    Difference{Func: "Unmarshal", Options: useNumber()},
+			want: "jsonsplit.Unmarshal(b, v) // and Decoder.UseNumber",
+		}, {
+			d:    Difference{Func: "Unmarshal", Options: jsonv2.JoinOptions(useNumber(), jsonv2.MatchCaseInsensitiveNames(true))},
+			want: "jsonsplit.Unmarshal(b, v, jsonv2.MatchCaseInsensitiveNames(true)) // and Decoder.UseNumber",
+		},
+	}
+	for _, tt := range tests {
+		if got := suggestedCall(tt.d); got != tt.want {
+			t.Errorf("suggestedCall(%v):\n\tgot  %s\n\twant %s", tt.d, got, tt.want)
+		}
+	}
+}
+
+type tagBase struct {
+	Timeout time.Duration `json:"timeout"`
+}
+
+type tagUser struct {
+	tagBase
+	Name    string            `json:"name" xml:"name"`
+	Aliases []string          `json:"aliases,omitempty"`
+	Hash    [4]byte           `json:",format:array"`
+	Friends []*tagUser        `json:"friends"`
+	Extra   map[string]string `json:"-"`
+}
+
+func TestTagSuggestions(t *testing.T) {
+	typeOfUser := reflect.TypeFor[*tagUser]()
+	tests := []struct {
+		d    Difference
+		want []TagSuggestion
+	}{{
+		d:    Difference{GoType: typeOfUser, GoValuePath: "tagUser.Name"},
+		want: nil, // no options
+	}, {
+		d:    Difference{GoType: typeOfUser, Options: jsonv2.MatchCaseInsensitiveNames(true)},
+		want: nil, // no location
+	}, {
+		d:    Difference{GoType: typeOfUser, GoValuePath: "tagUser.Name", Options: jsonv2.MatchCaseInsensitiveNames(true)},
+		want: []TagSuggestion{{typeString(typeOfUser.Elem()), "Name", `json:"name,case:ignore" xml:"name"`}},
+	}, {
+		d:    Difference{GoType: typeOfUser, JSONValuePointer: "/friends/0/aliases", Options: jsonv2.FormatNilSliceAsNull(true)},
+		want: []TagSuggestion{{typeString(typeOfUser.Elem()), "Aliases", `json:"aliases,omitempty,format:emitnull"`}},
+	}, {
+		d:    Difference{GoType: typeOfUser, JSONValuePointer: "/name", Options: jsonv2.FormatNilSliceAsNull(true)},
+		want: nil, // option does not apply to the field type
+	}, {
+		d:    Difference{GoType: typeOfUser, JSONValuePointer: "/Hash", Options: jsonv1.FormatByteArrayAsArray(true)},
+		want: nil, // tag option already present
+	}, {
+		d:    Difference{GoType: typeOfUser, GoValuePath: "tagUser.Timeout", Options: jsonv1.FormatDurationAsNano(true)},
+		want: []TagSuggestion{{typeString(reflect.TypeFor[tagBase]()), "Timeout", `json:"timeout,format:nano"`}},
+	}, {
+		d:    Difference{GoType: typeOfUser, JSONValuePointer: "/Extra", Options: jsonv2.FormatNilMapAsNull(true)},
+		want: nil, // ignored field
+	}}
+	for _, tt := range tests {
+		if d := cmp.Diff(tagSuggestions(tt.d), tt.want); d != "" {
+			t.Errorf("tagSuggestions(%v) mismatch (-got +want):\n%s", tt.d, d)
+		}
+	}
+}
+
+func TestWithTagOptions(t *testing.T) {
+	tests := []struct {
+		tag  reflect.StructTag
+		opts []string
+		want string
+	}{
+		{``, []string{"case:ignore"}, `json:",case:ignore"`},
+		{`json:"name"`, []string{"case:ignore", "format:emitnull"}, `json:"name,case:ignore,format:emitnull"`},
+		{`json:"name,case:ignore"`, []string{"case:ignore"}, `json:"name,case:ignore"`},
+		{`xml:"n" json:"name" yaml:"n"`, []string{"format:nano"}, `xml:"n" json:"name,format:nano" yaml:"n"`},
+		{`xml:"a\"b"`, []string{"case:ignore"}, `xml:"a\"b" json:",case:ignore"`},
+	}
+	for _, tt := range tests {
+		if got := withTagOptions(tt.tag, tt.opts); got != tt.want {
+			t.Errorf("withTagOptions(%q, %q) = %q, want %q", tt.tag, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestReportSuggestions(t *testing.T) {
+	var got Difference
+	codec := &Codec{AutoDetectOptions: true, ReportDifference: func(d Difference) { got = d }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(&tagUser{Name: "x", Hash: [4]byte{1}})
+	if want := "jsonsplit.Marshal(v, jsonv1.FormatDurationAsNano(true), jsonv2.FormatNilSliceAsNull(true))"; got.SuggestedCall != want {
+		t.Errorf("SuggestedCall = %s, want %s", got.SuggestedCall, want)
+	}
+	want := []TagSuggestion{{
+		Type:  typeString(reflect.TypeFor[tagBase]()),
+		Field: "Timeout",
+		Tag:   `json:"timeout,format:nano"`,
+	}}
+	if d := cmp.Diff(got.TagSuggestions, want); d != "" {
+		t.Errorf("TagSuggestions mismatch (-got +want):\n%s", d)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// textDiffContext is the number of unchanged lines
+// surrounding each change in [Difference.TextDiff].
+const textDiffContext = 3
+
+// maxTextDiffCells is the maximum size of the table used to compute
+// the longest common subsequence of lines in [Difference.TextDiff].
+// Larger inputs are rendered as a single replacement of the changed lines.
+const maxTextDiffCells = 1 << 22
+
+// TextDiff returns a unified diff from [Difference.JSONValueV1]
+// to [Difference.JSONValueV2] after indenting both values,
+// so that only the changed lines need to be inspected.
+// Invalid JSON is compared as is.
+// It returns the empty string if there are no JSON outputs
+// (e.g., for unmarshal differences) or if the indented outputs are identical.
+func (d Difference) TextDiff() string {
+	if len(d.JSONValueV1) == 0 && len(d.JSONValueV2) == 0 {
+		return ""
+	}
+	x := strings.Split(indentForDiff(d.JSONValueV1), "\n")
+	y := strings.Split(indentForDiff(d.JSONValueV2), "\n")
+	edits := diffLines(x, y)
+	if !slices.ContainsFunc(edits, func(e lineEdit) bool { return e.op != ' ' }) {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("--- v1\n+++ v2\n")
+	var n1, n2 int // number of lines of x and y preceding edits[next]
+	var next int
+	for start := 0; start < len(edits); {
+		// Find the next change and extend the hunk until
+		// the unchanged lines between changes exceed the context.
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end := start + 1
+		for j := end; j < len(edits) && j-end <= 2*textDiffContext; j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			}
+		}
+		lo := max(start-textDiffContext, next)
+		hi := min(end+textDiffContext, len(edits))
+		n1, n2 = n1+lo-next, n2+lo-next
+
+		var c1, c2 int
+		for _, e := range edits[lo:hi] {
+			c1 += btoi(e.op != '+')
+			c2 += btoi(e.op != '-')
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(n1, c1), hunkRange(n2, c2))
+		for _, e := range edits[lo:hi] {
+			b.WriteString(string(e.op) + e.text + "\n")
+		}
+		n1, n2, next, start = n1+c1, n2+c2, hi, hi
+	}
+	return b.String()
+}
+
+// indentForDiff returns b indented with one value per line.
+func indentForDiff(b []byte) string {
+	v := jsontext.Value(append([]byte(nil), b...))
+	if err := v.Indent(jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)); err != nil {
+		return string(b)
+	}
+	return string(v)
+}
+
+// hunkRange formats the start and count of lines in a unified diff hunk,
+// where start is the number of preceding lines.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// lineEdit is a line in an edit script,
+// where op is ' ' for a common line, '-' for a deleted line,
+// and '+' for an inserted line.
+type lineEdit struct {
+	op   byte
+	text string
+}
+
+// diffLines returns an edit script from x to y
+// based on the longest common subsequence of lines.
+func diffLines(x, y []string) []lineEdit {
+	// Trim the common prefix and suffix, which is typically most of the input.
+	var pre, suf int
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+	xm, ym := x[pre:len(x)-suf], y[pre:len(y)-suf]
+
+	var edits []lineEdit
+	for _, s := range x[:pre] {
+		edits = append(edits, lineEdit{' ', s})
+	}
+	if (len(xm)+1)*(len(ym)+1) > maxTextDiffCells {
+		for _, s := range xm {
+			edits = append(edits, lineEdit{'-', s})
+		}
+		for _, s := range ym {
+			edits = append(edits, lineEdit{'+', s})
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence
+		// of xm[i:] and ym[j:].
+		lcs := make([][]int32, len(xm)+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, len(ym)+1)
+		}
+		for i := len(xm) - 1; i >= 0; i-- {
+			for j := len(ym) - 1; j >= 0; j-- {
+				if xm[i] == ym[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		for i, j := 0, 0; i < len(xm) || j < len(ym); {
+			switch {
+			case i < len(xm) && j < len(ym) && xm[i] == ym[j]:
+				edits = append(edits, lineEdit{' ', xm[i]})
+				i, j = i+1, j+1
+			case j == len(ym) || (i < len(xm) && lcs[i+1][j] >= lcs[i][j+1]):
+				edits = append(edits, lineEdit{'-', xm[i]})
+				i++
+			default:
+				edits = append(edits, lineEdit{'+', ym[j]})
+				j++
+			}
+		}
+	}
+	for _, s := range x[len(x)-suf:] {
+		edits = append(edits, lineEdit{' ', s})
+	}
+	return edits
+}
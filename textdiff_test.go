@@ -0,0 +1,46 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTextDiff(t *testing.T) {
+	var items1, items2 []string
+	for i := range 20 {
+		items1 = append(items1, fmt.Sprint(i))
+		items2 = append(items2, fmt.Sprint(i))
+	}
+	items2[2] = "null"
+	items2 = append(items2[:15], items2[16:]...)
+
+	tests := []struct {
+		v1, v2 string
+		want   string
+	}{
+		{v1: ``, v2: ``, want: ``},
+		{v1: `{"k":[1]}`, v2: `{ "k" : [ 1 ] }`, want: ``},
+		{v1: `1`, v2: `2`, want: "--- v1\n+++ v2\n@@ -1 +1 @@\n-1\n+2\n"},
+		{v1: `{"k":null}`, v2: `{"k":[]}`, want: "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n {\n-\t\"k\": null\n+\t\"k\": []\n }\n"},
+		{v1: `[1]`, v2: ``, want: "--- v1\n+++ v2\n@@ -1,3 +1 @@\n-[\n-\t1\n-]\n+\n"},
+		{v1: `{"k":`, v2: `{"k":1}`, want: "--- v1\n+++ v2\n@@ -1 +1,3 @@\n-{\"k\":\n+{\n+\t\"k\": 1\n+}\n"},
+		{
+			v1: "[" + strings.Join(items1, ",") + "]",
+			v2: "[" + strings.Join(items2, ",") + "]",
+			want: "--- v1\n+++ v2\n" +
+				"@@ -1,7 +1,7 @@\n [\n \t0,\n \t1,\n-\t2,\n+\tnull,\n \t3,\n \t4,\n \t5,\n" +
+				"@@ -14,7 +14,6 @@\n \t12,\n \t13,\n \t14,\n-\t15,\n \t16,\n \t17,\n \t18,\n",
+		},
+	}
+	for _, tt := range tests {
+		got := Difference{JSONValueV1: []byte(tt.v1), JSONValueV2: []byte(tt.v2)}.TextDiff()
+		if got != tt.want {
+			t.Errorf("TextDiff(%s, %s):\ngot:\n%s\nwant:\n%s", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
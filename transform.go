@@ -0,0 +1,69 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+)
+
+// Transformer normalizes values of a particular Go type
+// before the results of v1 and v2 are compared.
+// See [Codec.Transformers].
+type Transformer struct {
+	typ reflect.Type
+	fn  func(reflect.Value) reflect.Value
+}
+
+// Transform constructs a [Transformer] that applies f to
+// every value of type T before the results of v1 and v2 are compared
+// (e.g., to round timestamps, scrub identifiers, or round floats).
+// The function f must not mutate its input.
+//
+// If T is [jsontext.Value], then it also transforms
+// the entire JSON output of a marshal call.
+func Transform[T any](f func(T) T) Transformer {
+	return Transformer{
+		typ: reflect.TypeFor[T](),
+		fn: func(v reflect.Value) reflect.Value {
+			in, _ := v.Interface().(T) // may be a nil interface value
+			out := f(in)
+			return reflect.ValueOf(&out).Elem() // preserve T even if an interface
+		},
+	}
+}
+
+// transformValue applies the transformers to v,
+// which must be exactly of the same type as T.
+func transformValue[T any](ts []Transformer, v T) T {
+	for _, t := range ts {
+		if t.typ == reflect.TypeFor[T]() {
+			v = t.fn(reflect.ValueOf(v)).Interface().(T)
+		}
+	}
+	return v
+}
+
+// transformGoValue returns a copy of v where the transformers
+// have been applied to every reachable value of a matching type.
+// Values within unexported struct fields are not transformed.
+// It returns v as is if no transformers are specified.
+func transformGoValue(ts []Transformer, v any) any {
+	if len(ts) == 0 || v == nil {
+		return v
+	}
+	c := copier{
+		seen: make(map[reflect.Type]map[uintptr]reflect.Value),
+		transform: func(v reflect.Value) (reflect.Value, bool) {
+			var ok bool
+			for _, t := range ts {
+				if t.typ == v.Type() {
+					v, ok = t.fn(v), true
+				}
+			}
+			return v, ok
+		},
+	}
+	return c.copy(reflect.ValueOf(v)).Interface()
+}
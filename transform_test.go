@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTransformGoValue(t *testing.T) {
+	type T struct {
+		Time    time.Time
+		Floats  []float64
+		Any     any
+		Error   error
+		Nested  *T
+		private string
+	}
+	ts := []Transformer{
+		Transform(func(t time.Time) time.Time { return t.Truncate(time.Second) }),
+		Transform(func(f float64) float64 { return math.Round(f*100) / 100 }),
+		Transform(func(s string) string { return strings.ToUpper(s) }),
+		Transform(func(s string) string { return s + "!" }),
+		Transform(func(error) error { return nil }),
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 123, time.UTC)
+	in := &T{
+		Time:    now,
+		Floats:  []float64{1.234, 5.678},
+		Any:     "hello",
+		Error:   jsontext.ErrDuplicateName,
+		Nested:  &T{Time: now, private: "hello"},
+		private: "hello",
+	}
+	got := transformGoValue(ts, in)
+	want := &T{
+		Time:    now.Truncate(time.Second),
+		Floats:  []float64{1.23, 5.68},
+		Any:     "HELLO!",
+		Nested:  &T{Time: now.Truncate(time.Second), private: "hello"},
+		private: "hello",
+	}
+	if d := cmp.Diff(got, want, cmp.AllowUnexported(T{})); d != "" {
+		t.Errorf("transformGoValue mismatch (-got +want):\n%s", d)
+	}
+	if in.Time != now || in.Floats[0] != 1.234 || in.Any != "hello" || in.Error == nil {
+		t.Errorf("transformGoValue mutated the input: %+v", in)
+	}
+}
+
+func TestCodecTransformers(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+	codec.Transformers = []Transformer{
+		Transform(func(v jsontext.Value) jsontext.Value { return bytes.ReplaceAll(v, []byte("[]"), []byte("null")) }),
+		Transform(func(s string) string { return "" }),
+	}
+
+	codec.Marshal(map[string][]int{"k": nil})
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(struct{ Name string }))
+	if len(got) > 0 {
+		t.Errorf("got differences %v, want none", got)
+	}
+
+	codec.Marshal(map[string]map[string]int{"k": nil})
+	codec.Unmarshal([]byte(`{"NAME":1}`), new(struct{ Name int }))
+	if len(got) != 2 {
+		t.Fatalf("got %d differences, want 2", len(got))
+	}
+	if got[1].GoValueV1.(*struct{ Name int }).Name != 1 {
+		t.Errorf("Difference.GoValueV1 = %v, want untransformed value", got[1].GoValueV1)
+	}
+}
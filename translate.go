@@ -0,0 +1,133 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	jsonv2 "github.com/go-json-experiment/json"            // TODO: Use "encoding/json/v2"
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+	jsonv1 "github.com/go-json-experiment/json/v1"         // TODO: Use "encoding/json"
+)
+
+// translateMarshalError translates err from a v2 marshal call
+// if [Codec.TranslateV2Errors] is enabled.
+func (c *Codec) translateMarshalError(err error) error {
+	if !c.TranslateV2Errors || err == nil {
+		return err
+	}
+	return translateMarshalError(err)
+}
+
+// translateUnmarshalError translates err from a v2 unmarshal call
+// of the JSON input b into v if [Codec.TranslateV2Errors] is enabled.
+func (c *Codec) translateUnmarshalError(b []byte, v any, err error) error {
+	if !c.TranslateV2Errors || err == nil {
+		return err
+	}
+	return translateUnmarshalError(b, v, err)
+}
+
+// translateMarshalError translates an error from [jsonv2.Marshal]
+// into the equivalent error type reported by [jsonv1.Marshal].
+// Errors without an equivalent are returned unchanged.
+func translateMarshalError(err error) error {
+	serr, ok := err.(*jsonv2.SemanticError)
+	switch {
+	case !ok:
+		return err
+	case serr.Err == nil && serr.GoType != nil:
+		return &jsonv1.UnsupportedTypeError{Type: serr.GoType}
+	case serr.Err == nil:
+		return err
+	case strings.HasPrefix(serr.Err.Error(), "unsupported value: "):
+		return &jsonv1.UnsupportedValueError{Str: strings.TrimPrefix(serr.Err.Error(), "unsupported value: ")}
+	case serr.GoType != nil && (serr.GoType.Implements(reflect.TypeFor[jsonv1.Marshaler]()) ||
+		serr.GoType.Implements(reflect.TypeFor[encoding.TextMarshaler]())):
+		return &jsonv1.MarshalerError{Type: serr.GoType, Err: serr.Err}
+	default:
+		return err
+	}
+}
+
+// translateUnmarshalError translates an error from [jsonv2.Unmarshal]
+// of the JSON input b into v into the equivalent error type
+// reported by [jsonv1.Unmarshal], following the same conventions
+// as [jsonv1.UnmarshalTypeError] for the Struct and Field.
+// Errors without an equivalent are returned unchanged.
+func translateUnmarshalError(b []byte, v any, err error) error {
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &jsonv1.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	switch err := err.(type) {
+	case *jsontext.SyntacticError:
+		// The message of a syntax error cannot be constructed,
+		// so obtain it by validating the same input with v1.
+		var raw jsonv1.RawMessage
+		if err1, ok := jsonv1.Unmarshal(b, &raw).(*jsonv1.SyntaxError); ok && err1.Offset == err.ByteOffset {
+			return err1
+		}
+		return err
+	case *jsonv2.SemanticError:
+		if err.Err == jsonv2.ErrUnknownName {
+			return fmt.Errorf("json: unknown field %q", err.JSONPointer.LastToken())
+		}
+		var value string
+		switch err.JSONKind {
+		case 'n', '"', '0':
+			value = err.JSONKind.String()
+		case 'f', 't':
+			value = "bool"
+		case '[', ']':
+			value = "array"
+		case '{', '}':
+			value = "object"
+		}
+		errInner := err.Err
+		if len(err.JSONValue) > 0 {
+			jsonValue := err.JSONValue
+			if (errInner == strconv.ErrRange || errInner == strconv.ErrSyntax) && isNumericType(err.GoType) {
+				value = "number"
+				if err.JSONKind == '"' {
+					jsonValue, _ = jsontext.AppendUnquote(nil, jsonValue)
+				}
+				errInner = nil
+			}
+			value += " " + string(jsonValue)
+		}
+		var rootName string
+		if err.JSONPointer != "" {
+			rootName = reflect.TypeOf(v).Elem().Name()
+		}
+		return &jsonv1.UnmarshalTypeError{
+			Value:  value,
+			Type:   err.GoType,
+			Offset: err.ByteOffset,
+			Struct: rootName,
+			Field:  strings.ReplaceAll(strings.TrimPrefix(string(err.JSONPointer), "/"), "/", "."),
+			Err:    errInner,
+		}
+	default:
+		return err
+	}
+}
+
+func isNumericType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
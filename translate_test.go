@@ -0,0 +1,88 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	jsonv1 "github.com/go-json-experiment/json/v1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTranslateUnmarshalError(t *testing.T) {
+	type inner struct{ N int8 }
+	type outer struct {
+		A int
+		B inner
+		C []bool
+	}
+	tests := []string{
+		`{"A":`,
+		`{"A":1}x`,
+		`{"A":"x"}`,
+		`{"A":1.5}`,
+		`{"B":{"N":300}}`,
+		`{"C":[true,"x"]}`,
+		`{"B":[]}`,
+	}
+	for _, in := range tests {
+		var v1, v2 outer
+		err1 := jsonv1.Unmarshal([]byte(in), &v1)
+		err2 := translateUnmarshalError([]byte(in), &v2, jsonv2.Unmarshal([]byte(in), &v2))
+		if reflect.TypeOf(err1) != reflect.TypeOf(err2) {
+			t.Errorf("Unmarshal(%s) error type = %T, want %T", in, err2, err1)
+		}
+		if diff := cmp.Diff(fmt.Sprint(err2), fmt.Sprint(err1)); diff != "" {
+			t.Errorf("Unmarshal(%s) error mismatch (-got +want):\n%s", in, diff)
+		}
+	}
+
+	err := translateUnmarshalError(nil, outer{}, jsonv2.Unmarshal([]byte(`{}`), outer{}))
+	if _, ok := err.(*jsonv1.InvalidUnmarshalError); !ok {
+		t.Errorf("Unmarshal(non-pointer) error = %T, want *jsonv1.InvalidUnmarshalError", err)
+	}
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("failure")
+}
+
+func TestTranslateMarshalError(t *testing.T) {
+	tests := []any{
+		make(chan int),
+		math.NaN(),
+		struct{ F func() }{},
+		failingMarshaler{},
+	}
+	for _, in := range tests {
+		_, err1 := jsonv1.Marshal(in)
+		_, err2 := jsonv2.Marshal(in)
+		err2 = translateMarshalError(err2)
+		if reflect.TypeOf(err1) != reflect.TypeOf(err2) {
+			t.Errorf("Marshal(%T) error type = %T, want %T", in, err2, err1)
+		}
+	}
+}
+
+func TestTranslateV2Errors(t *testing.T) {
+	codec := Codec{TranslateV2Errors: true}
+	codec.SetUnmarshalCallMode(CallBothButReturnV2)
+	var v struct{ A int }
+	err := codec.Unmarshal([]byte(`{"A":"x"}`), &v)
+	if err, ok := err.(*jsonv1.UnmarshalTypeError); !ok || err.Field != "A" || err.Offset != 5 {
+		t.Errorf("Unmarshal error = %#v, want *jsonv1.UnmarshalTypeError for field A at offset 5", err)
+	}
+	codec.TranslateV2Errors = false
+	if err := codec.Unmarshal([]byte(`{"A":"x"}`), &v); !errors.As(err, new(*jsonv2.SemanticError)) {
+		t.Errorf("Unmarshal error = %T, want *jsonv2.SemanticError", err)
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+// MarshalFor is like [Codec.Marshal], but is specialized for values of type T.
+// The codec caches state for T across calls, such that the overhead of
+// comparing v1 and v2 is reduced for frequently marshaled types.
+// In particular, the options registered for T by [RegisterTypeOptions]
+// are not looked up on every call, and if [Codec.AutoDetectOptions]
+// is enabled, then the options most recently detected for T are tried first
+// before falling back on detecting the options again.
+//
+// The [GlobalCodec] may be used by passing &GlobalCodec.
+func MarshalFor[T any](c *Codec, v T, o ...jsonv2.Options) ([]byte, error) {
+	return c.marshal(context.Background(), callSite{}, loadTypeState[T](c), jsonv1Marshal, jsonv2.Marshal, v, o...)
+}
+
+// UnmarshalFor is like [Codec.Unmarshal], but is specialized for
+// values of type T. The codec caches state for T across calls,
+// such that the overhead of comparing v1 and v2 is reduced for
+// frequently unmarshaled types. In particular, if T does not reference
+// any mutable memory (e.g., a struct composed only of strings and numbers),
+// then values are cloned with a plain assignment instead of reflection,
+// and if [Codec.AutoDetectOptions] is enabled, then the options most
+// recently detected for T are tried first before falling back on
+// detecting the options again.
+//
+// The [GlobalCodec] may be used by passing &GlobalCodec.
+func UnmarshalFor[T any](c *Codec, b []byte, v *T, o ...jsonv2.Options) error {
+	return c.unmarshal(context.Background(), callSite{}, loadTypeState[T](c), b, v, o...)
+}
+
+// RegisterTypeOptions registers options that c joins with the options of
+// every marshal and unmarshal call where the top-level value is of type T
+// or *T (e.g., for types that cannot be re-tagged but are known to need
+// particular options to preserve v1 behavior). The options are applied
+// on top of [Codec.DefaultOptions], but underneath any options specified
+// by the caller, and are therefore also reported in [Difference.CallerOptions].
+// Registering options for T replaces any options previously registered for T,
+// and registering no options removes them.
+//
+// The [GlobalCodec] may be used by passing &GlobalCodec.
+func RegisterTypeOptions[T any](c *Codec, opts ...jsonv2.Options) {
+	t := reflect.TypeFor[T]()
+	if len(opts) == 0 {
+		c.registeredOptions.Delete(t)
+	} else {
+		c.registeredOptions.Store(t, jsonv2.JoinOptions(opts...))
+	}
+	c.typeOptionsGen.Add(1)
+	c.notifyConfig()
+}
+
+// typeOptions returns the options registered by [RegisterTypeOptions]
+// for a top-level value of type t, or nil if there are none.
+// Options registered for t itself take precedence over those
+// registered for the element type of a pointer type t.
+func (c *Codec) typeOptions(t reflect.Type) jsonv2.Options {
+	if t == nil {
+		return nil
+	}
+	if opts, ok := c.registeredOptions.Load(t); ok {
+		return opts.(jsonv2.Options)
+	}
+	if t.Kind() == reflect.Pointer {
+		if opts, ok := c.registeredOptions.Load(t.Elem()); ok {
+			return opts.(jsonv2.Options)
+		}
+	}
+	return nil
+}
+
+// topLevelType returns the Go type of the top-level value v of
+// the "Marshal" or "Unmarshal" operation and the options registered for it
+// by [RegisterTypeOptions], which are cached in ts if non-nil.
+func (c *Codec) topLevelType(op string, ts *typeState, v any) (reflect.Type, jsonv2.Options) {
+	if ts == nil || (op == "Marshal" && ts.marshalType == nil) {
+		t := reflect.TypeOf(v)
+		return t, c.typeOptions(t)
+	}
+	gen := c.typeOptionsGen.Load()
+	opts := ts.typeOptions.Load()
+	if opts == nil || opts.gen != gen {
+		opts = &typeStateOptions{gen, c.typeOptions(ts.marshalType), c.typeOptions(ts.unmarshalType)}
+		ts.typeOptions.Store(opts)
+	}
+	if op == "Marshal" {
+		return ts.marshalType, opts.marshal
+	}
+	return ts.unmarshalType, opts.unmarshal
+}
+
+// typeState is the state cached by a [Codec] for a particular Go type.
+type typeState struct {
+	// clone clones a pointer to the type without reflection.
+	// It is nil if the type may reference mutable memory.
+	clone func(any) any
+
+	// marshalType and unmarshalType are the types T and *T of
+	// the top-level values passed to [MarshalFor] and [UnmarshalFor].
+	// The marshalType is nil if T is an interface type,
+	// in which case the dynamic type of the value is used.
+	marshalType, unmarshalType reflect.Type
+
+	// typeOptions caches the options registered for
+	// marshalType and unmarshalType by [RegisterTypeOptions].
+	typeOptions atomic.Pointer[typeStateOptions]
+
+	// marshalOptions and unmarshalOptions are the options
+	// most recently detected to resolve a difference for the type.
+	marshalOptions   atomic.Pointer[jsonv2.Options]
+	unmarshalOptions atomic.Pointer[jsonv2.Options]
+}
+
+// loadTypeState loads or creates the state for T in c.
+func loadTypeState[T any](c *Codec) *typeState {
+	t := reflect.TypeFor[T]()
+	if ts, ok := c.typeStates.Load(t); ok {
+		return ts.(*typeState)
+	}
+	ts := &typeState{unmarshalType: reflect.PointerTo(t)}
+	if t.Kind() != reflect.Interface {
+		ts.marshalType = t
+	}
+	if isShallowCopyableType(t) {
+		ts.clone = func(v any) any {
+			src, _ := v.(*T)
+			if src == nil {
+				return nil
+			}
+			dst := new(T)
+			*dst = *src
+			return dst
+		}
+	}
+	actual, _ := c.typeStates.LoadOrStore(t, ts)
+	return actual.(*typeState)
+}
+
+// typeStateOptions are the options cached in [typeState.typeOptions],
+// which are valid as long as gen equals [Codec.typeOptionsGen].
+type typeStateOptions struct {
+	gen                uint64
+	marshal, unmarshal jsonv2.Options
+}
+
+// detectedOptions returns the cache of detected options for the
+// "Marshal" or "Unmarshal" operation, or nil if ts is nil.
+func (ts *typeState) detectedOptions(op string) *atomic.Pointer[jsonv2.Options] {
+	switch {
+	case ts == nil:
+		return nil
+	case op == "Marshal":
+		return &ts.marshalOptions
+	default:
+		return &ts.unmarshalOptions
+	}
+}
+
+// cloneGoValueFor is like [Codec.cloneGoValue],
+// but uses the clone function in ts if available.
+// A custom [Codec.CloneGoValue] always takes precedence.
+func (c *Codec) cloneGoValueFor(ts *typeState, v any) any {
+	if ts != nil && ts.clone != nil && c.CloneGoValue == nil {
+		return ts.clone(v)
+	}
+	return c.cloneGoValue(v)
+}
+
+// isShallowCopyableType reports whether every value of type t
+// can be shallow copied without referencing any mutable memory.
+// It is the static equivalent of [canShallowCopy].
+func isShallowCopyableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isShallowCopyableType(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !isShallowCopyableType(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
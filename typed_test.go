@@ -0,0 +1,202 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	jsonv2 "github.com/go-json-experiment/json"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsShallowCopyableType(t *testing.T) {
+	tests := []struct {
+		typ  reflect.Type
+		want bool
+	}{
+		{reflect.TypeFor[int](), true},
+		{reflect.TypeFor[string](), true},
+		{reflect.TypeFor[[4]float64](), true},
+		{reflect.TypeFor[struct{ A, B string }](), true},
+		{reflect.TypeFor[struct{ A []string }](), false},
+		{reflect.TypeFor[*int](), false},
+		{reflect.TypeFor[any](), false},
+		{reflect.TypeFor[map[string]int](), false},
+		{reflect.TypeFor[time.Time](), false}, // contains a *time.Location
+	}
+	for _, tt := range tests {
+		if got := isShallowCopyableType(tt.typ); got != tt.want {
+			t.Errorf("isShallowCopyableType(%v) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalFor(t *testing.T) {
+	type user struct{ Name string }
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	var v user
+	wantCaller := callerPlus(codec.caller(), 1)
+	if err := UnmarshalFor(&codec, []byte(`{"NAME":"x"}`), &v); err != nil {
+		t.Fatalf("UnmarshalFor error: %v", err)
+	}
+	if v.Name != "x" {
+		t.Errorf("UnmarshalFor = %+v, want {Name:x}", v)
+	}
+	if len(got) != 1 || got[0].Caller != wantCaller {
+		t.Fatalf("got differences %v, want a difference from %s", got, wantCaller)
+	}
+	if diff := cmp.Diff(got[0].GoValueV2, &user{}); diff != "" {
+		t.Errorf("GoValueV2 mismatch (-got +want):\n%s", diff)
+	}
+
+	// Non-zero inputs are cloned by assignment for each implementation.
+	v = user{Name: "old"}
+	if err := UnmarshalFor(&codec, []byte(`{}`), &v); err != nil || v.Name != "old" {
+		t.Errorf("UnmarshalFor = (%+v, %v), want ({Name:old}, nil)", v, err)
+	}
+	if got := codec.NumUnmarshalCallBothSkipped.Value(); got != 0 {
+		t.Errorf("NumUnmarshalCallBothSkipped = %d, want 0", got)
+	}
+}
+
+func TestMarshalForCachesOptions(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		AutoDetectOptions: true,
+		ReportDifference:  func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	marshal := func() {
+		t.Helper()
+		b, err := MarshalFor(&codec, struct{ S []int }{})
+		if err != nil || string(b) != `{"S":null}` {
+			t.Errorf("MarshalFor = (%s, %v), want (%s, nil)", b, err, `{"S":null}`)
+		}
+	}
+	marshal()
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+	if diff := cmp.Diff(slices.Collect(got[0].OptionNames()), []string{"jsonv2.FormatNilSliceAsNull"}); diff != "" {
+		t.Errorf("OptionNames mismatch (-got +want):\n%s", diff)
+	}
+	ts := loadTypeState[struct{ S []int }](&codec)
+	if ts.marshalOptions.Load() == nil {
+		t.Fatalf("detected options not cached")
+	}
+
+	// Cached options that resolve the difference are used as is.
+	cached := jsonv2.JoinOptions(jsonv2.FormatNilSliceAsNull(true), jsonv2.FormatNilMapAsNull(true))
+	ts.marshalOptions.Store(&cached)
+	marshal()
+	if len(got) != 2 {
+		t.Fatalf("got %d differences, want 2", len(got))
+	}
+	if diff := cmp.Diff(slices.Collect(got[1].OptionNames()), []string{"jsonv2.FormatNilMapAsNull", "jsonv2.FormatNilSliceAsNull"}); diff != "" {
+		t.Errorf("OptionNames mismatch (-got +want):\n%s", diff)
+	}
+	if got := codec.NumAutoDetectResolved.Value(); got != 2 {
+		t.Errorf("NumAutoDetectResolved = %d, want 2", got)
+	}
+}
+
+func TestRegisterTypeOptions(t *testing.T) {
+	type user struct {
+		Name    string
+		Aliases []string
+	}
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV2)
+	codec.SetUnmarshalCallMode(CallBothButReturnV2)
+	RegisterTypeOptions[user](&codec, jsonv2.FormatNilSliceAsNull(true), jsonv2.MatchCaseInsensitiveNames(true))
+
+	// Options apply to both T and *T.
+	for _, v := range []any{user{}, &user{}} {
+		b, err := codec.Marshal(v)
+		if err != nil || string(b) != `{"Name":"","Aliases":null}` {
+			t.Errorf("Marshal(%T) = (%s, %v), want (%s, nil)", v, b, err, `{"Name":"","Aliases":null}`)
+		}
+	}
+	var u user
+	if err := codec.Unmarshal([]byte(`{"name":"x"}`), &u); err != nil || u.Name != "x" {
+		t.Errorf("Unmarshal = (%+v, %v), want ({Name:x}, nil)", u, err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d differences, want 0", len(got))
+	}
+
+	// Options do not apply to other types.
+	if b, _ := codec.Marshal([]string(nil)); string(b) != "[]" {
+		t.Errorf("Marshal([]string(nil)) = %s, want []", b)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+
+	// Registering no options removes them.
+	RegisterTypeOptions[user](&codec)
+	if b, _ := codec.Marshal(user{}); string(b) != `{"Name":"","Aliases":[]}` {
+		t.Errorf("Marshal(user{}) = %s, want %s", b, `{"Name":"","Aliases":[]}`)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d differences, want 2", len(got))
+	}
+}
+
+func TestMarshalForTypeOptions(t *testing.T) {
+	type user struct{ Aliases []string }
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	MarshalFor(&codec, user{})
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+
+	// Options registered after the state for T is cached still apply.
+	RegisterTypeOptions[user](&codec, jsonv2.FormatNilSliceAsNull(true))
+	if b, _ := MarshalFor(&codec, user{}); string(b) != `{"Aliases":null}` {
+		t.Errorf("MarshalFor = %s, want %s", b, `{"Aliases":null}`)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+
+	// The dynamic type is used for interface types.
+	if b, _ := MarshalFor[any](&codec, user{}); string(b) != `{"Aliases":null}` {
+		t.Errorf("MarshalFor[any] = %s, want %s", b, `{"Aliases":null}`)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d differences, want 1", len(got))
+	}
+}
+
+func BenchmarkTopLevelType(b *testing.B) {
+	type user struct{ Name string }
+	var c Codec
+	RegisterTypeOptions[time.Time](&c, jsonv2.Deterministic(true))
+	var out user
+	b.Run("Untyped", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c.topLevelType("Unmarshal", nil, &out)
+		}
+	})
+	b.Run("Typed", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c.topLevelType("Unmarshal", loadTypeState[user](&c), &out)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"reflect"
+
+	jsonv1std "encoding/json"
+
+	jsonv2 "github.com/go-json-experiment/json"    // TODO: Use "encoding/json/v2"
+	jsonv1 "github.com/go-json-experiment/json/v1" // TODO: Use "encoding/json"
+)
+
+// verifyMarshal performs additional verification of the v1 and v2 results
+// of a marshal call that compared both implementations,
+// where t is the Go type of v.
+// If returnV1 is specified, the result of v1 is being returned,
+// otherwise the result of v2 is being returned.
+func (c *Codec) verifyMarshal(ctx context.Context, caller string, t reflect.Type, returnV1 bool, v any, buf1, buf2 []byte, err1, err2 error, o ...jsonv2.Options) {
+	if t == nil {
+		return
+	}
+
+	// Verify that the returned output is accepted by the other implementation.
+	// Only report an error if the same implementation accepts its own output
+	// since some types are intentionally only ever marshaled.
+	if c.VerifyCrossDecode {
+		var errSame, errOther error
+		d := Difference{Caller: caller, Labels: contextLabels(ctx), Func: "Marshal", Check: "CrossDecode", GoType: t, GoValue: v, CallerOptions: callerOptions(o)}
+		switch {
+		case returnV1 && err1 == nil:
+			errSame = jsonv1Unmarshal(buf1, reflect.New(t).Interface(), o...)
+			errOther = jsonv2.Unmarshal(buf1, reflect.New(t).Interface(), o...)
+			d.JSONValueV1, d.ErrorV2 = buf1, errOther
+		case !returnV1 && err2 == nil:
+			errSame = jsonv2.Unmarshal(buf2, reflect.New(t).Interface(), o...)
+			errOther = jsonv1Unmarshal(buf2, reflect.New(t).Interface(), o...)
+			d.JSONValueV2, d.ErrorV1 = buf2, errOther
+		}
+		if errSame == nil && errOther != nil {
+			c.add(&c.NumMarshalCrossDecodeErrors, 1)
+			c.classifyDifference(&d)
+			if c.reportsDifferences() && c.sampleReport() {
+				c.reportDifference(d)
+			}
+		}
+	}
+
+	// Verify that each implementation can round-trip the Go value.
+	if c.VerifyRoundTrip {
+		if err1 == nil {
+			p := reflect.New(t)
+			err := jsonv1Unmarshal(buf1, p.Interface(), o...)
+			if val := p.Elem().Interface(); err != nil || !c.goEqual(v, val, len(buf1)) {
+				c.add(&c.NumMarshalRoundTripDiffs, 1)
+				d := Difference{
+					Caller: caller, Labels: contextLabels(ctx), Func: "Marshal", Check: "RoundTrip",
+					GoType: t, GoValue: v, JSONValueV1: buf1, GoValueV1: val, ErrorV1: err,
+					CallerOptions: callerOptions(o),
+				}
+				c.classifyDifference(&d)
+				if c.reportsDifferences() && c.sampleReport() {
+					c.reportDifference(d)
+				}
+			}
+		}
+		if err2 == nil {
+			p := reflect.New(t)
+			err := jsonv2.Unmarshal(buf2, p.Interface(), o...)
+			if val := p.Elem().Interface(); err != nil || !c.goEqual(v, val, len(buf2)) {
+				c.add(&c.NumMarshalRoundTripDiffs, 1)
+				d := Difference{
+					Caller: caller, Labels: contextLabels(ctx), Func: "Marshal", Check: "RoundTrip",
+					GoType: t, GoValue: v, JSONValueV2: buf2, GoValueV2: val, ErrorV2: err,
+					CallerOptions: callerOptions(o),
+				}
+				c.classifyDifference(&d)
+				if c.reportsDifferences() && c.sampleReport() {
+					c.reportDifference(d)
+				}
+			}
+		}
+	}
+
+	// Verify that each implementation can consume the output of the other
+	// and that both consumers agree on the resulting Go value.
+	if c.VerifyInterop && err1 == nil && err2 == nil {
+		p1, p2 := reflect.New(t), reflect.New(t)
+		errV1 := jsonv1Unmarshal(buf2, p1.Interface(), o...)
+		errV2 := jsonv2.Unmarshal(buf1, p2.Interface(), o...)
+		val1, val2 := p1.Elem().Interface(), p2.Elem().Interface()
+		if !c.unmarshalEqual(val1, val2, errV1, errV2, max(len(buf1), len(buf2))) {
+			c.add(&c.NumMarshalInteropDiffs, 1)
+			d := Difference{
+				Caller: caller, Labels: contextLabels(ctx), Func: "Marshal", Check: "Interop",
+				GoType: t, GoValue: v, JSONValueV1: buf1, JSONValueV2: buf2,
+				GoValueV1: val1, GoValueV2: val2, GoValuePath: goValuePath(val1, val2),
+				ErrorV1: errV1, ErrorV2: errV2, CallerOptions: callerOptions(o),
+			}
+			c.classifyDifference(&d)
+			if c.reportsDifferences() && c.sampleReport() {
+				c.reportDifference(d)
+			}
+		}
+	}
+
+	// Verify that the standard library agrees with the emulation of v1.
+	// The v1 result is always from the emulation since there are no options.
+	if c.VerifyStandardV1 && len(o) == 0 {
+		bufStd, errStd := jsonv1std.Marshal(v)
+		if !(c.jsonEqual(bufStd, buf1) && c.errorsEqual(errStd, err1)) {
+			c.add(&c.NumMarshalStandardV1Diffs, 1)
+			d := Difference{
+				Caller: caller, Labels: contextLabels(ctx), Func: "Marshal", Check: "StandardV1",
+				GoType: t, GoValue: v, JSONValueV1: bufStd, JSONValueV2: buf1,
+				ErrorV1: errStd, ErrorV2: err1,
+				OptionsV1: jsonv1.DefaultOptionsV1(), OptionsV2: jsonv1.DefaultOptionsV1(), StandardV1: true,
+			}
+			c.classifyDifference(&d)
+			if c.reportsDifferences() && c.sampleReport() {
+				c.reportDifference(d)
+			}
+		}
+	}
+}
+
+// verifyUnmarshal performs additional verification of the v1 result
+// of an unmarshal call that compared both implementations,
+// where t is the Go type of the value and valOrig is a clone of its original.
+func (c *Codec) verifyUnmarshal(ctx context.Context, caller string, ts *typeState, t reflect.Type, b []byte, valOrig, val1 any, err1 error, o ...jsonv2.Options) {
+	// Verify that the standard library agrees with the emulation of v1.
+	// The v1 result is always from the emulation since there are no options.
+	if c.VerifyStandardV1 && len(o) == 0 {
+		valStd := c.cloneGoValueFor(ts, valOrig)
+		errStd := jsonv1std.Unmarshal(b, valStd)
+		if !c.unmarshalEqual(valStd, val1, errStd, err1, len(b)) {
+			c.add(&c.NumUnmarshalStandardV1Diffs, 1)
+			d := Difference{
+				Caller: caller, Labels: contextLabels(ctx), Func: "Unmarshal", Check: "StandardV1",
+				GoType: t, JSONValue: b,
+				GoValueV1: valStd, GoValueV2: val1, GoValuePath: goValuePath(valStd, val1),
+				ErrorV1: errStd, ErrorV2: err1,
+				OptionsV1: jsonv1.DefaultOptionsV1(), OptionsV2: jsonv1.DefaultOptionsV1(), StandardV1: true,
+			}
+			c.classifyDifference(&d)
+			if c.reportsDifferences() && c.sampleReport() {
+				c.reportDifference(d)
+			}
+		}
+	}
+}
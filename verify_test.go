@@ -0,0 +1,150 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"reflect"
+	"testing"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+// duplicateNames marshals with duplicate object names,
+// which is accepted by v1 but rejected by v2.
+type duplicateNames struct {
+	A int `json:"a"`
+}
+
+func (duplicateNames) MarshalJSON() ([]byte, error) {
+	return []byte(`{"a":1,"a":2}`), nil
+}
+
+func TestVerifyCrossDecode(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		VerifyCrossDecode: true,
+		ReportDifference:  func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV2)
+	codec.Marshal(duplicateNames{}) // v2 output is an error
+	codec.Marshal(struct{ A []int }{})
+	if len(got) != 2 || got[0].Check != "" || got[1].Check != "" {
+		t.Fatalf("got differences %v, want 2 differences without checks", got)
+	}
+
+	got = nil
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.Marshal(duplicateNames{})
+	if len(got) != 2 || got[0].Check != "" || got[1].Check != "CrossDecode" {
+		t.Fatalf("got differences %v, want a difference and a CrossDecode difference", got)
+	}
+	if d := got[1]; string(d.JSONValueV1) != `{"a":1,"a":2}` || d.ErrorV1 != nil || d.ErrorV2 == nil {
+		t.Errorf("got CrossDecode difference %v, want v1 output rejected by v2", d)
+	}
+	if n := codec.NumMarshalCrossDecodeErrors.Value(); n != 1 {
+		t.Errorf("NumMarshalCrossDecodeErrors = %d, want 1", n)
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		VerifyRoundTrip:  true,
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	codec.Marshal(struct{ A, B int }{1, 2})
+	if len(got) != 0 {
+		t.Fatalf("got differences %v, want none", got)
+	}
+
+	// Only v2 loses the distinction between a nil and empty slice.
+	type T struct{ A []int }
+	codec.Marshal(T{})
+	if len(got) != 2 || got[0].Check != "" || got[1].Check != "RoundTrip" {
+		t.Fatalf("got differences %v, want a difference and a RoundTrip difference", got)
+	}
+	if d := got[1]; d.JSONValueV1 != nil || string(d.JSONValueV2) != `{"A":[]}` || !reflect.DeepEqual(d.GoValueV2, T{A: []int{}}) {
+		t.Errorf("got RoundTrip difference %v, want lossy round-trip for v2", d)
+	}
+	if n := codec.NumMarshalRoundTripDiffs.Value(); n != 1 {
+		t.Errorf("NumMarshalRoundTripDiffs = %d, want 1", n)
+	}
+}
+
+func TestVerifyInterop(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		VerifyInterop:    true,
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+
+	codec.Marshal(struct{ A, B int }{1, 2})
+	if len(got) != 0 {
+		t.Fatalf("got differences %v, want none", got)
+	}
+
+	// The v1 output unmarshals as a nil slice with v2,
+	// while the v2 output unmarshals as an empty slice with v1.
+	type T struct{ A []int }
+	codec.Marshal(T{})
+	if len(got) != 2 || got[0].Check != "" || got[1].Check != "Interop" {
+		t.Fatalf("got differences %v, want a difference and an Interop difference", got)
+	}
+	want := Difference{
+		Caller: got[1].Caller, Func: "Marshal", Check: "Interop",
+		GoType: reflect.TypeFor[T](), GoValue: T{},
+		JSONValueV1: []byte(`{"A":null}`), JSONValueV2: []byte(`{"A":[]}`),
+		GoValueV1: T{A: []int{}}, GoValueV2: T{}, GoValuePath: "T.A",
+		FieldDifferences: []FieldDifference{{Field: "A", ValueV1: "[]int{}", ValueV2: "[]int(nil)"}},
+		JSONValuePointer: "/A", JSONValueOffset: 5,
+		ID: got[1].ID, OptionsV1: got[1].OptionsV1, OptionsV2: got[1].OptionsV2,
+	}
+	if !reflect.DeepEqual(got[1], want) {
+		t.Errorf("got Interop difference:\n\t%v\nwant:\n\t%v", got[1], want)
+	}
+	if n := codec.NumMarshalInteropDiffs.Value(); n != 1 {
+		t.Errorf("NumMarshalInteropDiffs = %d, want 1", n)
+	}
+}
+
+func TestVerifyStandardV1(t *testing.T) {
+	var got []Difference
+	codec := Codec{
+		VerifyStandardV1: true,
+		ReportDifference: func(d Difference) { got = append(got, d) },
+	}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// The standard library and the emulation of v1 agree,
+	// such that only the difference between v1 and v2 is reported.
+	codec.Marshal(struct{ A []int }{})
+	codec.Unmarshal([]byte(`{"NAME":"x"}`), new(reverifyUser))
+	if len(got) != 2 || got[0].Check != "" || got[1].Check != "" {
+		t.Fatalf("got differences %v, want 2 differences without checks", got)
+	}
+
+	// Treat every Go value as different to simulate a bug in the emulation.
+	got = nil
+	codec.EqualGoValues = func(x, y any) bool { return false }
+	codec.Unmarshal([]byte(`{"Name":"x"}`), new(reverifyUser))
+	codec.Unmarshal([]byte(`{"Name":"x"}`), new(reverifyUser), jsonv2.Deterministic(true))
+	if len(got) != 3 || got[0].Check != "" || got[1].Check != "StandardV1" || got[2].Check != "" {
+		t.Fatalf("got differences %v, want a StandardV1 difference only without options", got)
+	}
+	want := &reverifyUser{Name: "x"}
+	if d := got[1]; string(d.JSONValue) != `{"Name":"x"}` || !reflect.DeepEqual(d.GoValueV1, want) || !reflect.DeepEqual(d.GoValueV2, want) {
+		t.Errorf("got StandardV1 difference %v, want results of jsonv1std and jsonv1", d)
+	}
+	if n := codec.NumUnmarshalStandardV1Diffs.Value(); n != 1 {
+		t.Errorf("NumUnmarshalStandardV1Diffs = %d, want 1", n)
+	}
+	if n := codec.NumMarshalStandardV1Diffs.Value(); n != 0 {
+		t.Errorf("NumMarshalStandardV1Diffs = %d, want 0", n)
+	}
+}
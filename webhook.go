@@ -0,0 +1,292 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	jsontext "github.com/go-json-experiment/json/jsontext" // TODO: Use "encoding/json/jsontext"
+)
+
+// WebhookSink records differences by sending them in batches
+// as HTTP POST requests to a central collector, which is useful for
+// services that have no log shipping to collect migration findings.
+// Its [WebhookSink.Report] method is intended for use with
+// [Codec.ReportDifference] (or [Codec.ReportEmulationRegression]),
+// or the sink may be added with [Codec.AddSink] to be closed by [Codec.Close].
+//
+// The body of each request is a JSON array of differences
+// (each encoded by [Difference.MarshalJSONLossless])
+// with a Content-Type of "application/json".
+// A request that fails with a transport error, a 429 status code,
+// or a 5xx status code is retried with exponential backoff.
+// Any other non-2xx status code is not retried.
+//
+// Differences are encoded by Report and sent by a background goroutine,
+// which is started by the first report and stopped by [WebhookSink.Close]
+// (or [WebhookSink.Shutdown]).
+// Report never blocks on the network; if the queue is full,
+// the difference is dropped and counted by [WebhookSink.NumDropped].
+// Every request is bounded by [WebhookSink.Timeout] and
+// every retry backoff is cancelled when the sink is shut down,
+// so that an unresponsive collector cannot stall the process.
+//
+// The exported fields must be set before the first report.
+// The methods are safe for concurrent use.
+//
+// For example:
+//
+//	sink := &jsonsplit.WebhookSink{URL: "https://collector.example.com/diffs"}
+//	defer sink.Close()
+//	jsonsplit.GlobalCodec.ReportDifference = sink.Report
+type WebhookSink struct {
+	// URL is the URL that batches of differences are posted to.
+	URL string
+
+	// Client is the HTTP client used to send requests.
+	// If nil, it uses [http.DefaultClient].
+	Client *http.Client
+
+	// Timeout is the maximum duration of a single request
+	// (including reading the response), which applies
+	// in addition to any timeout of the Client.
+	// If zero or negative, it uses 10 seconds.
+	Timeout time.Duration
+
+	// CloseTimeout is the maximum duration that [WebhookSink.Close]
+	// waits for queued differences to be sent.
+	// If zero or negative, it uses 30 seconds.
+	CloseTimeout time.Duration
+
+	// Header contains additional headers to send with every request
+	// (e.g., an "Authorization" header).
+	Header http.Header
+
+	// MaxBatchSize is the maximum number of differences per request.
+	// If zero or negative, it uses 100.
+	MaxBatchSize int
+
+	// FlushInterval is the maximum duration that a difference is queued
+	// before it is sent, even if the batch is not full.
+	// If zero or negative, it uses 5 seconds.
+	FlushInterval time.Duration
+
+	// QueueSize is the maximum number of differences queued to be sent.
+	// If zero or negative, it uses 1000.
+	QueueSize int
+
+	// MaxRetries is the maximum number of times a failed request is retried.
+	// If zero, it uses 3. If negative, failed requests are not retried.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry,
+	// which doubles with every subsequent retry up to 1 minute.
+	// If zero or negative, it uses 1 second.
+	RetryBackoff time.Duration
+
+	mu      sync.Mutex
+	queue   chan jsontext.Value
+	done    chan struct{}
+	ctx     context.Context // cancelled to abort requests and backoffs
+	cancel  context.CancelFunc
+	closed  bool
+	dropped int
+	err     error
+}
+
+// Report queues d to be sent in a subsequent batch.
+// Any error is retained and reported by [WebhookSink.Err] and [WebhookSink.Close].
+func (s *WebhookSink) Report(d Difference) {
+	b, err := d.MarshalJSONLossless()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil:
+		s.err = err
+		return
+	case s.closed:
+		s.dropped++
+		return
+	case s.queue == nil:
+		s.queue = make(chan jsontext.Value, orDefault(s.QueueSize, 1000))
+		s.done = make(chan struct{})
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		go s.run()
+	}
+	select {
+	case s.queue <- b:
+	default:
+		s.dropped++
+	}
+}
+
+// NumDropped reports the number of differences dropped
+// because the queue was full or the sink was closed.
+func (s *WebhookSink) NumDropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Err reports the most recent error encountered while sending differences.
+func (s *WebhookSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close is equivalent to [WebhookSink.Shutdown] with a context
+// that expires after [WebhookSink.CloseTimeout].
+func (s *WebhookSink) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), orDefault(s.CloseTimeout, 30*time.Second))
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+// Shutdown sends all queued differences (retrying as necessary),
+// stops the background goroutine, and reports
+// the most recent error encountered while sending differences.
+// If ctx is done first, any request or retry backoff in progress is aborted,
+// the remaining differences are dropped, and it reports the context error.
+// Subsequent reports are dropped.
+func (s *WebhookSink) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		defer s.mu.Unlock()
+		return s.err
+	}
+	s.closed = true
+	queue, done, cancel := s.queue, s.done, s.cancel
+	s.mu.Unlock()
+
+	if queue == nil {
+		return s.Err()
+	}
+	close(queue)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		cancel()
+		<-done
+		s.mu.Lock()
+		s.err = ctx.Err()
+		s.mu.Unlock()
+	}
+	cancel()
+	return s.Err()
+}
+
+// run batches queued differences until the queue is closed.
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	maxBatch := orDefault(s.MaxBatchSize, 100)
+	ticker := time.NewTicker(orDefault(s.FlushInterval, 5*time.Second))
+	defer ticker.Stop()
+	var batch []jsontext.Value
+	flush := func() {
+		if len(batch) > 0 {
+			if s.ctx.Err() != nil {
+				s.mu.Lock()
+				s.dropped += len(batch) // aborted by Shutdown
+				s.mu.Unlock()
+			} else if err := s.send(batch); err != nil {
+				s.mu.Lock()
+				s.err = err
+				s.mu.Unlock()
+			}
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case b, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			if batch = append(batch, b); len(batch) >= maxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send posts a batch of encoded differences, retrying as necessary.
+func (s *WebhookSink) send(batch []jsontext.Value) error {
+	body := []byte{'['}
+	for i, b := range batch {
+		if i > 0 {
+			body = append(body, ',')
+		}
+		body = append(body, b...)
+	}
+	body = append(body, ']')
+
+	maxRetries := s.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3
+	}
+	backoff := orDefault(s.RetryBackoff, time.Second)
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil || !retry || attempt >= maxRetries {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff = min(2*backoff, time.Minute)
+	}
+}
+
+// post sends a single request and reports whether a failure may be retried.
+func (s *WebhookSink) post(body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, orDefault(s.Timeout, 10*time.Second))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, vs := range s.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5
+		return retry, fmt.Errorf("jsonsplit: webhook POST %s: %s", s.URL, resp.Status)
+	}
+	return false, nil
+}
+
+// orDefault returns v if positive, otherwise def.
+func orDefault[T int | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
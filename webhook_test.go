@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	jsonv2 "github.com/go-json-experiment/json"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var batchSizes []int
+	var failures int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer token")
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type header = %q, want %q", got, "application/json")
+		}
+		if failures < 2 {
+			failures++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		var batch []corpusEntry
+		if err := jsonv2.Unmarshal(b, &batch, corpusOptions()); err != nil {
+			t.Errorf("Unmarshal error: %v", err)
+		}
+		for _, e := range batch {
+			if e.Func != "Marshal" || string(e.JSONValueV1) != "null" {
+				t.Errorf("recorded difference = %+v, want a Marshal difference", e)
+			}
+		}
+		batchSizes = append(batchSizes, len(batch))
+	}))
+	defer srv.Close()
+
+	sink := &WebhookSink{
+		URL:           srv.URL,
+		Header:        http.Header{"Authorization": {"Bearer token"}},
+		MaxBatchSize:  2,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	}
+	codec := &Codec{ReportDifference: sink.Report}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	for range 5 {
+		codec.Marshal([]int(nil))
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if want := []int{2, 2, 1}; !reflect.DeepEqual(batchSizes, want) {
+		t.Errorf("batch sizes = %v, want %v", batchSizes, want)
+	}
+	sink.Report(Difference{Func: "Marshal"})
+	if got := sink.NumDropped(); got != 1 {
+		t.Errorf("NumDropped = %d, want 1", got)
+	}
+
+	// Client errors are not retried and are retained.
+	var requests int
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer bad.Close()
+	badSink := &WebhookSink{URL: bad.URL, RetryBackoff: time.Millisecond}
+	badSink.Report(Difference{Func: "Marshal"})
+	if badSink.Close() == nil || badSink.Err() == nil {
+		t.Errorf("Close and Err unexpectedly succeeded")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestWebhookSinkShutdown(t *testing.T) {
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hung.Close()
+	defer close(release)
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	// Requests to an unresponsive collector time out.
+	sink := &WebhookSink{URL: hung.URL, Timeout: time.Millisecond, MaxRetries: -1}
+	sink.Report(Difference{Func: "Marshal"})
+	if err := sink.Close(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Shutdown aborts retry backoffs and drops the remaining differences.
+	sink = &WebhookSink{URL: unavailable.URL, MaxBatchSize: 1, RetryBackoff: time.Hour}
+	for range 3 {
+		sink.Report(Difference{Func: "Marshal"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sink.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := sink.NumDropped(); got != 2 {
+		t.Errorf("NumDropped = %d, want 2", got)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"slices"
+
+	jsonv1std "encoding/json"
+
+	jsonv2 "github.com/go-json-experiment/json" // TODO: Use "encoding/json/v2"
+)
+
+var (
+	_ jsonv1std.Marshaler   = (*Wrapped)(nil)
+	_ jsonv1std.Unmarshaler = (*Wrapped)(nil)
+	_ jsonv1std.Marshaler   = Value[any]{}
+	_ jsonv1std.Unmarshaler = (*Value[any])(nil)
+)
+
+// Wrapped is a proxy for a Go value whose MarshalJSON and UnmarshalJSON
+// methods route through a [Codec]. See [Codec.Wrap].
+type Wrapped struct {
+	codec *Codec
+	pcs   []uintptr // stack of the caller of Wrap
+	v     any
+}
+
+// Wrap returns a proxy for v using the [GlobalCodec] (see [Codec.Wrap]).
+func Wrap(v any) *Wrapped {
+	return GlobalCodec.Wrap(v)
+}
+
+// Wrap returns a proxy for v that implements
+// [jsonv1std.Marshaler] and [jsonv1std.Unmarshaler]
+// by calling [Codec.Marshal] and [Codec.Unmarshal] on v.
+// This allows values that are serialized by code outside of one's control
+// (e.g., a third-party framework that calls [jsonv1std.Marshal] internally)
+// to still be compared between v1 and v2.
+//
+// Since the stack of the eventual marshal or unmarshal call only reveals
+// the internals of the serializing code, any differences are attributed to
+// the caller of Wrap instead (see [Difference.Caller]).
+// To unmarshal, v must be a non-nil pointer.
+func (c *Codec) Wrap(v any) *Wrapped {
+	var pcs [maxStackLen]uintptr
+	n := runtime.Callers(2, pcs[:]) // skip [runtime.Callers] + [Codec.Wrap]
+	return &Wrapped{c, slices.Clone(pcs[:n]), v}
+}
+
+// Unwrap returns the wrapped value.
+func (w *Wrapped) Unwrap() any {
+	return w.v
+}
+
+// MarshalJSON marshals the wrapped value with [Codec.Marshal].
+func (w *Wrapped) MarshalJSON() ([]byte, error) {
+	return w.codec.marshal(context.Background(), callSite{pcs: w.pcs}, nil, jsonv1Marshal, jsonv2.Marshal, w.v)
+}
+
+// UnmarshalJSON unmarshals into the wrapped value with [Codec.Unmarshal].
+func (w *Wrapped) UnmarshalJSON(b []byte) error {
+	return w.codec.unmarshal(context.Background(), callSite{pcs: w.pcs}, nil, b, w.v)
+}
+
+// Value is a Go value of type T whose MarshalJSON and UnmarshalJSON methods
+// route through the [GlobalCodec] with [MarshalFor] and [UnmarshalFor].
+// It is intended for individual struct fields such that they can be
+// migrated and monitored independently of the documents that contain them,
+// which may be serialized by code outside of one's control.
+// For example:
+//
+//	type Document struct {
+//		Name     string
+//		Settings jsonsplit.Value[Settings] // compared between v1 and v2
+//	}
+//
+// Since the stack of the eventual marshal or unmarshal call only reveals
+// the internals of the serializing code, any differences are attributed to
+// a caller label naming the Value type (e.g., "jsonsplit.Value[example.com/app.Settings]").
+type Value[T any] struct {
+	V T
+}
+
+// MarshalJSON marshals the value with [MarshalFor] on the [GlobalCodec].
+func (v Value[T]) MarshalJSON() ([]byte, error) {
+	return GlobalCodec.marshal(context.Background(), callSite{label: valueLabel[T]()}, loadTypeState[T](&GlobalCodec), jsonv1Marshal, jsonv2.Marshal, v.V)
+}
+
+// UnmarshalJSON unmarshals the value with [UnmarshalFor] on the [GlobalCodec].
+func (v *Value[T]) UnmarshalJSON(b []byte) error {
+	return GlobalCodec.unmarshal(context.Background(), callSite{label: valueLabel[T]()}, loadTypeState[T](&GlobalCodec), b, &v.V)
+}
+
+// valueLabel returns the caller label for differences from a [Value] of T.
+func valueLabel[T any]() string {
+	return "jsonsplit.Value[" + typeString(reflect.TypeFor[T]()) + "]"
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonsplit
+
+import (
+	"testing"
+
+	jsonv1std "encoding/json"
+)
+
+func TestWrap(t *testing.T) {
+	var got []Difference
+	codec := Codec{ReportDifference: func(d Difference) { got = append(got, d) }}
+	codec.SetMarshalCallMode(CallBothButReturnV1)
+	codec.SetUnmarshalCallMode(CallBothButReturnV1)
+
+	// Marshal through a framework that only knows about encoding/json.
+	wantCaller1 := callerPlus(codec.caller(), 1)
+	w := codec.Wrap(map[string][]int{"k": nil})
+	b, err := jsonv1std.Marshal(struct{ Data any }{w})
+	if err != nil || string(b) != `{"Data":{"k":null}}` {
+		t.Errorf("Marshal = (%s, %v), want (%s, nil)", b, err, `{"Data":{"k":null}}`)
+	}
+
+	// Unmarshal through a framework that only knows about encoding/json.
+	var v struct{ Name string }
+	wantCaller2 := callerPlus(codec.caller(), 1)
+	w = codec.Wrap(&v)
+	if err := jsonv1std.Unmarshal([]byte(`{"NAME":"x"}`), w); err != nil || v.Name != "x" {
+		t.Errorf("Unmarshal = (%+v, %v), want ({Name:x}, nil)", v, err)
+	}
+	if w.Unwrap() != &v {
+		t.Errorf("Unwrap = %v, want %v", w.Unwrap(), &v)
+	}
+
+	if len(got) != 2 || got[0].Caller != wantCaller1 || got[1].Caller != wantCaller2 {
+		t.Errorf("got differences %v, want differences from %s and %s", got, wantCaller1, wantCaller2)
+	}
+}
+
+func TestValue(t *testing.T) {
+	var got []Difference
+	GlobalCodec.ReportDifference = func(d Difference) { got = append(got, d) }
+	GlobalCodec.SetMarshalCallMode(CallBothButReturnV1)
+	GlobalCodec.SetUnmarshalCallMode(CallBothButReturnV1)
+	t.Cleanup(func() {
+		GlobalCodec.ReportDifference = nil
+		GlobalCodec.SetMarshalCallMode(OnlyCallV1)
+		GlobalCodec.SetUnmarshalCallMode(OnlyCallV1)
+	})
+
+	type document struct {
+		Name string
+		User Value[reverifyUser]
+	}
+	b, err := jsonv1std.Marshal(document{Name: "doc"})
+	if err != nil || string(b) != `{"Name":"doc","User":{"Name":"","Aliases":null}}` {
+		t.Errorf("Marshal = (%s, %v), want (%s, nil)", b, err, `{"Name":"doc","User":{"Name":"","Aliases":null}}`)
+	}
+	var v document
+	if err := jsonv1std.Unmarshal([]byte(`{"Name":"doc","User":{"NAME":"x"}}`), &v); err != nil || v.User.V.Name != "x" {
+		t.Errorf("Unmarshal = (%+v, %v), want User.V.Name of x", v, err)
+	}
+
+	const wantCaller = "jsonsplit.Value[github.com/go-json-experiment/jsonsplit.reverifyUser]"
+	if len(got) != 2 || got[0].Caller != wantCaller || got[1].Caller != wantCaller {
+		t.Errorf("got differences %v, want 2 differences from %s", got, wantCaller)
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	var c Codec
+	in := map[string]int{"k": 1}
+	b.ReportAllocs()
+	for b.Loop() {
+		jsonv1std.Marshal(c.Wrap(in))
+	}
+}